@@ -18,6 +18,9 @@ import (
 	"github.com/the-web3/eth-wallet/api/service"
 	"github.com/the-web3/eth-wallet/config"
 	"github.com/the-web3/eth-wallet/database"
+	"github.com/the-web3/eth-wallet/risk"
+	"github.com/the-web3/eth-wallet/wallet"
+	"github.com/the-web3/eth-wallet/wallet/node"
 )
 
 const ethereumAddressRegex = `^0x[a-fA-F0-9]{40}$`
@@ -27,6 +30,20 @@ const (
 	DepositsV1Path          = "/api/v1/deposits"
 	WithdrawalsV1Path       = "/api/v1/withdrawals"
 	SubmitWithdrawalsV1Path = "/api/v1/submit/withdrawals"
+	WithdrawLimitsV1Path    = "/api/v1/withdraw/limits"
+	AddressBookV1Path       = "/api/v1/address-book"
+	CancelWithdrawV1Path    = "/api/v1/withdraw/cancel"
+	AmendWithdrawV1Path     = "/api/v1/withdraw/amend"
+	WithdrawDetailV1Path    = "/api/v1/withdraw/detail"
+	ApproveWithdrawV1Path   = "/api/v1/withdraw/approve"
+	RejectWithdrawV1Path    = "/api/v1/withdraw/reject"
+	RebalancesV1Path        = "/api/v1/rebalances"
+	ApproveRebalanceV1Path  = "/api/v1/rebalance/approve"
+	RejectRebalanceV1Path   = "/api/v1/rebalance/reject"
+	ColdImportV1Path        = "/api/v1/cold/import"
+	BalanceHistoryV1Path    = "/api/v1/balances/history"
+	ReservesV1Path          = "/api/v1/reserves"
+	ReserveProofV1Path      = "/api/v1/reserves/proof"
 )
 
 type APIConfig struct {
@@ -38,6 +55,8 @@ type API struct {
 	router    *chi.Mux
 	apiServer *httputil.HTTPServer
 	db        *database.DB
+	readDB    *database.DB
+	ethClient node.EthClient
 	stopped   atomic.Bool
 }
 
@@ -53,6 +72,11 @@ func (a *API) initFromConfig(ctx context.Context, cfg *config.Config) error {
 	if err := a.initDB(ctx, cfg); err != nil {
 		return fmt.Errorf("failed to init DB: %w", err)
 	}
+	ethClient, err := node.DialEthClient(ctx, cfg.Chain.RpcUrl)
+	if err != nil {
+		return fmt.Errorf("failed to dial eth client: %w", err)
+	}
+	a.ethClient = ethClient
 	a.initRouter(cfg.HTTPServer, cfg)
 	if err := a.startServer(cfg.HTTPServer); err != nil {
 		return fmt.Errorf("failed to start API server: %w", err)
@@ -63,7 +87,8 @@ func (a *API) initFromConfig(ctx context.Context, cfg *config.Config) error {
 func (a *API) initRouter(conf config.ServerConfig, cfg *config.Config) {
 	v := new(service.Validator)
 
-	svc := service.New(v, a.db.Deposits, a.db.Withdraws)
+	// 只读查询走读库，写入和读后写走主库
+	svc := service.New(v, a.readDB.Deposits, a.db.Withdraws, a.readDB.Withdraws, a.readDB.WithdrawEvents, a.db.Rebalances, a.readDB.Rebalances, a.readDB.Addresses, a.readDB.AddressBook, a.readDB.BalanceSnapshots, a.readDB.Reserves, wallet.NewColdOffline(a.db, a.ethClient, cfg.Chain.ChainID), risk.NewEngine(a.db), cfg)
 	apiRouter := chi.NewRouter()
 	h := routes.NewRoutes(apiRouter, svc)
 
@@ -75,27 +100,42 @@ func (a *API) initRouter(conf config.ServerConfig, cfg *config.Config) {
 	apiRouter.Get(fmt.Sprintf(DepositsV1Path), h.DepositListHandler)
 	apiRouter.Get(fmt.Sprintf(WithdrawalsV1Path), h.WithdrawListHandler)
 	apiRouter.Post(fmt.Sprintf(SubmitWithdrawalsV1Path), h.SubmitWithdrawHandler)
+	apiRouter.Get(fmt.Sprintf(WithdrawLimitsV1Path), h.WithdrawLimitsHandler)
+	apiRouter.Get(fmt.Sprintf(AddressBookV1Path), h.AddressBookListHandler)
+	apiRouter.Post(fmt.Sprintf(AddressBookV1Path), h.AddAddressBookHandler)
+	apiRouter.Delete(fmt.Sprintf(AddressBookV1Path), h.RemoveAddressBookHandler)
+	apiRouter.Post(fmt.Sprintf(CancelWithdrawV1Path), h.CancelWithdrawHandler)
+	apiRouter.Post(fmt.Sprintf(AmendWithdrawV1Path), h.AmendWithdrawHandler)
+	apiRouter.Get(fmt.Sprintf(WithdrawDetailV1Path), h.WithdrawDetailHandler)
+	apiRouter.Post(fmt.Sprintf(ApproveWithdrawV1Path), h.ApproveWithdrawHandler)
+	apiRouter.Post(fmt.Sprintf(RejectWithdrawV1Path), h.RejectWithdrawHandler)
+	apiRouter.Get(fmt.Sprintf(RebalancesV1Path), h.RebalanceListHandler)
+	apiRouter.Post(fmt.Sprintf(ApproveRebalanceV1Path), h.ApproveRebalanceHandler)
+	apiRouter.Post(fmt.Sprintf(RejectRebalanceV1Path), h.RejectRebalanceHandler)
+	apiRouter.Post(fmt.Sprintf(ColdImportV1Path), h.ColdImportHandler)
+	apiRouter.Get(fmt.Sprintf(BalanceHistoryV1Path), h.BalanceHistoryHandler)
+	apiRouter.Get(fmt.Sprintf(ReservesV1Path), h.ReservesHandler)
+	apiRouter.Get(fmt.Sprintf(ReserveProofV1Path), h.ReserveProofHandler)
 
 	a.router = apiRouter
 }
 
 func (a *API) initDB(ctx context.Context, cfg *config.Config) error {
-	var initDb *database.DB
-	var err error
-	if !cfg.SlaveDbEnable {
-		initDb, err = database.NewDB(ctx, cfg.MasterDB)
-		if err != nil {
-			log.Error("failed to connect to master database", "err", err)
-			return err
-		}
-	} else {
-		initDb, err = database.NewDB(ctx, cfg.SlaveDB)
+	initDb, err := database.NewDB(ctx, cfg.MasterDB)
+	if err != nil {
+		log.Error("failed to connect to master database", "err", err)
+		return err
+	}
+	a.db = initDb
+	a.readDB = initDb
+	if cfg.SlaveDbEnable {
+		readDb, err := database.NewReplicaDB(ctx, initDb, cfg.SlaveDB, cfg.SlaveDbLag)
 		if err != nil {
 			log.Error("failed to connect to slave database", "err", err)
 			return err
 		}
+		a.readDB = readDb
 	}
-	a.db = initDb
 	return nil
 }
 
@@ -110,6 +150,14 @@ func (a *API) Stop(ctx context.Context) error {
 			result = errors.Join(result, fmt.Errorf("failed to stop API server: %w", err))
 		}
 	}
+	if a.ethClient != nil {
+		a.ethClient.Close()
+	}
+	if a.readDB != nil && a.readDB != a.db {
+		if err := a.readDB.Close(); err != nil {
+			result = errors.Join(result, fmt.Errorf("failed to close slave DB: %w", err))
+		}
+	}
 	if a.db != nil {
 		if err := a.db.Close(); err != nil {
 			result = errors.Join(result, fmt.Errorf("failed to close DB: %w", err))
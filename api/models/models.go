@@ -2,15 +2,24 @@ package models
 
 import (
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/google/uuid"
 	"github.com/the-web3/eth-wallet/database"
+	"github.com/the-web3/eth-wallet/risk"
+	"github.com/the-web3/eth-wallet/wallet"
+	"github.com/the-web3/eth-wallet/wallet/merklesum"
 	"math/big"
 )
 
 type SubmitDWParams struct {
-	FromAddress  common.Address
-	ToAddress    common.Address
-	TokenAddress common.Address
-	Amount       *big.Int
+	ConsumerToken string
+	RequestId     string
+	UserUid       string
+	FromAddress   common.Address
+	ToAddress     common.Address
+	TokenAddress  common.Address
+	Amount        *big.Int
+	Deadline      uint64
+	Signature     []byte
 }
 
 type QueryDWParams struct {
@@ -20,6 +29,59 @@ type QueryDWParams struct {
 	Order    string
 }
 
+type QueryLimitParams struct {
+	UserUid      string
+	TokenAddress common.Address
+}
+
+// BalanceHistoryParams Address 与 UserUid 二选一，BlockNumber 为空时按 Timestamp 查询
+type BalanceHistoryParams struct {
+	Address     common.Address
+	UserUid     string
+	BlockNumber *big.Int
+	Timestamp   uint64
+}
+
+type ReserveProofParams struct {
+	UserUid      string
+	TokenAddress common.Address
+}
+
+type AddressBookParams struct {
+	UserUid string
+	Address common.Address
+	Label   string
+}
+
+type WithdrawLocatorParams struct {
+	Guid          uuid.UUID
+	ConsumerToken string
+	RequestId     string
+	Reason        string
+}
+
+type AmendWithdrawParams struct {
+	*WithdrawLocatorParams
+	ToAddress common.Address
+	Amount    *big.Int
+	Deadline  uint64
+	Signature []byte
+}
+
+type RebalanceReviewParams struct {
+	Guid     uuid.UUID
+	Approve  bool
+	Operator string
+	Reason   string
+}
+
+type WithdrawReviewParams struct {
+	Guid     uuid.UUID
+	Approve  bool
+	Operator string
+	Reason   string
+}
+
 type QueryPageParams struct {
 	Page     int
 	PageSize int
@@ -51,4 +113,84 @@ type WithdrawsResponse struct {
 type SubmitWithdrawsResponse struct {
 	Code int    `json:"code"`
 	Msg  string `json:"msg"`
+	Hash string `json:"hash"`
+}
+
+type WithdrawLimitsResponse struct {
+	UserUid      string            `json:"userUid"`
+	TokenAddress string            `json:"tokenAddress"`
+	Records      []risk.LimitUsage `json:"Records"`
+}
+
+type AddressBookResponse struct {
+	Code  int                   `json:"code"`
+	Msg   string                `json:"msg"`
+	Entry *database.AddressBook `json:"entry"`
+}
+
+type AddressBookListResponse struct {
+	UserUid string                 `json:"userUid"`
+	Records []database.AddressBook `json:"Records"`
+}
+
+type CancelWithdrawResponse struct {
+	Code   int    `json:"code"`
+	Msg    string `json:"msg"`
+	Status uint8  `json:"status"`
+}
+
+type AmendWithdrawResponse struct {
+	Code int    `json:"code"`
+	Msg  string `json:"msg"`
+}
+
+type WithdrawDetailResponse struct {
+	Withdraw *database.Withdraws       `json:"withdraw"`
+	Events   []database.WithdrawEvents `json:"events"`
+}
+
+type RebalancesResponse struct {
+	Current int                   `json:"Current"`
+	Size    int                   `json:"Size"`
+	Total   int64                 `json:"Total"`
+	Records []database.Rebalances `json:"Records"`
+}
+
+type RebalanceReviewResponse struct {
+	Code   int    `json:"code"`
+	Msg    string `json:"msg"`
+	Status uint8  `json:"status"`
+}
+
+type WithdrawReviewResponse struct {
+	Code   int    `json:"code"`
+	Msg    string `json:"msg"`
+	Status uint8  `json:"status"`
+}
+
+type ColdImportResponse struct {
+	Code    int                       `json:"code"`
+	Msg     string                    `json:"msg"`
+	Results []wallet.ColdImportResult `json:"results"`
+}
+
+type BalanceHistoryResponse struct {
+	Address     string                      `json:"address,omitempty"`
+	UserUid     string                      `json:"userUid,omitempty"`
+	BlockNumber string                      `json:"blockNumber,omitempty"`
+	Timestamp   uint64                      `json:"timestamp,omitempty"`
+	Balances    []database.BalanceSnapshots `json:"balances"`
+}
+
+type ReservesResponse struct {
+	Reports []database.ReserveReports `json:"reports"`
+}
+
+// ReserveProofResponse 用户用 salt、userUid 和 balance 计算叶子哈希，沿 path 计算出的根和总额应与 report 一致
+type ReserveProofResponse struct {
+	Code   int                      `json:"code"`
+	Msg    string                   `json:"msg"`
+	Report *database.ReserveReports `json:"report,omitempty"`
+	Proof  *database.ReserveProofs  `json:"proof,omitempty"`
+	Path   []merklesum.ProofNode    `json:"path,omitempty"`
 }
@@ -0,0 +1,30 @@
+package routes
+
+import (
+	"net/http"
+
+	"github.com/ethereum/go-ethereum/log"
+)
+
+func (h Routes) BalanceHistoryHandler(w http.ResponseWriter, r *http.Request) {
+	address := r.URL.Query().Get("address")
+	userUid := r.URL.Query().Get("userUid")
+	blockNumber := r.URL.Query().Get("blockNumber")
+	timestamp := r.URL.Query().Get("timestamp")
+	params, err := h.svc.BalanceHistoryParams(address, userUid, blockNumber, timestamp)
+	if err != nil {
+		http.Error(w, "invalid query params", http.StatusBadRequest)
+		log.Error("error reading request params", "err", err.Error())
+		return
+	}
+	historyRet, err := h.svc.GetBalanceHistory(params)
+	if err != nil {
+		http.Error(w, "Internal server error reading balance history", http.StatusInternalServerError)
+		log.Error("Unable to read balance history from DB", "err", err.Error())
+		return
+	}
+	err = jsonResponse(w, historyRet, http.StatusOK)
+	if err != nil {
+		log.Error("Error writing response", "err", err.Error())
+	}
+}
@@ -0,0 +1,79 @@
+package routes
+
+import (
+	"io"
+	"net/http"
+
+	"github.com/ethereum/go-ethereum/log"
+)
+
+func (h Routes) RebalanceListHandler(w http.ResponseWriter, r *http.Request) {
+	pageQuery := r.URL.Query().Get("page")
+	pageSizeQuery := r.URL.Query().Get("pageSize")
+	order := r.URL.Query().Get("order")
+	params, err := h.svc.QueryPageListParams(pageQuery, pageSizeQuery, order)
+	if err != nil {
+		http.Error(w, "invalid query params", http.StatusBadRequest)
+		log.Error("error reading request params", "err", err.Error())
+		return
+	}
+	rebalancePage, err := h.svc.GetRebalanceList(params)
+	if err != nil {
+		http.Error(w, "Internal server error reading rebalance list", http.StatusInternalServerError)
+		log.Error("Unable to read rebalance list from DB", "err", err.Error())
+		return
+	}
+	err = jsonResponse(w, rebalancePage, http.StatusOK)
+	if err != nil {
+		log.Error("Error writing response", "err", err.Error())
+	}
+}
+
+func (h Routes) ApproveRebalanceHandler(w http.ResponseWriter, r *http.Request) {
+	h.reviewRebalance(w, r, true)
+}
+
+func (h Routes) RejectRebalanceHandler(w http.ResponseWriter, r *http.Request) {
+	h.reviewRebalance(w, r, false)
+}
+
+func (h Routes) reviewRebalance(w http.ResponseWriter, r *http.Request, approve bool) {
+	guid := r.URL.Query().Get("guid")
+	operator := r.URL.Query().Get("operator")
+	reason := r.URL.Query().Get("reason")
+	params, err := h.svc.RebalanceReviewParams(guid, approve, operator, reason)
+	if err != nil {
+		http.Error(w, "invalid query params", http.StatusBadRequest)
+		log.Error("error reading request params", "err", err.Error())
+		return
+	}
+	reviewRet, err := h.svc.ReviewRebalance(params)
+	if err != nil {
+		http.Error(w, "Internal server error reviewing rebalance", http.StatusInternalServerError)
+		log.Error("Unable to review rebalance", "err", err.Error())
+		return
+	}
+	err = jsonResponse(w, reviewRet, http.StatusOK)
+	if err != nil {
+		log.Error("Error writing response", "err", err.Error())
+	}
+}
+
+func (h Routes) ColdImportHandler(w http.ResponseWriter, r *http.Request) {
+	content, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		log.Error("error reading request body", "err", err.Error())
+		return
+	}
+	importRet, err := h.svc.ImportColdTransactions(r.Context(), content)
+	if err != nil {
+		http.Error(w, "Internal server error importing signed txs", http.StatusInternalServerError)
+		log.Error("Unable to import signed txs", "err", err.Error())
+		return
+	}
+	err = jsonResponse(w, importRet, http.StatusOK)
+	if err != nil {
+		log.Error("Error writing response", "err", err.Error())
+	}
+}
@@ -0,0 +1,41 @@
+package routes
+
+import (
+	"net/http"
+
+	"github.com/ethereum/go-ethereum/log"
+)
+
+func (h Routes) ReservesHandler(w http.ResponseWriter, r *http.Request) {
+	reservesRet, err := h.svc.GetReserves()
+	if err != nil {
+		http.Error(w, "Internal server error reading reserves", http.StatusInternalServerError)
+		log.Error("Unable to read reserves from DB", "err", err.Error())
+		return
+	}
+	err = jsonResponse(w, reservesRet, http.StatusOK)
+	if err != nil {
+		log.Error("Error writing response", "err", err.Error())
+	}
+}
+
+func (h Routes) ReserveProofHandler(w http.ResponseWriter, r *http.Request) {
+	userUid := r.URL.Query().Get("userUid")
+	tokenAddress := r.URL.Query().Get("tokenAddress")
+	params, err := h.svc.ReserveProofParams(userUid, tokenAddress)
+	if err != nil {
+		http.Error(w, "invalid query params", http.StatusBadRequest)
+		log.Error("error reading request params", "err", err.Error())
+		return
+	}
+	proofRet, err := h.svc.GetReserveProof(params)
+	if err != nil {
+		http.Error(w, "Internal server error reading reserve proof", http.StatusInternalServerError)
+		log.Error("Unable to read reserve proof from DB", "err", err.Error())
+		return
+	}
+	err = jsonResponse(w, proofRet, http.StatusOK)
+	if err != nil {
+		log.Error("Error writing response", "err", err.Error())
+	}
+}
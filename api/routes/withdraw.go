@@ -32,12 +32,17 @@ func (h Routes) WithdrawListHandler(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h Routes) SubmitWithdrawHandler(w http.ResponseWriter, r *http.Request) {
+	consumerToken := r.URL.Query().Get("consumerToken")
+	requestId := r.URL.Query().Get("requestId")
+	userUid := r.URL.Query().Get("userUid")
 	fromAddress := r.URL.Query().Get("fromAddress")
 	toaAdress := r.URL.Query().Get("toAddress")
 	tokenAddress := r.URL.Query().Get("tokenAddress")
 	amount := r.URL.Query().Get("amount")
+	deadline := r.URL.Query().Get("deadline")
+	signature := r.URL.Query().Get("signature")
 
-	params, err := h.svc.SubmitDWParams(fromAddress, toaAdress, tokenAddress, amount)
+	params, err := h.svc.SubmitDWParams(consumerToken, requestId, userUid, fromAddress, toaAdress, tokenAddress, amount, deadline, signature)
 	if err != nil {
 		http.Error(w, "invalid query params", http.StatusBadRequest)
 		log.Error("error reading request params", "err", err.Error())
@@ -54,3 +59,192 @@ func (h Routes) SubmitWithdrawHandler(w http.ResponseWriter, r *http.Request) {
 		log.Error("Error writing response", "err", err.Error())
 	}
 }
+
+func (h Routes) WithdrawLimitsHandler(w http.ResponseWriter, r *http.Request) {
+	userUid := r.URL.Query().Get("userUid")
+	tokenAddress := r.URL.Query().Get("tokenAddress")
+	params, err := h.svc.QueryLimitParams(userUid, tokenAddress)
+	if err != nil {
+		http.Error(w, "invalid query params", http.StatusBadRequest)
+		log.Error("error reading request params", "err", err.Error())
+		return
+	}
+	limitsRet, err := h.svc.GetWithdrawLimitUsage(params)
+	if err != nil {
+		http.Error(w, "Internal server error reading withdraw limits", http.StatusInternalServerError)
+		log.Error("Unable to read withdraw limits from DB", "err", err.Error())
+		return
+	}
+	err = jsonResponse(w, limitsRet, http.StatusOK)
+	if err != nil {
+		log.Error("Error writing response", "err", err.Error())
+	}
+}
+
+func (h Routes) AddressBookListHandler(w http.ResponseWriter, r *http.Request) {
+	userUid := r.URL.Query().Get("userUid")
+	addressBookRet, err := h.svc.GetAddressBook(userUid)
+	if err != nil {
+		http.Error(w, "Internal server error reading address book", http.StatusInternalServerError)
+		log.Error("Unable to read address book from DB", "err", err.Error())
+		return
+	}
+	err = jsonResponse(w, addressBookRet, http.StatusOK)
+	if err != nil {
+		log.Error("Error writing response", "err", err.Error())
+	}
+}
+
+func (h Routes) AddAddressBookHandler(w http.ResponseWriter, r *http.Request) {
+	userUid := r.URL.Query().Get("userUid")
+	address := r.URL.Query().Get("address")
+	label := r.URL.Query().Get("label")
+	params, err := h.svc.AddressBookParams(userUid, address, label)
+	if err != nil {
+		http.Error(w, "invalid query params", http.StatusBadRequest)
+		log.Error("error reading request params", "err", err.Error())
+		return
+	}
+	addressBookRet, err := h.svc.AddAddressBook(params)
+	if err != nil {
+		http.Error(w, "Internal server error writing address book", http.StatusInternalServerError)
+		log.Error("Unable to write address book to DB", "err", err.Error())
+		return
+	}
+	err = jsonResponse(w, addressBookRet, http.StatusOK)
+	if err != nil {
+		log.Error("Error writing response", "err", err.Error())
+	}
+}
+
+func (h Routes) RemoveAddressBookHandler(w http.ResponseWriter, r *http.Request) {
+	userUid := r.URL.Query().Get("userUid")
+	address := r.URL.Query().Get("address")
+	params, err := h.svc.AddressBookParams(userUid, address, "")
+	if err != nil {
+		http.Error(w, "invalid query params", http.StatusBadRequest)
+		log.Error("error reading request params", "err", err.Error())
+		return
+	}
+	addressBookRet, err := h.svc.RemoveAddressBook(params)
+	if err != nil {
+		http.Error(w, "Internal server error writing address book", http.StatusInternalServerError)
+		log.Error("Unable to write address book to DB", "err", err.Error())
+		return
+	}
+	err = jsonResponse(w, addressBookRet, http.StatusOK)
+	if err != nil {
+		log.Error("Error writing response", "err", err.Error())
+	}
+}
+
+func (h Routes) CancelWithdrawHandler(w http.ResponseWriter, r *http.Request) {
+	guid := r.URL.Query().Get("guid")
+	consumerToken := r.URL.Query().Get("consumerToken")
+	requestId := r.URL.Query().Get("requestId")
+	params, err := h.svc.WithdrawLocatorParams(guid, consumerToken, requestId)
+	if err != nil {
+		http.Error(w, "invalid query params", http.StatusBadRequest)
+		log.Error("error reading request params", "err", err.Error())
+		return
+	}
+	params.Reason = r.URL.Query().Get("reason")
+	cancelRet, err := h.svc.CancelWithdraw(params)
+	if err != nil {
+		http.Error(w, "Internal server error cancelling withdraw", http.StatusInternalServerError)
+		log.Error("Unable to cancel withdraw", "err", err.Error())
+		return
+	}
+	err = jsonResponse(w, cancelRet, http.StatusOK)
+	if err != nil {
+		log.Error("Error writing response", "err", err.Error())
+	}
+}
+
+func (h Routes) ApproveWithdrawHandler(w http.ResponseWriter, r *http.Request) {
+	h.reviewWithdraw(w, r, true)
+}
+
+func (h Routes) RejectWithdrawHandler(w http.ResponseWriter, r *http.Request) {
+	h.reviewWithdraw(w, r, false)
+}
+
+func (h Routes) reviewWithdraw(w http.ResponseWriter, r *http.Request, approve bool) {
+	guid := r.URL.Query().Get("guid")
+	operator := r.URL.Query().Get("operator")
+	reason := r.URL.Query().Get("reason")
+	params, err := h.svc.WithdrawReviewParams(guid, approve, operator, reason)
+	if err != nil {
+		http.Error(w, "invalid query params", http.StatusBadRequest)
+		log.Error("error reading request params", "err", err.Error())
+		return
+	}
+	reviewRet, err := h.svc.ReviewWithdraw(params)
+	if err != nil {
+		http.Error(w, "Internal server error reviewing withdraw", http.StatusInternalServerError)
+		log.Error("Unable to review withdraw", "err", err.Error())
+		return
+	}
+	err = jsonResponse(w, reviewRet, http.StatusOK)
+	if err != nil {
+		log.Error("Error writing response", "err", err.Error())
+	}
+}
+
+func (h Routes) AmendWithdrawHandler(w http.ResponseWriter, r *http.Request) {
+	guid := r.URL.Query().Get("guid")
+	consumerToken := r.URL.Query().Get("consumerToken")
+	requestId := r.URL.Query().Get("requestId")
+	locator, err := h.svc.WithdrawLocatorParams(guid, consumerToken, requestId)
+	if err != nil {
+		http.Error(w, "invalid query params", http.StatusBadRequest)
+		log.Error("error reading request params", "err", err.Error())
+		return
+	}
+	toAddress := r.URL.Query().Get("toAddress")
+	amount := r.URL.Query().Get("amount")
+	deadline := r.URL.Query().Get("deadline")
+	signature := r.URL.Query().Get("signature")
+	params, err := h.svc.AmendWithdrawParams(locator, toAddress, amount, deadline, signature)
+	if err != nil {
+		http.Error(w, "invalid query params", http.StatusBadRequest)
+		log.Error("error reading request params", "err", err.Error())
+		return
+	}
+	amendRet, err := h.svc.AmendWithdraw(params)
+	if err != nil {
+		http.Error(w, "Internal server error amending withdraw", http.StatusInternalServerError)
+		log.Error("Unable to amend withdraw", "err", err.Error())
+		return
+	}
+	err = jsonResponse(w, amendRet, http.StatusOK)
+	if err != nil {
+		log.Error("Error writing response", "err", err.Error())
+	}
+}
+
+func (h Routes) WithdrawDetailHandler(w http.ResponseWriter, r *http.Request) {
+	guid := r.URL.Query().Get("guid")
+	consumerToken := r.URL.Query().Get("consumerToken")
+	requestId := r.URL.Query().Get("requestId")
+	params, err := h.svc.WithdrawLocatorParams(guid, consumerToken, requestId)
+	if err != nil {
+		http.Error(w, "invalid query params", http.StatusBadRequest)
+		log.Error("error reading request params", "err", err.Error())
+		return
+	}
+	detailRet, err := h.svc.GetWithdrawDetail(params)
+	if err != nil {
+		http.Error(w, "Internal server error reading withdraw detail", http.StatusInternalServerError)
+		log.Error("Unable to read withdraw detail from DB", "err", err.Error())
+		return
+	}
+	if detailRet == nil {
+		http.Error(w, "withdraw not found", http.StatusNotFound)
+		return
+	}
+	err = jsonResponse(w, detailRet, http.StatusOK)
+	if err != nil {
+		log.Error("Error writing response", "err", err.Error())
+	}
+}
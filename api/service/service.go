@@ -1,37 +1,100 @@
 package service
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"math/big"
 	"strconv"
 	"strings"
 
+	"github.com/google/uuid"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/log"
 
 	"github.com/the-web3/eth-wallet/api/models"
+	"github.com/the-web3/eth-wallet/config"
 	"github.com/the-web3/eth-wallet/database"
+	"github.com/the-web3/eth-wallet/risk"
+	"github.com/the-web3/eth-wallet/wallet"
+	"github.com/the-web3/eth-wallet/wallet/coldsign"
+	"github.com/the-web3/eth-wallet/wallet/merklesum"
 )
 
 type Service interface {
 	GetDepositList(*models.QueryDWParams) (*models.DepositsResponse, error)
 	GetWithdrawalList(params *models.QueryDWParams) (*models.WithdrawsResponse, error)
 	SubmitWithdrawFromBusiness(params *models.SubmitDWParams) (*models.SubmitWithdrawsResponse, error)
+	GetWithdrawLimitUsage(params *models.QueryLimitParams) (*models.WithdrawLimitsResponse, error)
+	GetAddressBook(userUid string) (*models.AddressBookListResponse, error)
+	CancelWithdraw(params *models.WithdrawLocatorParams) (*models.CancelWithdrawResponse, error)
+	AmendWithdraw(params *models.AmendWithdrawParams) (*models.AmendWithdrawResponse, error)
+	GetWithdrawDetail(params *models.WithdrawLocatorParams) (*models.WithdrawDetailResponse, error)
+	AddAddressBook(params *models.AddressBookParams) (*models.AddressBookResponse, error)
+	RemoveAddressBook(params *models.AddressBookParams) (*models.AddressBookResponse, error)
+	GetRebalanceList(params *models.QueryPageParams) (*models.RebalancesResponse, error)
+	ReviewRebalance(params *models.RebalanceReviewParams) (*models.RebalanceReviewResponse, error)
+	ReviewWithdraw(params *models.WithdrawReviewParams) (*models.WithdrawReviewResponse, error)
+	ImportColdTransactions(ctx context.Context, content []byte) (*models.ColdImportResponse, error)
+	GetBalanceHistory(params *models.BalanceHistoryParams) (*models.BalanceHistoryResponse, error)
+	GetReserves() (*models.ReservesResponse, error)
+	GetReserveProof(params *models.ReserveProofParams) (*models.ReserveProofResponse, error)
 
-	SubmitDWParams(fromAddress string, toAddress string, tokenAddress string, amount string) (*models.SubmitDWParams, error)
+	SubmitDWParams(consumerToken string, requestId string, userUid string, fromAddress string, toAddress string, tokenAddress string, amount string, deadline string, signature string) (*models.SubmitDWParams, error)
 	QueryDWListParams(address string, page string, pageSize string, order string) (*models.QueryDWParams, error)
+	QueryLimitParams(userUid string, tokenAddress string) (*models.QueryLimitParams, error)
+	AddressBookParams(userUid string, address string, label string) (*models.AddressBookParams, error)
+	WithdrawLocatorParams(guid string, consumerToken string, requestId string) (*models.WithdrawLocatorParams, error)
+	AmendWithdrawParams(locator *models.WithdrawLocatorParams, toAddress string, amount string, deadline string, signature string) (*models.AmendWithdrawParams, error)
 	QueryPageListParams(page string, pageSize string, order string) (*models.QueryPageParams, error)
+	RebalanceReviewParams(guid string, approve bool, operator string, reason string) (*models.RebalanceReviewParams, error)
+	WithdrawReviewParams(guid string, approve bool, operator string, reason string) (*models.WithdrawReviewParams, error)
+	BalanceHistoryParams(address string, userUid string, blockNumber string, timestamp string) (*models.BalanceHistoryParams, error)
+	ReserveProofParams(userUid string, tokenAddress string) (*models.ReserveProofParams, error)
 }
 
 type HandlerSvc struct {
-	v             *Validator
-	depositsView  database.DepositsView
-	withdrawsView database.WithdrawsView
+	v               *Validator
+	depositsView    database.DepositsView
+	withdrawsDB     database.WithdrawsDB
+	withdrawsView   database.WithdrawsView
+	eventsView      database.WithdrawEventsView
+	rebalancesDB    database.RebalancesDB
+	rebalancesView  database.RebalancesView
+	addressView     database.AddressesView
+	addressBookView database.AddressBookView
+	snapshotView    database.BalanceSnapshotsView
+	reservesView    database.ReservesView
+	coldOffline     *wallet.ColdOffline
+	riskEngine      *risk.Engine
+	addressBook     config.AddressBookConfig
+
+	chainId              *big.Int
+	withdrawSignRequired bool
 }
 
-func New(v *Validator, dsv database.DepositsView, wdv database.WithdrawsView) Service {
+func New(v *Validator, dsv database.DepositsView, wdb database.WithdrawsDB, wsv database.WithdrawsView, wev database.WithdrawEventsView, rdb database.RebalancesDB, rbv database.RebalancesView, adv database.AddressesView, abv database.AddressBookView, bsv database.BalanceSnapshotsView, rsv database.ReservesView, coldOffline *wallet.ColdOffline, riskEngine *risk.Engine, cfg *config.Config) Service {
 	return &HandlerSvc{
-		v:             v,
-		depositsView:  dsv,
-		withdrawsView: wdv,
+		v:               v,
+		depositsView:    dsv,
+		withdrawsDB:     wdb,
+		withdrawsView:   wsv,
+		eventsView:      wev,
+		rebalancesDB:    rdb,
+		rebalancesView:  rbv,
+		addressView:     adv,
+		addressBookView: abv,
+		snapshotView:    bsv,
+		reservesView:    rsv,
+		coldOffline:     coldOffline,
+		riskEngine:      riskEngine,
+		addressBook:     cfg.AddressBook,
+
+		chainId:              new(big.Int).SetUint64(uint64(cfg.Chain.ChainID)),
+		withdrawSignRequired: cfg.WithdrawSignRequired,
 	}
 }
 
@@ -58,20 +121,464 @@ func (h HandlerSvc) GetWithdrawalList(params *models.QueryDWParams) (*models.Wit
 }
 
 func (h HandlerSvc) SubmitWithdrawFromBusiness(params *models.SubmitDWParams) (*models.SubmitWithdrawsResponse, error) {
-	err := h.withdrawsView.SubmitWithdrawFromBusiness(params.FromAddress, params.ToAddress, params.ToAddress, params.Amount)
+	signDigest, err := h.verifyWithdrawSignature(&risk.WithdrawAuthorization{
+		ConsumerToken: params.ConsumerToken,
+		RequestId:     params.RequestId,
+		FromAddress:   params.FromAddress,
+		ToAddress:     params.ToAddress,
+		TokenAddress:  params.TokenAddress,
+		Amount:        params.Amount,
+		Deadline:      params.Deadline,
+		Signature:     params.Signature,
+	})
+	if errors.Is(err, risk.ErrWithdrawUnauthorized) {
+		return &models.SubmitWithdrawsResponse{
+			Code: 4004,
+			Msg:  err.Error(),
+			Hash: common.Hash{}.String(),
+		}, nil
+	}
+	if err != nil {
+		return &models.SubmitWithdrawsResponse{
+			Code: 4000,
+			Msg:  "submit transaction fail",
+			Hash: common.Hash{}.String(),
+		}, nil
+	}
+	if h.addressBook.Enforce {
+		err := h.riskEngine.CheckWithdrawDestination(params.UserUid, params.ToAddress)
+		if errors.Is(err, risk.ErrDestinationNotAllowed) {
+			return &models.SubmitWithdrawsResponse{
+				Code: 4003,
+				Msg:  err.Error(),
+				Hash: common.Hash{}.String(),
+			}, nil
+		}
+		if err != nil {
+			return &models.SubmitWithdrawsResponse{
+				Code: 4000,
+				Msg:  "submit transaction fail",
+				Hash: common.Hash{}.String(),
+			}, nil
+		}
+	}
+	err = h.riskEngine.CheckSubmitLimits(params.ConsumerToken, params.RequestId, params.UserUid, params.TokenAddress, params.Amount)
+	if errors.Is(err, risk.ErrWithdrawLimitExceeded) {
+		return &models.SubmitWithdrawsResponse{
+			Code: 4002,
+			Msg:  err.Error(),
+			Hash: common.Hash{}.String(),
+		}, nil
+	}
+	if err != nil {
+		return &models.SubmitWithdrawsResponse{
+			Code: 4000,
+			Msg:  "submit transaction fail",
+			Hash: common.Hash{}.String(),
+		}, nil
+	}
+	withdraw, err := h.withdrawsDB.SubmitWithdrawFromBusiness(params.ConsumerToken, params.RequestId, params.UserUid, params.FromAddress, params.ToAddress, params.TokenAddress, params.Amount, signDigest)
+	if errors.Is(err, database.ErrWithdrawRequestConflict) {
+		return &models.SubmitWithdrawsResponse{
+			Code: 4001,
+			Msg:  "request id already submitted with different params",
+			Hash: common.Hash{}.String(),
+		}, nil
+	}
 	if err != nil {
 		return &models.SubmitWithdrawsResponse{
 			Code: 4000,
 			Msg:  "submit transaction fail",
+			Hash: common.Hash{}.String(),
 		}, nil
 	}
 	return &models.SubmitWithdrawsResponse{
 		Code: 2000,
 		Msg:  "submit transaction success",
+		Hash: withdraw.Hash.String(),
+	}, nil
+}
+
+func (h HandlerSvc) GetWithdrawLimitUsage(params *models.QueryLimitParams) (*models.WithdrawLimitsResponse, error) {
+	usages, err := h.riskEngine.WithdrawLimitUsage(params.UserUid, params.TokenAddress)
+	if err != nil {
+		return nil, err
+	}
+	return &models.WithdrawLimitsResponse{
+		UserUid:      params.UserUid,
+		TokenAddress: params.TokenAddress.String(),
+		Records:      usages,
+	}, nil
+}
+
+// verifyWithdrawSignature 开启签名校验或请求带签名时，校验业务方的 EIP-712 签名并返回签名摘要
+func (h HandlerSvc) verifyWithdrawSignature(auth *risk.WithdrawAuthorization) (string, error) {
+	if len(auth.Signature) == 0 && !h.withdrawSignRequired {
+		return "", nil
+	}
+	return h.riskEngine.VerifyWithdrawAuthorization(auth, h.chainId)
+}
+
+// findWithdraw 撤销和修改需要从主库读取，详情可以从读库读取
+func (h HandlerSvc) findWithdraw(view database.WithdrawsView, params *models.WithdrawLocatorParams) (*database.Withdraws, error) {
+	if params.Guid != uuid.Nil {
+		return view.QueryWithdrawsByGuid(params.Guid)
+	}
+	return view.QueryWithdrawsByRequestId(params.ConsumerToken, params.RequestId)
+}
+
+func (h HandlerSvc) CancelWithdraw(params *models.WithdrawLocatorParams) (*models.CancelWithdrawResponse, error) {
+	withdraw, err := h.findWithdraw(h.withdrawsDB, params)
+	if err != nil {
+		return nil, err
+	}
+	if withdraw == nil {
+		return &models.CancelWithdrawResponse{
+			Code: 4005,
+			Msg:  "withdraw not found",
+		}, nil
+	}
+	cancelled, err := h.withdrawsDB.CancelPendingWithdraw(withdraw.GUID, params.Reason)
+	if err != nil {
+		return nil, err
+	}
+	if cancelled {
+		return &models.CancelWithdrawResponse{
+			Code:   2000,
+			Msg:    "withdraw cancelled",
+			Status: 9,
+		}, nil
+	}
+	requested, err := h.withdrawsDB.RequestWithdrawCancel(withdraw.GUID, params.Reason)
+	if err != nil {
+		return nil, err
+	}
+	if !requested {
+		return &models.CancelWithdrawResponse{
+			Code:   4006,
+			Msg:    fmt.Sprintf("withdraw in status %d can not be cancelled", withdraw.Status),
+			Status: withdraw.Status,
+		}, nil
+	}
+	return &models.CancelWithdrawResponse{
+		Code:   2000,
+		Msg:    "withdraw cancel requested on chain",
+		Status: 10,
+	}, nil
+}
+
+func (h HandlerSvc) AmendWithdraw(params *models.AmendWithdrawParams) (*models.AmendWithdrawResponse, error) {
+	withdraw, err := h.findWithdraw(h.withdrawsDB, params.WithdrawLocatorParams)
+	if err != nil {
+		return nil, err
+	}
+	if withdraw == nil {
+		return &models.AmendWithdrawResponse{
+			Code: 4005,
+			Msg:  "withdraw not found",
+		}, nil
+	}
+	signDigest, err := h.verifyWithdrawSignature(&risk.WithdrawAuthorization{
+		ConsumerToken: withdraw.ConsumerToken,
+		RequestId:     withdraw.RequestId,
+		FromAddress:   withdraw.FromAddress,
+		ToAddress:     params.ToAddress,
+		TokenAddress:  withdraw.TokenAddress,
+		Amount:        params.Amount,
+		Deadline:      params.Deadline,
+		Signature:     params.Signature,
+	})
+	if errors.Is(err, risk.ErrWithdrawUnauthorized) {
+		return &models.AmendWithdrawResponse{
+			Code: 4004,
+			Msg:  err.Error(),
+		}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if h.addressBook.Enforce {
+		err = h.riskEngine.CheckWithdrawDestination(withdraw.UserUid, params.ToAddress)
+		if errors.Is(err, risk.ErrDestinationNotAllowed) {
+			return &models.AmendWithdrawResponse{
+				Code: 4003,
+				Msg:  err.Error(),
+			}, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	err = h.riskEngine.CheckAmendLimits(withdraw, params.Amount)
+	if errors.Is(err, risk.ErrWithdrawLimitExceeded) {
+		return &models.AmendWithdrawResponse{
+			Code: 4002,
+			Msg:  err.Error(),
+		}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	detail := fmt.Sprintf("to %s amount %s -> to %s amount %s", withdraw.ToAddress.String(), withdraw.Amount.String(), params.ToAddress.String(), params.Amount.String())
+	amended, err := h.withdrawsDB.AmendPendingWithdraw(withdraw.GUID, params.ToAddress, params.Amount, signDigest, detail)
+	if err != nil {
+		return nil, err
+	}
+	if !amended {
+		return &models.AmendWithdrawResponse{
+			Code: 4006,
+			Msg:  fmt.Sprintf("withdraw in status %d can not be amended", withdraw.Status),
+		}, nil
+	}
+	return &models.AmendWithdrawResponse{
+		Code: 2000,
+		Msg:  "amend withdraw success",
+	}, nil
+}
+
+// GetWithdrawDetail 查询提现详情和状态变更历史，提现不存在时返回 nil
+func (h HandlerSvc) GetWithdrawDetail(params *models.WithdrawLocatorParams) (*models.WithdrawDetailResponse, error) {
+	withdraw, err := h.findWithdraw(h.withdrawsView, params)
+	if err != nil || withdraw == nil {
+		return nil, err
+	}
+	eventList, err := h.eventsView.QueryWithdrawEvents(withdraw.GUID)
+	if err != nil {
+		return nil, err
+	}
+	return &models.WithdrawDetailResponse{
+		Withdraw: withdraw,
+		Events:   eventList,
+	}, nil
+}
+
+// GetBalanceHistory 查询地址或用户全部地址在指定区块或时间的余额快照
+func (h HandlerSvc) GetBalanceHistory(params *models.BalanceHistoryParams) (*models.BalanceHistoryResponse, error) {
+	response := &models.BalanceHistoryResponse{UserUid: params.UserUid, Timestamp: params.Timestamp}
+	var addressList []common.Address
+	if params.UserUid != "" {
+		userAddressList, err := h.addressView.QueryAddressesByUserUid(params.UserUid)
+		if err != nil {
+			return nil, err
+		}
+		for _, userAddress := range userAddressList {
+			addressList = append(addressList, userAddress.Address)
+		}
+	} else {
+		response.Address = params.Address.String()
+		addressList = append(addressList, params.Address)
+	}
+	var err error
+	if params.BlockNumber != nil {
+		response.BlockNumber = params.BlockNumber.String()
+		response.Balances, err = h.snapshotView.QueryBalancesAtBlock(addressList, params.BlockNumber)
+	} else {
+		response.Balances, err = h.snapshotView.QueryBalancesAtTime(addressList, params.Timestamp)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func (h HandlerSvc) GetReserves() (*models.ReservesResponse, error) {
+	reportList, err := h.reservesView.QueryLatestReserveReports()
+	if err != nil {
+		return nil, err
+	}
+	return &models.ReservesResponse{Reports: reportList}, nil
+}
+
+// GetReserveProof 查询用户在某一币种最近一次储备证明中的包含证明
+func (h HandlerSvc) GetReserveProof(params *models.ReserveProofParams) (*models.ReserveProofResponse, error) {
+	proof, err := h.reservesView.QueryLatestReserveProof(params.UserUid, params.TokenAddress)
+	if err != nil {
+		return nil, err
+	}
+	if proof == nil {
+		return &models.ReserveProofResponse{
+			Code: 4005,
+			Msg:  "reserve proof not found",
+		}, nil
+	}
+	report, err := h.reservesView.QueryReserveReportByGuid(proof.ReportGuid)
+	if err != nil {
+		return nil, err
+	}
+	var path []merklesum.ProofNode
+	if err := json.Unmarshal([]byte(proof.Proof), &path); err != nil {
+		return nil, err
+	}
+	return &models.ReserveProofResponse{
+		Code:   2000,
+		Msg:    "success",
+		Report: report,
+		Proof:  proof,
+		Path:   path,
+	}, nil
+}
+
+func (h HandlerSvc) GetAddressBook(userUid string) (*models.AddressBookListResponse, error) {
+	entryList, err := h.addressBookView.QueryAddressBook(userUid)
+	if err != nil {
+		return nil, err
+	}
+	return &models.AddressBookListResponse{
+		UserUid: userUid,
+		Records: entryList,
+	}, nil
+}
+
+func (h HandlerSvc) AddAddressBook(params *models.AddressBookParams) (*models.AddressBookResponse, error) {
+	entry, err := h.riskEngine.AddAddressBookEntry(params.UserUid, params.Address, params.Label, h.addressBook.CoolingPeriod)
+	if err != nil {
+		log.Error("add address book fail", "userUid", params.UserUid, "address", params.Address, "err", err)
+		return &models.AddressBookResponse{
+			Code: 4000,
+			Msg:  "add address book fail",
+		}, nil
+	}
+	return &models.AddressBookResponse{
+		Code:  2000,
+		Msg:   "add address book success",
+		Entry: entry,
+	}, nil
+}
+
+func (h HandlerSvc) RemoveAddressBook(params *models.AddressBookParams) (*models.AddressBookResponse, error) {
+	removed, err := h.riskEngine.RemoveAddressBookEntry(params.UserUid, params.Address)
+	if err != nil {
+		log.Error("remove address book fail", "userUid", params.UserUid, "address", params.Address, "err", err)
+		return &models.AddressBookResponse{
+			Code: 4000,
+			Msg:  "remove address book fail",
+		}, nil
+	}
+	if !removed {
+		return &models.AddressBookResponse{
+			Code: 4000,
+			Msg:  "address book entry not found",
+		}, nil
+	}
+	return &models.AddressBookResponse{
+		Code: 2000,
+		Msg:  "remove address book success",
+	}, nil
+}
+
+func (h HandlerSvc) GetRebalanceList(params *models.QueryPageParams) (*models.RebalancesResponse, error) {
+	rebalanceList, total := h.rebalancesView.QueryRebalanceList(params.Page, params.PageSize, params.Order)
+	return &models.RebalancesResponse{
+		Current: params.Page,
+		Size:    params.PageSize,
+		Total:   total,
+		Records: rebalanceList,
+	}, nil
+}
+
+// ReviewRebalance 审批冷转热申请，只有待审批的申请可以通过或拒绝
+func (h HandlerSvc) ReviewRebalance(params *models.RebalanceReviewParams) (*models.RebalanceReviewResponse, error) {
+	rebalance, err := h.rebalancesDB.QueryRebalanceByGuid(params.Guid)
+	if err != nil {
+		return nil, err
+	}
+	if rebalance == nil {
+		return &models.RebalanceReviewResponse{
+			Code: 4005,
+			Msg:  "rebalance not found",
+		}, nil
+	}
+	reviewed, err := h.rebalancesDB.ReviewRebalance(params.Guid, params.Approve, params.Operator, params.Reason)
+	if err != nil {
+		return nil, err
+	}
+	if !reviewed {
+		return &models.RebalanceReviewResponse{
+			Code:   4006,
+			Msg:    fmt.Sprintf("rebalance in status %d can not be reviewed", rebalance.Status),
+			Status: rebalance.Status,
+		}, nil
+	}
+	status := uint8(4)
+	if params.Approve {
+		status = 1
+	}
+	return &models.RebalanceReviewResponse{
+		Code:   2000,
+		Msg:    "rebalance reviewed",
+		Status: status,
 	}, nil
 }
 
-func (h HandlerSvc) SubmitDWParams(fromAddress string, toAddress string, tokenAddress string, amount string) (*models.SubmitDWParams, error) {
+// ReviewWithdraw 人工审核风控审核状态的提现，通过后重新进入出款流程，拒绝后转入风控拒绝状态
+func (h HandlerSvc) ReviewWithdraw(params *models.WithdrawReviewParams) (*models.WithdrawReviewResponse, error) {
+	withdraw, err := h.withdrawsDB.QueryWithdrawsByGuid(params.Guid)
+	if err != nil {
+		return nil, err
+	}
+	if withdraw == nil {
+		return &models.WithdrawReviewResponse{
+			Code: 4005,
+			Msg:  "withdraw not found",
+		}, nil
+	}
+	reviewed, err := h.riskEngine.ReviewWithdraw(params.Guid, params.Approve, params.Operator, params.Reason)
+	if err != nil {
+		return nil, err
+	}
+	if !reviewed {
+		return &models.WithdrawReviewResponse{
+			Code:   4006,
+			Msg:    fmt.Sprintf("withdraw in status %d can not be reviewed", withdraw.Status),
+			Status: withdraw.Status,
+		}, nil
+	}
+	status := uint8(7)
+	if params.Approve {
+		status = 0
+	}
+	return &models.WithdrawReviewResponse{
+		Code:   2000,
+		Msg:    "withdraw reviewed",
+		Status: status,
+	}, nil
+}
+
+// ImportColdTransactions 导入离线签名后的冷钱包交易文件，逐笔校验后广播
+func (h HandlerSvc) ImportColdTransactions(ctx context.Context, content []byte) (*models.ColdImportResponse, error) {
+	file, err := coldsign.DecodeFile(content)
+	if err != nil {
+		return &models.ColdImportResponse{
+			Code: 4000,
+			Msg:  fmt.Sprintf("invalid signed tx file: %v", err),
+		}, nil
+	}
+	resultList, err := h.coldOffline.Import(ctx, file)
+	if err != nil {
+		return nil, err
+	}
+	for _, result := range resultList {
+		if result.Error != "" {
+			return &models.ColdImportResponse{
+				Code:    4000,
+				Msg:     "some txs failed to import",
+				Results: resultList,
+			}, nil
+		}
+	}
+	return &models.ColdImportResponse{
+		Code:    2000,
+		Msg:     "import signed txs success",
+		Results: resultList,
+	}, nil
+}
+
+func (h HandlerSvc) SubmitDWParams(consumerToken string, requestId string, userUid string, fromAddress string, toAddress string, tokenAddress string, amount string, deadline string, signature string) (*models.SubmitDWParams, error) {
+	if requestId == "" {
+		log.Error("invalid request id param")
+		return nil, errors.New("request id is empty")
+	}
+
 	fromAddr, err := h.v.ParseValidateAddress(fromAddress)
 	if err != nil {
 		log.Error("invalid address param", "address", fromAddr.String(), "err", err)
@@ -96,11 +603,21 @@ func (h HandlerSvc) SubmitDWParams(fromAddress string, toAddress string, tokenAd
 		return nil, err
 	}
 
+	deadlineValue, signatureBytes, err := parseWithdrawSignature(deadline, signature)
+	if err != nil {
+		return nil, err
+	}
+
 	return &models.SubmitDWParams{
-		FromAddress:  fromAddr,
-		ToAddress:    toAddr,
-		TokenAddress: tokenAddr,
-		Amount:       transferAmount,
+		ConsumerToken: consumerToken,
+		RequestId:     requestId,
+		UserUid:       userUid,
+		FromAddress:   fromAddr,
+		ToAddress:     toAddr,
+		TokenAddress:  tokenAddr,
+		Amount:        transferAmount,
+		Deadline:      deadlineValue,
+		Signature:     signatureBytes,
 	}, nil
 }
 
@@ -138,6 +655,178 @@ func (h HandlerSvc) QueryDWListParams(address string, page string, pageSize stri
 	}, nil
 }
 
+func (h HandlerSvc) QueryLimitParams(userUid string, tokenAddress string) (*models.QueryLimitParams, error) {
+	tokenAddr, err := h.v.ParseValidateAddress(tokenAddress)
+	if err != nil {
+		log.Error("invalid address param", "address", tokenAddress, "err", err)
+		return nil, err
+	}
+	return &models.QueryLimitParams{
+		UserUid:      userUid,
+		TokenAddress: tokenAddr,
+	}, nil
+}
+
+func (h HandlerSvc) BalanceHistoryParams(address string, userUid string, blockNumber string, timestamp string) (*models.BalanceHistoryParams, error) {
+	params := &models.BalanceHistoryParams{UserUid: userUid}
+	if (address == "") == (userUid == "") {
+		return nil, errors.New("one of address and user uid is required")
+	}
+	if address != "" {
+		addr, err := h.v.ParseValidateAddress(address)
+		if err != nil {
+			log.Error("invalid address param", "address", address, "err", err)
+			return nil, err
+		}
+		params.Address = addr
+	}
+	if (blockNumber == "") == (timestamp == "") {
+		return nil, errors.New("one of block number and timestamp is required")
+	}
+	if blockNumber != "" {
+		number, ok := new(big.Int).SetString(blockNumber, 10)
+		if !ok || number.Sign() < 0 {
+			log.Error("invalid block number param", "blockNumber", blockNumber)
+			return nil, errors.New("invalid block number")
+		}
+		params.BlockNumber = number
+		return params, nil
+	}
+	timestampValue, err := strconv.ParseUint(timestamp, 10, 64)
+	if err != nil {
+		log.Error("invalid timestamp param", "timestamp", timestamp, "err", err)
+		return nil, err
+	}
+	params.Timestamp = timestampValue
+	return params, nil
+}
+
+func (h HandlerSvc) ReserveProofParams(userUid string, tokenAddress string) (*models.ReserveProofParams, error) {
+	if userUid == "" {
+		return nil, errors.New("user uid is required")
+	}
+	tokenAddr, err := h.v.ParseValidateAddress(tokenAddress)
+	if err != nil {
+		log.Error("invalid address param", "address", tokenAddress, "err", err)
+		return nil, err
+	}
+	return &models.ReserveProofParams{
+		UserUid:      userUid,
+		TokenAddress: tokenAddr,
+	}, nil
+}
+
+func (h HandlerSvc) AddressBookParams(userUid string, address string, label string) (*models.AddressBookParams, error) {
+	addr, err := h.v.ParseValidateAddress(address)
+	if err != nil {
+		log.Error("invalid address param", "address", address, "err", err)
+		return nil, err
+	}
+	return &models.AddressBookParams{
+		UserUid: userUid,
+		Address: addr,
+		Label:   label,
+	}, nil
+}
+
+func (h HandlerSvc) WithdrawLocatorParams(guid string, consumerToken string, requestId string) (*models.WithdrawLocatorParams, error) {
+	if guid != "" {
+		withdrawGuid, err := uuid.Parse(guid)
+		if err != nil {
+			log.Error("invalid guid param", "guid", guid, "err", err)
+			return nil, err
+		}
+		return &models.WithdrawLocatorParams{Guid: withdrawGuid}, nil
+	}
+	if requestId == "" {
+		return nil, errors.New("guid or request id is required")
+	}
+	return &models.WithdrawLocatorParams{
+		ConsumerToken: consumerToken,
+		RequestId:     requestId,
+	}, nil
+}
+
+func (h HandlerSvc) AmendWithdrawParams(locator *models.WithdrawLocatorParams, toAddress string, amount string, deadline string, signature string) (*models.AmendWithdrawParams, error) {
+	toAddr, err := h.v.ParseValidateAddress(toAddress)
+	if err != nil {
+		log.Error("invalid address param", "address", toAddress, "err", err)
+		return nil, err
+	}
+	amendAmount, ok := new(big.Int).SetString(amount, 10)
+	if !ok || amendAmount.Sign() <= 0 {
+		log.Error("invalid amount param", "amount", amount)
+		return nil, errors.New("invalid amount")
+	}
+	deadlineValue, signatureBytes, err := parseWithdrawSignature(deadline, signature)
+	if err != nil {
+		return nil, err
+	}
+	return &models.AmendWithdrawParams{
+		WithdrawLocatorParams: locator,
+		ToAddress:             toAddr,
+		Amount:                amendAmount,
+		Deadline:              deadlineValue,
+		Signature:             signatureBytes,
+	}, nil
+}
+
+func parseWithdrawSignature(deadline string, signature string) (uint64, []byte, error) {
+	var deadlineValue uint64
+	if deadline != "" {
+		value, err := strconv.ParseUint(deadline, 10, 64)
+		if err != nil {
+			log.Error("invalid deadline param", "deadline", deadline, "err", err)
+			return 0, nil, err
+		}
+		deadlineValue = value
+	}
+	var signatureBytes []byte
+	if signature != "" {
+		value, err := hexutil.Decode(signature)
+		if err != nil {
+			log.Error("invalid signature param", "signature", signature, "err", err)
+			return 0, nil, err
+		}
+		signatureBytes = value
+	}
+	return deadlineValue, signatureBytes, nil
+}
+
+func (h HandlerSvc) RebalanceReviewParams(guid string, approve bool, operator string, reason string) (*models.RebalanceReviewParams, error) {
+	rebalanceGuid, err := uuid.Parse(guid)
+	if err != nil {
+		log.Error("invalid guid param", "guid", guid, "err", err)
+		return nil, err
+	}
+	if operator == "" {
+		return nil, errors.New("operator is required")
+	}
+	return &models.RebalanceReviewParams{
+		Guid:     rebalanceGuid,
+		Approve:  approve,
+		Operator: operator,
+		Reason:   reason,
+	}, nil
+}
+
+func (h HandlerSvc) WithdrawReviewParams(guid string, approve bool, operator string, reason string) (*models.WithdrawReviewParams, error) {
+	withdrawGuid, err := uuid.Parse(guid)
+	if err != nil {
+		log.Error("invalid guid param", "guid", guid, "err", err)
+		return nil, err
+	}
+	if operator == "" {
+		return nil, errors.New("operator is required")
+	}
+	return &models.WithdrawReviewParams{
+		Guid:     withdrawGuid,
+		Approve:  approve,
+		Operator: operator,
+		Reason:   reason,
+	}, nil
+}
+
 func (h HandlerSvc) QueryPageListParams(page string, pageSize string, order string) (*models.QueryPageParams, error) {
 	pageInt, err := strconv.Atoi(page)
 	if err != nil {
@@ -18,6 +18,10 @@ import (
 	flags2 "github.com/the-web3/eth-wallet/flags"
 	"github.com/the-web3/eth-wallet/services"
 	"github.com/the-web3/eth-wallet/tools"
+	"github.com/the-web3/eth-wallet/wallet"
+	"github.com/the-web3/eth-wallet/wallet/hdwallet"
+	"github.com/the-web3/eth-wallet/wallet/kms"
+	"github.com/the-web3/eth-wallet/wallet/node"
 )
 
 func runEthWallet(ctx *cli.Context, shutdown context.CancelCauseFunc) (cliapp.Lifecycle, error) {
@@ -50,13 +54,25 @@ func runRpc(ctx *cli.Context, shutdown context.CancelCauseFunc) (cliapp.Lifecycl
 	grpcServerCfg := &services.RpcServerConfig{
 		GrpcHostname: cfg.RpcServer.Host,
 		GrpcPort:     cfg.RpcServer.Port,
+		AddressBook:  cfg.AddressBook,
+
+		ChainId:              cfg.Chain.ChainID,
+		WithdrawSignRequired: cfg.WithdrawSignRequired,
 	}
 	db, err := database.NewDB(ctx.Context, cfg.MasterDB)
 	if err != nil {
 		log.Error("failed to connect to database", "err", err)
 		return nil, err
 	}
-	return services.NewRpcServer(db, grpcServerCfg)
+	readDB := db
+	if cfg.SlaveDbEnable {
+		readDB, err = database.NewReplicaDB(ctx.Context, db, cfg.SlaveDB, cfg.SlaveDbLag)
+		if err != nil {
+			log.Error("failed to connect to slave database", "err", err)
+			return nil, err
+		}
+	}
+	return services.NewRpcServer(db, readDB, grpcServerCfg)
 }
 
 func runGenerateAddress(ctx *cli.Context) error {
@@ -70,12 +86,108 @@ func runGenerateAddress(ctx *cli.Context) error {
 		log.Error("failed to connect to database", "err", err)
 		return err
 	}
-	return tools.CreateAddressTools(ctx, db)
+	keyManager, err := kms.NewKMS(&cfg.KMS)
+	if err != nil {
+		log.Error("failed to init kms", "err", err)
+		return err
+	}
+	hd, err := hdwallet.NewHDWallet(&cfg.HDWallet)
+	if err != nil {
+		log.Error("failed to init hd wallet", "err", err)
+		return err
+	}
+	return tools.CreateAddressTools(ctx, db, keyManager, hd, cfg.HDWallet.ColdWalletAddress)
 }
 
-func runMigrations(ctx *cli.Context) error {
-	ctx.Context = opio.CancelOnInterrupt(ctx.Context)
-	log.Info("running migrations...")
+func runGenerateForwarderAddress(ctx *cli.Context) error {
+	cfg, err := config.LoadConfig(ctx)
+	if err != nil {
+		log.Error("failed to load config", "err", err)
+		return err
+	}
+	db, err := database.NewDB(ctx.Context, cfg.MasterDB)
+	if err != nil {
+		log.Error("failed to connect to database", "err", err)
+		return err
+	}
+	return tools.CreateForwarderAddressTools(ctx, db, &cfg.Chain)
+}
+
+func runVerifyHDAddresses(ctx *cli.Context) error {
+	cfg, err := config.LoadConfig(ctx)
+	if err != nil {
+		log.Error("failed to load config", "err", err)
+		return err
+	}
+	db, err := database.NewDB(ctx.Context, cfg.MasterDB)
+	if err != nil {
+		log.Error("failed to connect to database", "err", err)
+		return err
+	}
+	hd, err := hdwallet.NewHDWallet(&cfg.HDWallet)
+	if err != nil {
+		log.Error("failed to init hd wallet", "err", err)
+		return err
+	}
+	return tools.VerifyHDAddressesTools(ctx, db, hd)
+}
+
+func runRekey(ctx *cli.Context) error {
+	cfg, err := config.LoadConfig(ctx)
+	if err != nil {
+		log.Error("failed to load config", "err", err)
+		return err
+	}
+	db, err := database.NewDB(ctx.Context, cfg.MasterDB)
+	if err != nil {
+		log.Error("failed to connect to database", "err", err)
+		return err
+	}
+	keyManager, err := kms.NewKMS(&cfg.KMS)
+	if err != nil {
+		log.Error("failed to init kms", "err", err)
+		return err
+	}
+	return tools.RekeyAddressesTools(ctx, db, keyManager)
+}
+
+// newColdOffline 连接数据库和节点，cold-export 与 cold-import 共用
+func newColdOffline(ctx *cli.Context) (*wallet.ColdOffline, error) {
+	cfg, err := config.LoadConfig(ctx)
+	if err != nil {
+		log.Error("failed to load config", "err", err)
+		return nil, err
+	}
+	db, err := database.NewDB(ctx.Context, cfg.MasterDB)
+	if err != nil {
+		log.Error("failed to connect to database", "err", err)
+		return nil, err
+	}
+	ethClient, err := node.DialEthClient(ctx.Context, cfg.Chain.RpcUrl)
+	if err != nil {
+		log.Error("failed to dial eth client", "err", err)
+		return nil, err
+	}
+	return wallet.NewColdOffline(db, ethClient, cfg.Chain.ChainID), nil
+}
+
+func runColdExport(ctx *cli.Context) error {
+	coldOffline, err := newColdOffline(ctx)
+	if err != nil {
+		return err
+	}
+	return tools.ColdExportTools(ctx, coldOffline)
+}
+
+func runColdImport(ctx *cli.Context) error {
+	coldOffline, err := newColdOffline(ctx)
+	if err != nil {
+		return err
+	}
+	return tools.ColdImportTools(ctx, coldOffline)
+}
+
+func runReconcile(ctx *cli.Context) error {
 	cfg, err := config.LoadConfig(ctx)
 	if err != nil {
 		log.Error("failed to load config", "err", err)
@@ -86,13 +198,80 @@ func runMigrations(ctx *cli.Context) error {
 		log.Error("failed to connect to database", "err", err)
 		return err
 	}
-	defer func(db *database.DB) {
-		err := db.Close()
+	ethClient, err := node.DialEthClient(ctx.Context, cfg.Chain.RpcUrl)
+	if err != nil {
+		log.Error("failed to dial eth client", "err", err)
+		return err
+	}
+	defer ethClient.Close()
+	reconciler, err := wallet.NewReconciler(&cfg, db, ethClient, func(cause error) {})
+	if err != nil {
+		return err
+	}
+	return tools.ReconcileTools(ctx, reconciler)
+}
+
+func runProofOfReserves(ctx *cli.Context) error {
+	cfg, err := config.LoadConfig(ctx)
+	if err != nil {
+		log.Error("failed to load config", "err", err)
+		return err
+	}
+	db, err := database.NewDB(ctx.Context, cfg.MasterDB)
+	if err != nil {
+		log.Error("failed to connect to database", "err", err)
+		return err
+	}
+	ethClient, err := node.DialEthClient(ctx.Context, cfg.Chain.RpcUrl)
+	if err != nil {
+		log.Error("failed to dial eth client", "err", err)
+		return err
+	}
+	defer ethClient.Close()
+	return tools.ProofOfReservesTools(ctx, wallet.NewReserveReporter(db, ethClient, cfg.Reconcile.BatchSize))
+}
+
+func runLedgerCheck(ctx *cli.Context) error {
+	cfg, err := config.LoadConfig(ctx)
+	if err != nil {
+		log.Error("failed to load config", "err", err)
+		return err
+	}
+	db, err := database.NewDB(ctx.Context, cfg.MasterDB)
+	if err != nil {
+		log.Error("failed to connect to database", "err", err)
+		return err
+	}
+	return tools.LedgerCheckTools(ctx, db)
+}
+
+func runMigrations(ctx *cli.Context) error {
+	return runMigrate(tools.MigrateUpTools)(ctx)
+}
+
+// runMigrate 连接主库后执行迁移子命令
+func runMigrate(fn func(ctx *cli.Context, db *database.DB, migrationsFolder string) error) cli.ActionFunc {
+	return func(ctx *cli.Context) error {
+		ctx.Context = opio.CancelOnInterrupt(ctx.Context)
+		log.Info("running migrations...")
+		cfg, err := config.LoadConfig(ctx)
+		if err != nil {
+			log.Error("failed to load config", "err", err)
+			return err
+		}
+		db, err := database.NewDB(ctx.Context, cfg.MasterDB)
 		if err != nil {
-			log.Error("fail to close database", "err", err)
+			log.Error("failed to connect to database", "err", err)
+			return err
 		}
-	}(db)
-	return db.ExecuteSQLMigration(cfg.Migrations)
+		defer func(db *database.DB) {
+			err := db.Close()
+			if err != nil {
+				log.Error("fail to close database", "err", err)
+			}
+		}(db)
+		return fn(ctx, db, cfg.Migrations)
+	}
 }
 
 func NewCli(GitCommit string, GitData string) *cli.App {
@@ -120,6 +299,60 @@ func NewCli(GitCommit string, GitData string) *cli.App {
 				Description: "Run grenerate adddress tools",
 				Action:      runGenerateAddress,
 			},
+			{
+				Name:        "generate-forwarder-address",
+				Flags:       flags,
+				Description: "Create CREATE2 forwarder deposit addresses for the given user uids",
+				Action:      runGenerateForwarderAddress,
+			},
+			{
+				Name:        "rekey",
+				Flags:       flags,
+				Description: "Re-encrypt address private keys with the current kms master key",
+				Action:      runRekey,
+			},
+			{
+				Name:        "verify-hd-addresses",
+				Flags:       flags,
+				Description: "Verify every hd derived address against the mnemonic",
+				Action:      runVerifyHDAddresses,
+			},
+			{
+				Name:        "cold-export",
+				Flags:       flags,
+				Description: "Export approved cold wallet txs to an unsigned tx file for offline signing",
+				Action:      runColdExport,
+			},
+			{
+				Name:        "cold-sign",
+				Flags:       []cli.Flag{flags2.ColdTxFileFlag, flags2.ColdSignedTxFileFlag, flags2.ColdKeyFileFlag},
+				Description: "Sign an unsigned tx file with the cold wallet key file on an air-gapped machine",
+				Action:      tools.ColdSignTools,
+			},
+			{
+				Name:        "cold-import",
+				Flags:       flags,
+				Description: "Verify an offline signed tx file and broadcast it",
+				Action:      runColdImport,
+			},
+			{
+				Name:        "reconcile",
+				Flags:       flags,
+				Description: "Reconcile database balances against on-chain balances once and record the report",
+				Action:      runReconcile,
+			},
+			{
+				Name:        "proof-of-reserves",
+				Flags:       flags,
+				Description: "Build merkle sum trees over user liabilities, store per-user proofs and compare with on-chain holdings",
+				Action:      runProofOfReserves,
+			},
+			{
+				Name:        "ledger-check",
+				Flags:       flags,
+				Description: "Check that every ledger journal balances and balances match the ledger",
+				Action:      runLedgerCheck,
+			},
 			{
 				Name:        "wallet",
 				Flags:       flags,
@@ -131,6 +364,34 @@ func NewCli(GitCommit string, GitData string) *cli.App {
 				Flags:       flags,
 				Description: "Run database migrations",
 				Action:      runMigrations,
+				Subcommands: []*cli.Command{
+					{
+						Name:        "up",
+						Flags:       flags,
+						Description: "Apply all pending migrations",
+						Action:      runMigrate(tools.MigrateUpTools),
+					},
+					{
+						Name:        "down",
+						Flags:       flags,
+						ArgsUsage:   "[steps]",
+						Description: "Revert the latest applied migrations, one by default",
+						Action:      runMigrate(tools.MigrateDownTools),
+					},
+					{
+						Name:        "status",
+						Flags:       flags,
+						Description: "Show applied and pending migrations",
+						Action:      runMigrate(tools.MigrateStatusTools),
+					},
+					{
+						Name:        "to",
+						Flags:       flags,
+						ArgsUsage:   "<version>",
+						Description: "Apply or revert migrations until the given version",
+						Action:      runMigrate(tools.MigrateToTools),
+					},
+				},
 			},
 			{
 				Name:        "version",
@@ -1,10 +1,14 @@
 package config
 
 import (
+	"fmt"
+	"math/big"
+	"strings"
 	"time"
 
 	"github.com/urfave/cli/v2"
 
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/the-web3/eth-wallet/flags"
 )
@@ -16,6 +20,9 @@ const (
 	defaultCollectInterval  = 500
 	defaultColdInterval     = 500
 	defaultBlocksStep       = 500
+	defaultWithdrawBatch    = 100
+	defaultHotWalletPolicy  = "round-robin"
+	defaultReconcileBatch   = 100
 )
 
 type Config struct {
@@ -24,11 +31,28 @@ type Config struct {
 	MasterDB       DBConfig
 	SlaveDB        DBConfig
 	SlaveDbEnable  bool
+	SlaveDbLag     SlaveDbLagConfig
 	ApiCacheEnable bool
 	CacheConfig    CacheConfig
 	RpcServer      ServerConfig
 	HTTPServer     ServerConfig
 	MetricsServer  ServerConfig
+	HotWallet      HotWalletConfig
+	Signer         SignerConfig
+	KMS            KMSConfig
+	HDWallet       HDWalletConfig
+	AddressBook    AddressBookConfig
+	Reconcile      ReconcileConfig
+
+	WithdrawSignRequired    bool
+	ColdSignOffline         bool
+	BalanceSnapshotInterval time.Duration
+}
+
+// SlaveDbLagConfig 从库延迟超过 MaxLag 或检测失败时读请求回退主库
+type SlaveDbLagConfig struct {
+	MaxLag        time.Duration
+	CheckInterval time.Duration
 }
 
 type ChainConfig struct {
@@ -41,6 +65,53 @@ type ChainConfig struct {
 	CollectInterval  uint
 	ColdInterval     uint
 	BlocksStep       uint
+
+	WithdrawBatchEnable bool
+	WithdrawBatchSize   uint
+	DisperseContract    string
+	GasStationAddress   string
+
+	ForwarderFactory      string
+	ForwarderInitCodeHash string
+}
+
+type HotWalletConfig struct {
+	Strategy         string
+	TokenAssignments map[common.Address]common.Address
+	MaxPendingTx     uint
+}
+
+type SignerConfig struct {
+	Type                 string
+	KeystoreDir          string
+	KeystorePasswordFile string
+	RemoteUrl            string
+	RemoteMethod         string
+}
+
+type KMSConfig struct {
+	MasterKey              string
+	MasterKeyFile          string
+	PreviousMasterKeyFiles []string
+}
+
+type HDWalletConfig struct {
+	Mnemonic          string
+	MnemonicFile      string
+	Passphrase        string
+	ColdWalletAddress string
+}
+
+type AddressBookConfig struct {
+	Enforce       bool
+	CoolingPeriod time.Duration
+}
+
+type ReconcileConfig struct {
+	Interval        time.Duration
+	BatchSize       uint
+	Tolerance       *big.Int
+	TokenTolerances map[common.Address]*big.Int
 }
 
 type DBConfig struct {
@@ -91,6 +162,35 @@ func LoadConfig(cliCtx *cli.Context) (Config, error) {
 		cfg.Chain.BlocksStep = defaultBlocksStep
 	}
 
+	if cfg.Chain.WithdrawBatchSize == 0 {
+		cfg.Chain.WithdrawBatchSize = defaultWithdrawBatch
+	}
+
+	if cfg.HotWallet.Strategy == "" {
+		cfg.HotWallet.Strategy = defaultHotWalletPolicy
+	}
+
+	tokenAssignments, err := parseTokenAssignments(cliCtx.String(flags.HotWalletTokenAssignmentsFlag.Name))
+	if err != nil {
+		return cfg, err
+	}
+	cfg.HotWallet.TokenAssignments = tokenAssignments
+
+	if cfg.Reconcile.BatchSize == 0 {
+		cfg.Reconcile.BatchSize = defaultReconcileBatch
+	}
+
+	tolerance, ok := new(big.Int).SetString(cliCtx.String(flags.ReconcileToleranceFlag.Name), 10)
+	if !ok || tolerance.Sign() < 0 {
+		return cfg, fmt.Errorf("invalid reconcile tolerance: %s", cliCtx.String(flags.ReconcileToleranceFlag.Name))
+	}
+	cfg.Reconcile.Tolerance = tolerance
+	tokenTolerances, err := parseTokenTolerances(cliCtx.String(flags.ReconcileTokenTolerancesFlag.Name))
+	if err != nil {
+		return cfg, err
+	}
+	cfg.Reconcile.TokenTolerances = tokenTolerances
+
 	log.Info("loaded chain config", "config", cfg.Chain)
 	return cfg, nil
 }
@@ -108,6 +208,14 @@ func NewConfig(ctx *cli.Context) Config {
 			CollectInterval:  ctx.Uint(flags.CollectIntervalFlag.Name),
 			ColdInterval:     ctx.Uint(flags.ColdIntervalFlag.Name),
 			BlocksStep:       ctx.Uint(flags.BlocksStepFlag.Name),
+
+			WithdrawBatchEnable: ctx.Bool(flags.WithdrawBatchEnableFlag.Name),
+			WithdrawBatchSize:   ctx.Uint(flags.WithdrawBatchSizeFlag.Name),
+			DisperseContract:    ctx.String(flags.DisperseContractFlag.Name),
+			GasStationAddress:   ctx.String(flags.GasStationAddressFlag.Name),
+
+			ForwarderFactory:      ctx.String(flags.ForwarderFactoryFlag.Name),
+			ForwarderInitCodeHash: ctx.String(flags.ForwarderInitCodeHashFlag.Name),
 		},
 		MasterDB: DBConfig{
 			Host:     ctx.String(flags.MasterDbHostFlag.Name),
@@ -123,7 +231,11 @@ func NewConfig(ctx *cli.Context) Config {
 			User:     ctx.String(flags.SlaveDbUserFlag.Name),
 			Password: ctx.String(flags.SlaveDbPasswordFlag.Name),
 		},
-		SlaveDbEnable:  ctx.Bool(flags.SlaveDbEnableFlag.Name),
+		SlaveDbEnable: ctx.Bool(flags.SlaveDbEnableFlag.Name),
+		SlaveDbLag: SlaveDbLagConfig{
+			MaxLag:        ctx.Duration(flags.SlaveDbMaxLagFlag.Name),
+			CheckInterval: ctx.Duration(flags.SlaveDbLagCheckIntervalFlag.Name),
+		},
 		ApiCacheEnable: ctx.Bool(flags.ApiCacheEnableFlag.Name),
 		CacheConfig: CacheConfig{
 			ListSize:         ctx.Int(flags.ApiCacheListSizeFlag.Name),
@@ -143,5 +255,76 @@ func NewConfig(ctx *cli.Context) Config {
 			Host: ctx.String(flags.MetricsHostFlag.Name),
 			Port: ctx.Int(flags.MetricsPortFlag.Name),
 		},
+		HotWallet: HotWalletConfig{
+			Strategy:     ctx.String(flags.HotWalletStrategyFlag.Name),
+			MaxPendingTx: ctx.Uint(flags.HotWalletMaxPendingTxFlag.Name),
+		},
+		Signer: SignerConfig{
+			Type:                 ctx.String(flags.SignerTypeFlag.Name),
+			KeystoreDir:          ctx.String(flags.SignerKeystoreDirFlag.Name),
+			KeystorePasswordFile: ctx.String(flags.SignerKeystorePasswordFileFlag.Name),
+			RemoteUrl:            ctx.String(flags.SignerRemoteUrlFlag.Name),
+			RemoteMethod:         ctx.String(flags.SignerRemoteMethodFlag.Name),
+		},
+		KMS: KMSConfig{
+			MasterKey:              ctx.String(flags.KmsMasterKeyFlag.Name),
+			MasterKeyFile:          ctx.String(flags.KmsMasterKeyFileFlag.Name),
+			PreviousMasterKeyFiles: ctx.StringSlice(flags.KmsPreviousMasterKeyFilesFlag.Name),
+		},
+		HDWallet: HDWalletConfig{
+			Mnemonic:          ctx.String(flags.HdMnemonicFlag.Name),
+			MnemonicFile:      ctx.String(flags.HdMnemonicFileFlag.Name),
+			Passphrase:        ctx.String(flags.HdPassphraseFlag.Name),
+			ColdWalletAddress: ctx.String(flags.HdColdWalletAddressFlag.Name),
+		},
+		AddressBook: AddressBookConfig{
+			Enforce:       ctx.Bool(flags.AddressBookEnforceFlag.Name),
+			CoolingPeriod: ctx.Duration(flags.AddressBookCoolingPeriodFlag.Name),
+		},
+		Reconcile: ReconcileConfig{
+			Interval:  ctx.Duration(flags.ReconcileIntervalFlag.Name),
+			BatchSize: ctx.Uint(flags.ReconcileBatchSizeFlag.Name),
+		},
+		WithdrawSignRequired:    ctx.Bool(flags.WithdrawSignRequiredFlag.Name),
+		ColdSignOffline:         ctx.Bool(flags.ColdSignOfflineFlag.Name),
+		BalanceSnapshotInterval: ctx.Duration(flags.BalanceSnapshotIntervalFlag.Name),
+	}
+}
+
+// parseTokenAssignments 解析 token:hotWallet 形式的币种与热钱包绑定关系，多个绑定用逗号分隔
+func parseTokenAssignments(value string) (map[common.Address]common.Address, error) {
+	tokenAssignments := make(map[common.Address]common.Address)
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		parts := strings.Split(item, ":")
+		if len(parts) != 2 || !common.IsHexAddress(parts[0]) || !common.IsHexAddress(parts[1]) {
+			return nil, fmt.Errorf("invalid hot wallet token assignment: %s", item)
+		}
+		tokenAssignments[common.HexToAddress(parts[0])] = common.HexToAddress(parts[1])
+	}
+	return tokenAssignments, nil
+}
+
+// parseTokenTolerances 解析 token:amount 形式的对账容差，多个用逗号分隔
+func parseTokenTolerances(value string) (map[common.Address]*big.Int, error) {
+	tokenTolerances := make(map[common.Address]*big.Int)
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		parts := strings.Split(item, ":")
+		if len(parts) != 2 || !common.IsHexAddress(parts[0]) {
+			return nil, fmt.Errorf("invalid reconcile token tolerance: %s", item)
+		}
+		tolerance, ok := new(big.Int).SetString(parts[1], 10)
+		if !ok || tolerance.Sign() < 0 {
+			return nil, fmt.Errorf("invalid reconcile token tolerance: %s", item)
+		}
+		tokenTolerances[common.HexToAddress(parts[0])] = tolerance
 	}
+	return tokenTolerances, nil
 }
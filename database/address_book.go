@@ -0,0 +1,106 @@
+package database
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+type AddressBook struct {
+	GUID          uuid.UUID      `gorm:"primaryKey" json:"guid"`
+	UserUid       string         `json:"user_uid"` // 为空表示业务方登记、对所有用户生效的地址
+	Address       common.Address `json:"address" gorm:"serializer:bytes"`
+	Label         string         `json:"label"`
+	EffectiveTime uint64         `json:"effective_time"` // 冷却期结束时间，之后才能作为提现目标地址
+	Timestamp     uint64
+}
+
+func (AddressBook) TableName() string {
+	return "address_book"
+}
+
+type AddressBookView interface {
+	QueryAddressBook(userUid string) ([]AddressBook, error)
+	QueryAddressBookEntry(userUid string, address common.Address) (*AddressBook, error)
+	QueryWithdrawDestination(userUid string, address common.Address) (*AddressBook, error)
+}
+
+type AddressBookDB interface {
+	AddressBookView
+
+	StoreAddressBook(entry AddressBook) (*AddressBook, error)
+	RemoveAddressBook(userUid string, address common.Address) (bool, error)
+}
+
+type addressBookDB struct {
+	gorm *gorm.DB
+}
+
+func NewAddressBookDB(db *gorm.DB) AddressBookDB {
+	return &addressBookDB{gorm: db}
+}
+
+func (db *addressBookDB) QueryAddressBook(userUid string) ([]AddressBook, error) {
+	var entryList []AddressBook
+	err := db.gorm.Table("address_book").Where("user_uid = ?", userUid).Order("timestamp asc").Find(&entryList).Error
+	if err != nil {
+		return nil, err
+	}
+	return entryList, nil
+}
+
+func (db *addressBookDB) QueryAddressBookEntry(userUid string, address common.Address) (*AddressBook, error) {
+	var entry AddressBook
+	err := db.gorm.Table("address_book").Where("user_uid = ? and address = ?", userUid, strings.ToLower(address.String())).Take(&entry).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &entry, nil
+}
+
+// QueryWithdrawDestination 查询用户或业务方登记的该地址，有多条时取最早过冷却期的一条
+func (db *addressBookDB) QueryWithdrawDestination(userUid string, address common.Address) (*AddressBook, error) {
+	var entry AddressBook
+	err := db.gorm.Table("address_book").Where("user_uid in ? and address = ?", []string{"", userUid}, strings.ToLower(address.String())).Order("effective_time asc").Take(&entry).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &entry, nil
+}
+
+// StoreAddressBook 登记地址，已登记的地址只更新备注，不重置冷却期
+func (db *addressBookDB) StoreAddressBook(entry AddressBook) (*AddressBook, error) {
+	existEntry, err := db.QueryAddressBookEntry(entry.UserUid, entry.Address)
+	if err != nil {
+		return nil, err
+	}
+	if existEntry != nil {
+		existEntry.Label = entry.Label
+		if err := db.gorm.Table("address_book").Where("guid = ?", existEntry.GUID).Update("label", entry.Label).Error; err != nil {
+			return nil, err
+		}
+		return existEntry, nil
+	}
+	if err := db.gorm.Create(&entry).Error; err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+func (db *addressBookDB) RemoveAddressBook(userUid string, address common.Address) (bool, error) {
+	result := db.gorm.Where("user_uid = ? and address = ?", userUid, strings.ToLower(address.String())).Delete(&AddressBook{})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
@@ -11,25 +11,36 @@ import (
 )
 
 type Addresses struct {
-	GUID        uuid.UUID      `gorm:"primaryKey" json:"guid"`
-	UserUid     string         `json:"user_uid"`
-	Address     common.Address `json:"address" gorm:"serializer:bytes"`
-	AddressType uint8          `json:"address_type"` //0:用户地址；1:热钱包地址(归集地址)；2:冷钱包地址
-	PrivateKey  string         `json:"private_key"`
-	PublicKey   string         `json:"public_key"`
-	Timestamp   uint64
+	GUID            uuid.UUID      `gorm:"primaryKey" json:"guid"`
+	UserUid         string         `json:"user_uid"`
+	Address         common.Address `json:"address" gorm:"serializer:bytes"`
+	AddressType     uint8          `json:"address_type"` //0:用户地址；1:热钱包地址(归集地址)；2:冷钱包地址
+	PrivateKey      string         `json:"private_key"`  // data_key 非空时为 AES-GCM 加密后的私钥密文
+	PublicKey       string         `json:"public_key"`
+	DataKey         string         `json:"data_key"`         // 被主密钥包装的数据密钥
+	KeyId           string         `json:"key_id"`           // 包装数据密钥的主密钥标识
+	DerivationIndex *uint32        `json:"derivation_index"` // HD 派生地址 m/44'/60'/0'/0/i 中的 i，此类地址不保存私钥
+	ForwarderSalt   string         `json:"forwarder_salt"`   // CREATE2 充值转发地址的 salt，此类地址没有私钥，由工厂合约归集
+	Timestamp       uint64
 }
 
 type AddressesView interface {
 	QueryAddressesByToAddress(*common.Address) (*Addresses, error)
 	QueryHotWalletInfo() (*Addresses, error)
+	QueryHotWalletList() ([]Addresses, error)
 	QueryColdWalletInfo() (*Addresses, error)
+	QueryAddressesToRekey(keyId, boundPrefix string, limit int) ([]Addresses, error)
+	NextDerivationIndex() (uint32, error)
+	QueryDerivedAddresses(afterIndex int64, limit int) ([]Addresses, error)
+	QueryForwarderByUserUid(userUid string) (*Addresses, error)
+	QueryAddressesByUserUid(userUid string) ([]Addresses, error)
 }
 
 type AddressesDB interface {
 	AddressesView
 
 	StoreAddressess([]Addresses, uint64) error
+	UpdateAddressPrivateKey(guid uuid.UUID, privateKey, dataKey, keyId string) error
 }
 
 type addressesDB struct {
@@ -69,6 +80,15 @@ func (db *addressesDB) QueryHotWalletInfo() (*Addresses, error) {
 	return &addressEntry, nil
 }
 
+func (db *addressesDB) QueryHotWalletList() ([]Addresses, error) {
+	var addressList []Addresses
+	err := db.gorm.Table("addresses").Where("address_type", 1).Order("timestamp asc").Find(&addressList).Error
+	if err != nil {
+		return nil, err
+	}
+	return addressList, nil
+}
+
 func (db *addressesDB) QueryColdWalletInfo() (*Addresses, error) {
 	var addressEntry Addresses
 	err := db.gorm.Table("addresses").Where("address_type", 2).Take(&addressEntry).Error
@@ -80,3 +100,64 @@ func (db *addressesDB) QueryColdWalletInfo() (*Addresses, error) {
 	}
 	return &addressEntry, nil
 }
+
+// QueryAddressesToRekey 查询私钥未使用 keyId 主密钥加密或密文没有 boundPrefix 前缀的地址，包括仍是明文的历史地址，HD 派生地址和只保存地址的冷钱包不保存私钥因此不参与
+func (db *addressesDB) QueryAddressesToRekey(keyId, boundPrefix string, limit int) ([]Addresses, error) {
+	var addressList []Addresses
+	err := db.gorm.Table("addresses").Where("(key_id <> ? or private_key not like ?) and private_key <> '' and derivation_index is null and forwarder_salt = ''", keyId, boundPrefix+"%").Order("timestamp asc").Limit(limit).Find(&addressList).Error
+	if err != nil {
+		return nil, err
+	}
+	return addressList, nil
+}
+
+func (db *addressesDB) UpdateAddressPrivateKey(guid uuid.UUID, privateKey, dataKey, keyId string) error {
+	return db.gorm.Table("addresses").Where("guid = ?", guid).Updates(map[string]interface{}{
+		"private_key": privateKey,
+		"data_key":    dataKey,
+		"key_id":      keyId,
+	}).Error
+}
+
+func (db *addressesDB) NextDerivationIndex() (uint32, error) {
+	var maxIndex *int64
+	err := db.gorm.Table("addresses").Select("max(derivation_index)").Scan(&maxIndex).Error
+	if err != nil {
+		return 0, err
+	}
+	if maxIndex == nil {
+		return 0, nil
+	}
+	return uint32(*maxIndex + 1), nil
+}
+
+// QueryDerivedAddresses 按派生序号分页查询 HD 派生地址
+func (db *addressesDB) QueryDerivedAddresses(afterIndex int64, limit int) ([]Addresses, error) {
+	var addressList []Addresses
+	err := db.gorm.Table("addresses").Where("derivation_index > ?", afterIndex).Order("derivation_index asc").Limit(limit).Find(&addressList).Error
+	if err != nil {
+		return nil, err
+	}
+	return addressList, nil
+}
+
+func (db *addressesDB) QueryForwarderByUserUid(userUid string) (*Addresses, error) {
+	var addressEntry Addresses
+	err := db.gorm.Table("addresses").Where("user_uid = ? and forwarder_salt <> ''", userUid).Take(&addressEntry).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &addressEntry, nil
+}
+
+func (db *addressesDB) QueryAddressesByUserUid(userUid string) ([]Addresses, error) {
+	var addressList []Addresses
+	err := db.gorm.Table("addresses").Where("user_uid = ?", userUid).Find(&addressList).Error
+	if err != nil {
+		return nil, err
+	}
+	return addressList, nil
+}
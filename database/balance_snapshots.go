@@ -0,0 +1,110 @@
+package database
+
+import (
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// BalanceSnapshots 扫块处理到 block_number 时地址的余额，只在余额相对上一次快照变化时记录
+type BalanceSnapshots struct {
+	GUID           uuid.UUID      `gorm:"primaryKey" json:"guid"`
+	Address        common.Address `json:"address" gorm:"serializer:bytes"`
+	AddressType    uint8          `json:"address_type"`
+	TokenAddress   common.Address `json:"token_address" gorm:"serializer:bytes"`
+	Balance        *big.Int       `gorm:"serializer:u256;column:balance" json:"balance"`
+	LockBalance    *big.Int       `gorm:"serializer:u256;column:lock_balance" json:"lock_balance"`
+	BlockNumber    *big.Int       `gorm:"serializer:u256;column:block_number" json:"block_number"`
+	BlockTimestamp uint64         `json:"block_timestamp"`
+	Timestamp      uint64
+}
+
+func (BalanceSnapshots) TableName() string {
+	return "balance_snapshots"
+}
+
+type BalanceSnapshotsView interface {
+	QueryBalancesAtBlock(addressList []common.Address, blockNumber *big.Int) ([]BalanceSnapshots, error)
+	QueryBalancesAtTime(addressList []common.Address, timestamp uint64) ([]BalanceSnapshots, error)
+}
+
+type BalanceSnapshotsDB interface {
+	BalanceSnapshotsView
+
+	SnapshotBalances(since uint64, blockNumber *big.Int, blockTimestamp uint64) (int64, error)
+	SnapshotLatestBalances() (int64, error)
+}
+
+type balanceSnapshotsDB struct {
+	gorm *gorm.DB
+}
+
+func NewBalanceSnapshotsDB(db *gorm.DB) BalanceSnapshotsDB {
+	return &balanceSnapshotsDB{gorm: db}
+}
+
+// SnapshotBalances 扫块批次按本批最后一个区块记录与上一次快照不同的余额；since 大于 0 时只比较此后有分录的地址，为 0 时比较全部余额
+// 该区块的快照只由扫块批次写入，重复处理同一批次时覆盖
+func (db *balanceSnapshotsDB) SnapshotBalances(since uint64, blockNumber *big.Int, blockTimestamp uint64) (int64, error) {
+	changedFilter := ""
+	args := []interface{}{time.Now().Unix(), blockNumber.String(), blockTimestamp}
+	if since > 0 {
+		changedFilter = "AND EXISTS (SELECT 1 FROM ledger_entries l WHERE l.address = b.address AND l.token_address = b.token_address AND l.timestamp >= ?)"
+		args = append(args, since)
+	}
+	return db.snapshot("SELECT ?::NUMERIC AS number, ?::INTEGER AS timestamp", changedFilter,
+		"DO UPDATE SET balance = EXCLUDED.balance, lock_balance = EXCLUDED.lock_balance, timestamp = EXCLUDED.timestamp", args)
+}
+
+// SnapshotLatestBalances 按扫块已处理的最新区块全量比较余额，用于定期快照和对账；该区块已有批次快照时保留批次快照
+func (db *balanceSnapshotsDB) SnapshotLatestBalances() (int64, error) {
+	return db.snapshot("SELECT number, timestamp FROM blocks ORDER BY number DESC LIMIT 1", "", "DO NOTHING", []interface{}{time.Now().Unix()})
+}
+
+func (db *balanceSnapshotsDB) snapshot(blockQuery, changedFilter, onConflict string, args []interface{}) (int64, error) {
+	result := db.gorm.Exec(`INSERT INTO balance_snapshots (guid, address, address_type, token_address, balance, lock_balance, block_number, block_timestamp, timestamp)
+	SELECT md5(random()::text || clock_timestamp()::text)::uuid::text, b.address, b.address_type, b.token_address, b.balance, b.lock_balance, latest.number, latest.timestamp, ?
+	FROM balances b
+	CROSS JOIN (`+blockQuery+`) latest
+	LEFT JOIN LATERAL (
+		SELECT s.balance, s.lock_balance FROM balance_snapshots s
+		WHERE s.address = b.address AND s.token_address = b.token_address
+		ORDER BY s.block_number DESC LIMIT 1
+	) last ON TRUE
+	WHERE (last.balance IS DISTINCT FROM b.balance OR last.lock_balance IS DISTINCT FROM b.lock_balance) `+changedFilter+`
+	ON CONFLICT (address, token_address, block_number) `+onConflict, args...)
+	return result.RowsAffected, result.Error
+}
+
+// QueryBalancesAtBlock 每个地址和币种取不晚于 blockNumber 的最后一条快照
+func (db *balanceSnapshotsDB) QueryBalancesAtBlock(addressList []common.Address, blockNumber *big.Int) ([]BalanceSnapshots, error) {
+	return db.queryLatest(addressList, "block_number <= ?", blockNumber.Uint64())
+}
+
+// QueryBalancesAtTime 每个地址和币种取区块时间不晚于 timestamp 的最后一条快照
+func (db *balanceSnapshotsDB) QueryBalancesAtTime(addressList []common.Address, timestamp uint64) ([]BalanceSnapshots, error) {
+	return db.queryLatest(addressList, "block_timestamp <= ?", timestamp)
+}
+
+func (db *balanceSnapshotsDB) queryLatest(addressList []common.Address, condition string, value interface{}) ([]BalanceSnapshots, error) {
+	if len(addressList) == 0 {
+		return nil, nil
+	}
+	addresses := make([]string, len(addressList))
+	for i, address := range addressList {
+		addresses[i] = strings.ToLower(address.String())
+	}
+	var snapshotList []BalanceSnapshots
+	err := db.gorm.Table("balance_snapshots").Select("DISTINCT ON (address, token_address) *").
+		Where("address IN ?", addresses).Where(condition, value).
+		Order("address, token_address, block_number desc").Find(&snapshotList).Error
+	if err != nil {
+		return nil, err
+	}
+	return snapshotList, nil
+}
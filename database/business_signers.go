@@ -0,0 +1,51 @@
+package database
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+type BusinessSigners struct {
+	GUID          uuid.UUID      `gorm:"primaryKey" json:"guid"`
+	ConsumerToken string         `json:"consumer_token"`
+	SignerAddress common.Address `json:"signer_address" gorm:"serializer:bytes"`
+	Enable        bool           `json:"enable"`
+	Timestamp     uint64
+}
+
+func (BusinessSigners) TableName() string {
+	return "business_signers"
+}
+
+type BusinessSignersView interface {
+	QueryEnableBusinessSigner(consumerToken string, signerAddress common.Address) (*BusinessSigners, error)
+}
+
+type BusinessSignersDB interface {
+	BusinessSignersView
+}
+
+type businessSignersDB struct {
+	gorm *gorm.DB
+}
+
+func NewBusinessSignersDB(db *gorm.DB) BusinessSignersDB {
+	return &businessSignersDB{gorm: db}
+}
+
+func (db *businessSignersDB) QueryEnableBusinessSigner(consumerToken string, signerAddress common.Address) (*BusinessSigners, error) {
+	var signer BusinessSigners
+	err := db.gorm.Table("business_signers").Where("consumer_token = ? and signer_address = ? and enable = ?", consumerToken, strings.ToLower(signerAddress.String()), true).Take(&signer).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &signer, nil
+}
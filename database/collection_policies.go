@@ -0,0 +1,63 @@
+package database
+
+import (
+	"math/big"
+	"strings"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// CollectionPolicies 按币种配置的归集策略，token_address 为空的策略对没有单独配置的币种生效
+type CollectionPolicies struct {
+	GUID             uuid.UUID `gorm:"primaryKey" json:"guid"`
+	TokenAddress     string    `json:"token_address"`
+	Threshold        *big.Int  `gorm:"serializer:u256;column:threshold" db:"threshold" json:"Threshold" form:"threshold"`               // 余额达到该值才归集，0 表示使用 tokens.collect_amount
+	WindowStart      uint8     `json:"window_start"`                                                                                    // 允许归集的 UTC 起始小时
+	WindowEnd        uint8     `json:"window_end"`                                                                                      // 允许归集的 UTC 结束小时（不含），与起始小时相等表示全天
+	MaxGasPrice      *big.Int  `gorm:"serializer:u256;column:max_gas_price" db:"max_gas_price" json:"MaxGasPrice" form:"max_gas_price"` // 当前 gas price 高于该值时暂停归集，0 表示不限制
+	MinValueFeeRatio uint64    `json:"min_value_fee_ratio"`                                                                             // ETH 归集金额至少是手续费的倍数，0 表示不限制，代币策略不能设置
+	BatchSize        uint64    `json:"batch_size"`                                                                                      // 每轮最多归集的地址数，0 表示不限制
+	Enable           bool      `json:"enable"`
+	Timestamp        uint64
+}
+
+func (CollectionPolicies) TableName() string {
+	return "collection_policies"
+}
+
+type CollectionPoliciesView interface {
+	QueryEnableCollectionPolicies() (map[common.Address]CollectionPolicies, *CollectionPolicies, error)
+}
+
+type CollectionPoliciesDB interface {
+	CollectionPoliciesView
+}
+
+type collectionPoliciesDB struct {
+	gorm *gorm.DB
+}
+
+func NewCollectionPoliciesDB(db *gorm.DB) CollectionPoliciesDB {
+	return &collectionPoliciesDB{gorm: db}
+}
+
+// QueryEnableCollectionPolicies 返回按币种索引的归集策略以及默认策略，没有默认策略时返回 nil
+func (db *collectionPoliciesDB) QueryEnableCollectionPolicies() (map[common.Address]CollectionPolicies, *CollectionPolicies, error) {
+	var policyList []CollectionPolicies
+	if err := db.gorm.Table("collection_policies").Where("enable = ?", true).Find(&policyList).Error; err != nil {
+		return nil, nil, err
+	}
+	policies := make(map[common.Address]CollectionPolicies)
+	var defaultPolicy *CollectionPolicies
+	for i, policy := range policyList {
+		if strings.TrimSpace(policy.TokenAddress) == "" {
+			defaultPolicy = &policyList[i]
+			continue
+		}
+		policies[common.HexToAddress(policy.TokenAddress)] = policy
+	}
+	return policies, defaultPolicy, nil
+}
@@ -2,11 +2,9 @@ package database
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
-	"os"
-	"path/filepath"
 
-	"github.com/pkg/errors"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 
@@ -16,7 +14,8 @@ import (
 )
 
 type DB struct {
-	gorm *gorm.DB
+	gorm    *gorm.DB
+	replica *ReplicaPool
 
 	Blocks       BlocksDB
 	Addresses    AddressesDB
@@ -25,6 +24,22 @@ type DB struct {
 	Withdraws    WithdrawsDB
 	Transactions TransactionsDB
 	Tokens       TokensDB
+	Risk         RiskDB
+
+	WithdrawBatches    WithdrawBatchesDB
+	WithdrawLimits     WithdrawLimitsDB
+	AddressBook        AddressBookDB
+	BusinessSigners    BusinessSignersDB
+	WithdrawEvents     WithdrawEventsDB
+	GasFundings        GasFundingsDB
+	CollectionPolicies CollectionPoliciesDB
+	HotWalletBands     HotWalletBandsDB
+	Rebalances         RebalancesDB
+	ForwarderSweeps    ForwarderSweepsDB
+	LedgerEntries      LedgerEntriesDB
+	Reconciliations    ReconciliationsDB
+	BalanceSnapshots   BalanceSnapshotsDB
+	Reserves           ReservesDB
 }
 
 func NewDB(ctx context.Context, dbConfig config.DBConfig) (*DB, error) {
@@ -39,14 +54,9 @@ func NewDB(ctx context.Context, dbConfig config.DBConfig) (*DB, error) {
 		dsn += fmt.Sprintf(" password=%s", dbConfig.Password)
 	}
 
-	gormConfig := gorm.Config{
-		SkipDefaultTransaction: true,
-		CreateBatchSize:        3_000,
-	}
-
 	retryStrategy := &retry.ExponentialStrategy{Min: 1000, Max: 20_000, MaxJitter: 250}
 	gorm, err := retry.Do[*gorm.DB](context.Background(), 10, retryStrategy, func() (*gorm.DB, error) {
-		gorm, err := gorm.Open(postgres.Open(dsn), &gormConfig)
+		gorm, err := gorm.Open(postgres.Open(dsn), newGormConfig())
 		if err != nil {
 			return nil, fmt.Errorf("failed to connect to database: %w", err)
 		}
@@ -57,7 +67,18 @@ func NewDB(ctx context.Context, dbConfig config.DBConfig) (*DB, error) {
 		return nil, err
 	}
 
-	db := &DB{
+	return newDB(gorm), nil
+}
+
+func newGormConfig() *gorm.Config {
+	return &gorm.Config{
+		SkipDefaultTransaction: true,
+		CreateBatchSize:        3_000,
+	}
+}
+
+func newDB(gorm *gorm.DB) *DB {
+	return &DB{
 		gorm: gorm,
 
 		Blocks:       NewBlocksDB(gorm),
@@ -67,52 +88,45 @@ func NewDB(ctx context.Context, dbConfig config.DBConfig) (*DB, error) {
 		Withdraws:    NewWithdrawsDB(gorm),
 		Transactions: NewTransactionsDB(gorm),
 		Tokens:       NewTokensDB(gorm),
+		Risk:         NewRiskDB(gorm),
+
+		WithdrawBatches:    NewWithdrawBatchesDB(gorm),
+		WithdrawLimits:     NewWithdrawLimitsDB(gorm),
+		AddressBook:        NewAddressBookDB(gorm),
+		BusinessSigners:    NewBusinessSignersDB(gorm),
+		WithdrawEvents:     NewWithdrawEventsDB(gorm),
+		GasFundings:        NewGasFundingsDB(gorm),
+		CollectionPolicies: NewCollectionPoliciesDB(gorm),
+		HotWalletBands:     NewHotWalletBandsDB(gorm),
+		Rebalances:         NewRebalancesDB(gorm),
+		ForwarderSweeps:    NewForwarderSweepsDB(gorm),
+		LedgerEntries:      NewLedgerEntriesDB(gorm),
+		Reconciliations:    NewReconciliationsDB(gorm),
+		BalanceSnapshots:   NewBalanceSnapshotsDB(gorm),
+		Reserves:           NewReservesDB(gorm),
 	}
-	return db, nil
 }
 
 func (db *DB) Transaction(fn func(db *DB) error) error {
 	return db.gorm.Transaction(func(tx *gorm.DB) error {
-		txDB := &DB{
-			gorm:         tx,
-			Blocks:       NewBlocksDB(tx),
-			Addresses:    NewAddressesDB(tx),
-			Balances:     NewBalancesDB(tx),
-			Deposits:     NewDepositsDB(tx),
-			Withdraws:    NewWithdrawsDB(tx),
-			Transactions: NewTransactionsDB(tx),
-			Tokens:       NewTokensDB(tx),
-		}
-		return fn(txDB)
+		return fn(newDB(tx))
 	})
 }
 
+// SnapshotTransaction 可重复读事务，事务内的查询都读取事务开始时的同一份数据
+func (db *DB) SnapshotTransaction(fn func(db *DB) error) error {
+	return db.gorm.Transaction(func(tx *gorm.DB) error {
+		return fn(newDB(tx))
+	}, &sql.TxOptions{Isolation: sql.LevelRepeatableRead})
+}
+
 func (db *DB) Close() error {
+	if db.replica != nil {
+		return db.replica.Close()
+	}
 	sql, err := db.gorm.DB()
 	if err != nil {
 		return err
 	}
 	return sql.Close()
 }
-
-func (db *DB) ExecuteSQLMigration(migrationsFolder string) error {
-	err := filepath.Walk(migrationsFolder, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return errors.Wrap(err, fmt.Sprintf("Failed to process migration file: %s", path))
-		}
-		if info.IsDir() {
-			return nil
-		}
-		fileContent, readErr := os.ReadFile(path)
-		if readErr != nil {
-			return errors.Wrap(readErr, fmt.Sprintf("Error reading SQL file: %s", path))
-		}
-
-		execErr := db.gorm.Exec(string(fileContent)).Error
-		if execErr != nil {
-			return errors.Wrap(execErr, fmt.Sprintf("Error executing SQL script: %s", path))
-		}
-		return nil
-	})
-	return err
-}
@@ -28,6 +28,7 @@ type Deposits struct {
 }
 
 type DepositsView interface {
+	QueryDepositsByHash(hash common.Hash) (*Deposits, error)
 	ApiDepositList(string, int, int, string) ([]Deposits, int64)
 }
 
@@ -71,6 +72,18 @@ func (db *depositsDB) ApiDepositList(address string, page int, pageSize int, ord
 	return depositList, totalRecord
 }
 
+func (db *depositsDB) QueryDepositsByHash(hash common.Hash) (*Deposits, error) {
+	var depositEntity Deposits
+	result := db.gorm.Table("deposits").Where("hash = ?", hash.String()).Take(&depositEntity)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, result.Error
+	}
+	return &depositEntity, nil
+}
+
 func (db *depositsDB) UpdateDepositsStatus(blockNumber uint64) error {
 	result := db.gorm.Model(&Deposits{}).Where("status = ? and block_number <= ?", 0, blockNumber).Updates(map[string]interface{}{"status": 1})
 	if result.Error != nil {
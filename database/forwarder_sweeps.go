@@ -0,0 +1,95 @@
+package database
+
+import (
+	"math/big"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// ForwarderSweeps 一笔工厂合约批量归集交易中单个转发地址的归集记录，同一交易的记录共用 hash
+type ForwarderSweeps struct {
+	GUID             uuid.UUID      `gorm:"primaryKey" json:"guid"`
+	Hash             common.Hash    `gorm:"column:hash;serializer:bytes" json:"hash"`
+	HotWallet        common.Address `gorm:"column:hot_wallet;serializer:bytes" json:"hot_wallet"`
+	ForwarderAddress common.Address `gorm:"column:forwarder_address;serializer:bytes" json:"forwarder_address"`
+	TokenAddress     common.Address `gorm:"column:token_address;serializer:bytes" json:"token_address"`
+	Amount           *big.Int       `gorm:"serializer:u256;column:amount" json:"amount"`
+	Fee              *big.Int       `gorm:"serializer:u256;column:fee" json:"fee"` // 整笔归集交易的手续费
+	BlockNumber      *big.Int       `gorm:"serializer:u256;column:block_number" json:"block_number"`
+	Status           uint8          `json:"status"` // 0:归集交易已发送；1:上链成功；2:上链失败
+	TxSignHex        string         `json:"tx_sign_hex" gorm:"column:tx_sign_hex"`
+	Timestamp        uint64
+}
+
+func (ForwarderSweeps) TableName() string {
+	return "forwarder_sweeps"
+}
+
+type ForwarderSweepsView interface {
+	QueryForwarderSweepsByHash(hash common.Hash) ([]ForwarderSweeps, error)
+	QueryUnconfirmedForwarderSweeps() ([]ForwarderSweeps, error)
+}
+
+type ForwarderSweepsDB interface {
+	ForwarderSweepsView
+
+	StoreForwarderSweeps(sweepList []ForwarderSweeps) error
+	UpdateForwarderSweepStatus(sweep ForwarderSweeps) error
+	MarkForwarderSweepsReplaced(hash common.Hash) error
+}
+
+type forwarderSweepsDB struct {
+	gorm *gorm.DB
+}
+
+func NewForwarderSweepsDB(db *gorm.DB) ForwarderSweepsDB {
+	return &forwarderSweepsDB{gorm: db}
+}
+
+func (db *forwarderSweepsDB) QueryForwarderSweepsByHash(hash common.Hash) ([]ForwarderSweeps, error) {
+	var sweepList []ForwarderSweeps
+	err := db.gorm.Table("forwarder_sweeps").Where("hash = ?", hash.String()).Find(&sweepList).Error
+	if err != nil {
+		return nil, err
+	}
+	return sweepList, nil
+}
+
+// QueryUnconfirmedForwarderSweeps 查询已签名并持久化、但还未确认上链的归集交易，每笔交易只返回一条记录
+func (db *forwarderSweepsDB) QueryUnconfirmedForwarderSweeps() ([]ForwarderSweeps, error) {
+	var sweepList []ForwarderSweeps
+	err := db.gorm.Table("forwarder_sweeps").Where("status = ? and tx_sign_hex <> ?", 0, "").Find(&sweepList).Error
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[common.Hash]bool)
+	unconfirmedList := make([]ForwarderSweeps, 0, len(sweepList))
+	for _, sweep := range sweepList {
+		if seen[sweep.Hash] {
+			continue
+		}
+		seen[sweep.Hash] = true
+		unconfirmedList = append(unconfirmedList, sweep)
+	}
+	return unconfirmedList, nil
+}
+
+func (db *forwarderSweepsDB) StoreForwarderSweeps(sweepList []ForwarderSweeps) error {
+	return db.gorm.CreateInBatches(&sweepList, len(sweepList)).Error
+}
+
+func (db *forwarderSweepsDB) UpdateForwarderSweepStatus(sweep ForwarderSweeps) error {
+	return db.gorm.Model(&ForwarderSweeps{}).Where("guid = ? and status = ?", sweep.GUID.String(), 0).Updates(map[string]interface{}{
+		"status":       sweep.Status,
+		"block_number": sweep.BlockNumber.String(),
+		"fee":          sweep.Fee.String(),
+	}).Error
+}
+
+// MarkForwarderSweepsReplaced nonce 被占用的归集交易作废，交易内的记录全部标记为上链失败
+func (db *forwarderSweepsDB) MarkForwarderSweepsReplaced(hash common.Hash) error {
+	return db.gorm.Table("forwarder_sweeps").Where("hash = ? and status = ?", hash.String(), 0).Update("status", 2).Error
+}
@@ -0,0 +1,94 @@
+package database
+
+import (
+	"errors"
+	"math/big"
+	"strings"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// TxTypeGasFunding 交易表中 gas 补充交易的类型
+const TxTypeGasFunding uint8 = 5
+
+// GasFundings 记录为用户地址补充 gas 的交易以及随后的 token 归集交易
+// Status 0:补充交易已发送；1:归集交易已发送；2:补充交易失败
+type GasFundings struct {
+	GUID           uuid.UUID      `gorm:"primaryKey" json:"guid"`
+	Address        common.Address `gorm:"serializer:bytes" json:"address"`
+	TokenAddress   common.Address `gorm:"serializer:bytes" json:"token_address"`
+	FundingHash    common.Hash    `gorm:"serializer:bytes" json:"funding_hash"`
+	FundingAmount  *big.Int       `gorm:"serializer:u256" json:"funding_amount"`
+	CollectionHash string         `json:"collection_hash"`
+	Status         uint8          `json:"status"`
+	Timestamp      uint64
+}
+
+func (GasFundings) TableName() string {
+	return "gas_fundings"
+}
+
+type GasFundingsView interface {
+	QueryPendingGasFunding(address common.Address, tokenAddress common.Address) (*GasFundings, error)
+	QueryGasFundingByHash(fundingHash common.Hash) (*GasFundings, error)
+}
+
+type GasFundingsDB interface {
+	GasFundingsView
+
+	StoreGasFunding(funding GasFundings) error
+	MarkGasFundingCollected(guid uuid.UUID, collectionHash common.Hash) error
+	MarkGasFundingFailed(guid uuid.UUID) error
+}
+
+type gasFundingsDB struct {
+	gorm *gorm.DB
+}
+
+func NewGasFundingsDB(db *gorm.DB) GasFundingsDB {
+	return &gasFundingsDB{gorm: db}
+}
+
+// QueryPendingGasFunding 查询地址上某币种尚未归集的 gas 补充记录
+func (db *gasFundingsDB) QueryPendingGasFunding(address common.Address, tokenAddress common.Address) (*GasFundings, error) {
+	var funding GasFundings
+	err := db.gorm.Table("gas_fundings").
+		Where("address = ? and token_address = ? and status = ?", strings.ToLower(address.String()), strings.ToLower(tokenAddress.String()), 0).
+		Order("timestamp desc").Take(&funding).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &funding, nil
+}
+
+func (db *gasFundingsDB) QueryGasFundingByHash(fundingHash common.Hash) (*GasFundings, error) {
+	var funding GasFundings
+	err := db.gorm.Table("gas_fundings").Where("funding_hash = ?", fundingHash.String()).Take(&funding).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &funding, nil
+}
+
+func (db *gasFundingsDB) StoreGasFunding(funding GasFundings) error {
+	return db.gorm.Create(&funding).Error
+}
+
+// MarkGasFundingCollected 归集交易发出后关联归集交易哈希
+func (db *gasFundingsDB) MarkGasFundingCollected(guid uuid.UUID, collectionHash common.Hash) error {
+	return db.gorm.Table("gas_fundings").Where("guid = ? and status = ?", guid.String(), 0).
+		Updates(map[string]interface{}{"collection_hash": collectionHash.String(), "status": 1}).Error
+}
+
+func (db *gasFundingsDB) MarkGasFundingFailed(guid uuid.UUID) error {
+	return db.gorm.Table("gas_fundings").Where("guid = ? and status = ?", guid.String(), 0).Update("status", 2).Error
+}
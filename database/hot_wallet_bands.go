@@ -0,0 +1,50 @@
+package database
+
+import (
+	"math/big"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// HotWalletBands 按币种配置的热钱包余额区间，对每个热钱包分别生效
+type HotWalletBands struct {
+	GUID          uuid.UUID      `gorm:"primaryKey" json:"guid"`
+	TokenAddress  common.Address `json:"token_address" gorm:"serializer:bytes"`
+	LowWatermark  *big.Int       `gorm:"serializer:u256;column:low_watermark" db:"low_watermark" json:"LowWatermark" form:"low_watermark"`     // 低于该值发起冷转热申请
+	Target        *big.Int       `gorm:"serializer:u256;column:target" db:"target" json:"Target" form:"target"`                                // 调拨后的目标余额
+	HighWatermark *big.Int       `gorm:"serializer:u256;column:high_watermark" db:"high_watermark" json:"HighWatermark" form:"high_watermark"` // 高于该值自动热转冷
+	Enable        bool           `json:"enable"`
+	Timestamp     uint64
+}
+
+func (HotWalletBands) TableName() string {
+	return "hot_wallet_bands"
+}
+
+type HotWalletBandsView interface {
+	QueryEnableHotWalletBands() ([]HotWalletBands, error)
+}
+
+type HotWalletBandsDB interface {
+	HotWalletBandsView
+}
+
+type hotWalletBandsDB struct {
+	gorm *gorm.DB
+}
+
+func NewHotWalletBandsDB(db *gorm.DB) HotWalletBandsDB {
+	return &hotWalletBandsDB{gorm: db}
+}
+
+func (db *hotWalletBandsDB) QueryEnableHotWalletBands() ([]HotWalletBands, error) {
+	var bandList []HotWalletBands
+	err := db.gorm.Table("hot_wallet_bands").Where("enable = ?", true).Find(&bandList).Error
+	if err != nil {
+		return nil, err
+	}
+	return bandList, nil
+}
@@ -0,0 +1,350 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+const (
+	LedgerBucketAvailable = "available" // 钱包可用余额，对应 balances.balance
+	LedgerBucketLocked    = "locked"    // 钱包锁定余额，对应 balances.lock_balance
+	LedgerBucketExternal  = "external"  // 链上外部账户，充值、提现和手续费的对手方
+)
+
+const (
+	LedgerReasonDeposit           = "deposit"
+	LedgerReasonWithdrawLock      = "withdraw_lock"
+	LedgerReasonWithdraw          = "withdraw"
+	LedgerReasonWithdrawRelease   = "withdraw_release"
+	LedgerReasonCollectionLock    = "collection_lock"
+	LedgerReasonCollection        = "collection"
+	LedgerReasonCollectionRelease = "collection_release"
+	LedgerReasonRebalanceLock     = "rebalance_lock"
+	LedgerReasonRebalance         = "rebalance"
+	LedgerReasonRebalanceRelease  = "rebalance_release"
+)
+
+var (
+	ErrJournalUnbalanced      = errors.New("journal debits and credits do not balance")
+	ErrLedgerNegativeBalance  = errors.New("journal would make a wallet balance negative")
+	LedgerExternalAccount     = LedgerAccount{Bucket: LedgerBucketExternal}
+	ledgerMaterialisedBuckets = map[string]bool{LedgerBucketAvailable: true, LedgerBucketLocked: true}
+)
+
+// LedgerEntries 只追加的复式记账分录，同一 journal 的借贷合计必须相等；钱包账户借方增加、贷方减少
+type LedgerEntries struct {
+	GUID         uuid.UUID      `gorm:"primaryKey" json:"guid"`
+	JournalGuid  uuid.UUID      `json:"journal_guid"`
+	Address      common.Address `json:"address" gorm:"serializer:bytes"`
+	AddressType  uint8          `json:"address_type"`
+	Bucket       string         `json:"bucket"`
+	TokenAddress common.Address `json:"token_address" gorm:"serializer:bytes"`
+	Debit        *big.Int       `gorm:"serializer:u256;column:debit" json:"debit"`
+	Credit       *big.Int       `gorm:"serializer:u256;column:credit" json:"credit"`
+	Reason       string         `json:"reason"`
+	SourceHash   common.Hash    `gorm:"column:source_hash;serializer:bytes" json:"source_hash"`
+	Timestamp    uint64
+}
+
+func (LedgerEntries) TableName() string {
+	return "ledger_entries"
+}
+
+// LedgerAccount 记账账户：钱包地址的可用或锁定余额，或链上外部账户
+type LedgerAccount struct {
+	Address     common.Address
+	AddressType uint8
+	Bucket      string
+}
+
+func AvailableAccount(address common.Address, addressType uint8) LedgerAccount {
+	return LedgerAccount{Address: address, AddressType: addressType, Bucket: LedgerBucketAvailable}
+}
+
+func LockedAccount(address common.Address, addressType uint8) LedgerAccount {
+	return LedgerAccount{Address: address, AddressType: addressType, Bucket: LedgerBucketLocked}
+}
+
+// Journal 一次余额变动的全部分录，同一来源交易的同一币种
+type Journal struct {
+	GUID         uuid.UUID
+	Reason       string
+	SourceHash   common.Hash
+	TokenAddress common.Address
+	Entries      []LedgerEntries
+}
+
+func NewJournal(reason string, sourceHash common.Hash, tokenAddress common.Address) *Journal {
+	return &Journal{
+		GUID:         uuid.New(),
+		Reason:       reason,
+		SourceHash:   sourceHash,
+		TokenAddress: tokenAddress,
+	}
+}
+
+// Transfer 从 from 转 amount 到 to：贷记 from、借记 to，金额为零时忽略
+func (j *Journal) Transfer(from, to LedgerAccount, amount *big.Int) *Journal {
+	if amount == nil || amount.Sign() == 0 {
+		return j
+	}
+	if amount.Sign() < 0 {
+		from, to, amount = to, from, new(big.Int).Neg(amount)
+	}
+	j.Entries = append(j.Entries, j.entry(from, big.NewInt(0), amount), j.entry(to, amount, big.NewInt(0)))
+	return j
+}
+
+func (j *Journal) entry(account LedgerAccount, debit, credit *big.Int) LedgerEntries {
+	return LedgerEntries{
+		GUID:         uuid.New(),
+		JournalGuid:  j.GUID,
+		Address:      account.Address,
+		AddressType:  account.AddressType,
+		Bucket:       account.Bucket,
+		TokenAddress: j.TokenAddress,
+		Debit:        new(big.Int).Set(debit),
+		Credit:       new(big.Int).Set(credit),
+		Reason:       j.Reason,
+		SourceHash:   j.SourceHash,
+		Timestamp:    uint64(time.Now().Unix()),
+	}
+}
+
+// Balanced 借方合计等于贷方合计，且每条分录只有一方有金额
+func (j *Journal) Balanced() bool {
+	total := big.NewInt(0)
+	for _, entry := range j.Entries {
+		if entry.Debit.Sign() < 0 || entry.Credit.Sign() < 0 || (entry.Debit.Sign() != 0 && entry.Credit.Sign() != 0) {
+			return false
+		}
+		total.Add(total, entry.Debit)
+		total.Sub(total, entry.Credit)
+	}
+	return total.Sign() == 0
+}
+
+type LedgerEntriesView interface {
+	QueryLedgerBalance(address, tokenAddress common.Address, bucket string) (*big.Int, error)
+	QueryLockEntries(sourceHash common.Hash, lockReason string) ([]LedgerEntries, error)
+	QueryUnbalancedJournals() ([]uuid.UUID, error)
+	QueryBalanceDrift() ([]LedgerDrift, error)
+}
+
+type LedgerEntriesDB interface {
+	LedgerEntriesView
+
+	PostJournal(journal *Journal) error
+	SettleLocks(sourceHash common.Hash, lockReason string, reason string, to *LedgerAccount, received *big.Int) error
+}
+
+type ledgerEntriesDB struct {
+	gorm *gorm.DB
+}
+
+func NewLedgerEntriesDB(db *gorm.DB) LedgerEntriesDB {
+	return &ledgerEntriesDB{gorm: db}
+}
+
+// QueryLedgerBalance 由分录推导账户余额
+func (db *ledgerEntriesDB) QueryLedgerBalance(address, tokenAddress common.Address, bucket string) (*big.Int, error) {
+	var total string
+	err := db.gorm.Table("ledger_entries").
+		Select("COALESCE(SUM(debit) - SUM(credit), 0)::text").
+		Where("address = ? and token_address = ? and bucket = ?", strings.ToLower(address.String()), strings.ToLower(tokenAddress.String()), bucket).
+		Scan(&total).Error
+	if err != nil {
+		return nil, err
+	}
+	balance, ok := new(big.Int).SetString(total, 10)
+	if !ok {
+		return nil, fmt.Errorf("invalid ledger balance %q", total)
+	}
+	return balance, nil
+}
+
+// QueryLockEntries 查询来源交易锁定余额的分录（锁定账户的借方）
+func (db *ledgerEntriesDB) QueryLockEntries(sourceHash common.Hash, lockReason string) ([]LedgerEntries, error) {
+	var entryList []LedgerEntries
+	err := db.gorm.Table("ledger_entries").
+		Where("source_hash = ? and reason = ? and bucket = ? and debit > 0", sourceHash.String(), lockReason, LedgerBucketLocked).
+		Find(&entryList).Error
+	if err != nil {
+		return nil, err
+	}
+	return entryList, nil
+}
+
+// QueryUnbalancedJournals 借贷不平的 journal，正常情况下应为空
+func (db *ledgerEntriesDB) QueryUnbalancedJournals() ([]uuid.UUID, error) {
+	var journalList []uuid.UUID
+	err := db.gorm.Table("ledger_entries").
+		Select("journal_guid").
+		Group("journal_guid").
+		Having("SUM(debit) <> SUM(credit)").
+		Scan(&journalList).Error
+	if err != nil {
+		return nil, err
+	}
+	return journalList, nil
+}
+
+// PostJournal 写入分录并同步物化到 balances，需在事务内调用；借贷不平或余额为负时整笔拒绝
+func (db *ledgerEntriesDB) PostJournal(journal *Journal) error {
+	if len(journal.Entries) == 0 {
+		return nil
+	}
+	if !journal.Balanced() {
+		return fmt.Errorf("%w: %s %s", ErrJournalUnbalanced, journal.Reason, journal.SourceHash)
+	}
+	if err := db.gorm.CreateInBatches(&journal.Entries, len(journal.Entries)).Error; err != nil {
+		return err
+	}
+	// 按账户合并后按固定顺序更新，减少并发事务之间的死锁
+	deltas := make(map[balanceKey]*balanceDelta)
+	var keyList []balanceKey
+	for _, entry := range journal.Entries {
+		if !ledgerMaterialisedBuckets[entry.Bucket] {
+			continue
+		}
+		key := balanceKey{address: strings.ToLower(entry.Address.String()), tokenAddress: strings.ToLower(entry.TokenAddress.String())}
+		delta, ok := deltas[key]
+		if !ok {
+			delta = &balanceDelta{addressType: entry.AddressType, balance: big.NewInt(0), lockBalance: big.NewInt(0)}
+			deltas[key] = delta
+			keyList = append(keyList, key)
+		}
+		amount := new(big.Int).Sub(entry.Debit, entry.Credit)
+		if entry.Bucket == LedgerBucketLocked {
+			delta.lockBalance.Add(delta.lockBalance, amount)
+		} else {
+			delta.balance.Add(delta.balance, amount)
+		}
+	}
+	sort.Slice(keyList, func(i, j int) bool {
+		if keyList[i].address != keyList[j].address {
+			return keyList[i].address < keyList[j].address
+		}
+		return keyList[i].tokenAddress < keyList[j].tokenAddress
+	})
+	for _, key := range keyList {
+		if err := db.materialise(key, deltas[key], journal.Reason); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type balanceKey struct {
+	address      string
+	tokenAddress string
+}
+
+type balanceDelta struct {
+	addressType uint8
+	balance     *big.Int
+	lockBalance *big.Int
+}
+
+// materialise 以单条 SQL 原子累加余额，行锁持有到事务结束；结果为负时不更新并返回 ErrLedgerNegativeBalance
+func (db *ledgerEntriesDB) materialise(key balanceKey, delta *balanceDelta, reason string) error {
+	if delta.balance.Sign() == 0 && delta.lockBalance.Sign() == 0 {
+		return nil
+	}
+	result := db.gorm.Exec(`UPDATE balances SET balance = balance + ?, lock_balance = lock_balance + ?
+	WHERE address = ? AND token_address = ? AND balance + ? >= 0 AND lock_balance + ? >= 0`,
+		delta.balance.String(), delta.lockBalance.String(), key.address, key.tokenAddress, delta.balance.String(), delta.lockBalance.String())
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected > 0 {
+		return nil
+	}
+	// 没有更新到记录：余额不足，或者地址还没有余额记录
+	if delta.balance.Sign() < 0 || delta.lockBalance.Sign() < 0 {
+		return fmt.Errorf("%w: %s %s %s", ErrLedgerNegativeBalance, key.address, key.tokenAddress, reason)
+	}
+	return db.gorm.Exec(`INSERT INTO balances (guid, address, address_type, token_address, balance, lock_balance, timestamp)
+	VALUES (?, ?, ?, ?, ?, ?, ?)
+	ON CONFLICT (address, token_address) DO UPDATE SET balance = balances.balance + EXCLUDED.balance, lock_balance = balances.lock_balance + EXCLUDED.lock_balance`,
+		uuid.New().String(), key.address, delta.addressType, key.tokenAddress, delta.balance.String(), delta.lockBalance.String(), time.Now().Unix()).Error
+}
+
+// SettleLocks 结算来源交易锁定的余额：to 为空时退回各地址可用余额，否则转入 to；
+// received 为 to 实际收到的金额，与锁定合计的差额计入外部账户（手续费或链上未入账的余额），为空时等于锁定合计。
+// 来源交易已结算过时不重复记账
+func (db *ledgerEntriesDB) SettleLocks(sourceHash common.Hash, lockReason string, reason string, to *LedgerAccount, received *big.Int) error {
+	lockList, err := db.QueryLockEntries(sourceHash, lockReason)
+	if err != nil {
+		return err
+	}
+	if len(lockList) == 0 {
+		// 账本上线前锁定的余额计入了期初分录，没有对应的锁定分录
+		log.Warn("no ledger lock entries for source tx", "sourceHash", sourceHash, "lockReason", lockReason)
+		return nil
+	}
+	var settled int64
+	err = db.gorm.Table("ledger_entries").
+		Where("source_hash = ? and bucket = ? and credit > 0 and reason <> ?", sourceHash.String(), LedgerBucketLocked, lockReason).
+		Count(&settled).Error
+	if err != nil {
+		return err
+	}
+	if settled > 0 {
+		return nil
+	}
+
+	journal := NewJournal(reason, sourceHash, lockList[0].TokenAddress)
+	lockTotal := big.NewInt(0)
+	for _, lock := range lockList {
+		locked := LockedAccount(lock.Address, lock.AddressType)
+		if to == nil {
+			journal.Transfer(locked, AvailableAccount(lock.Address, lock.AddressType), lock.Debit)
+			continue
+		}
+		journal.Transfer(locked, *to, lock.Debit)
+		lockTotal.Add(lockTotal, lock.Debit)
+	}
+	if to != nil && received != nil && to.Bucket != LedgerBucketExternal {
+		journal.Transfer(LedgerExternalAccount, *to, new(big.Int).Sub(received, lockTotal))
+	}
+	return db.PostJournal(journal)
+}
+
+// LedgerDrift 物化余额与分录推导余额不一致的账户
+type LedgerDrift struct {
+	Address           string
+	TokenAddress      string
+	Balance           string
+	LockBalance       string
+	LedgerBalance     string
+	LedgerLockBalance string
+}
+
+// QueryBalanceDrift 对比 balances 与分录推导的可用、锁定余额
+func (db *ledgerEntriesDB) QueryBalanceDrift() ([]LedgerDrift, error) {
+	var driftList []LedgerDrift
+	err := db.gorm.Raw(`SELECT b.address, b.token_address, b.balance::text AS balance, b.lock_balance::text AS lock_balance,
+		COALESCE(l.available, 0)::text AS ledger_balance, COALESCE(l.locked, 0)::text AS ledger_lock_balance
+	FROM balances b LEFT JOIN (
+		SELECT address, token_address,
+			SUM(CASE WHEN bucket = ? THEN debit - credit ELSE 0 END) AS available,
+			SUM(CASE WHEN bucket = ? THEN debit - credit ELSE 0 END) AS locked
+		FROM ledger_entries GROUP BY address, token_address
+	) l ON l.address = b.address AND l.token_address = b.token_address
+	WHERE b.balance <> COALESCE(l.available, 0) OR b.lock_balance <> COALESCE(l.locked, 0)`, LedgerBucketAvailable, LedgerBucketLocked).Scan(&driftList).Error
+	if err != nil {
+		return nil, err
+	}
+	return driftList, nil
+}
@@ -0,0 +1,34 @@
+package database
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestJournalTransfer(t *testing.T) {
+	hot := common.HexToAddress("0x0000000000000000000000000000000000000001")
+	journal := NewJournal(LedgerReasonWithdrawLock, common.HexToHash("0x01"), common.Address{})
+	journal.Transfer(AvailableAccount(hot, 1), LockedAccount(hot, 1), big.NewInt(100))
+	journal.Transfer(AvailableAccount(hot, 1), LockedAccount(hot, 1), big.NewInt(0))
+	require.Len(t, journal.Entries, 2)
+	require.Equal(t, LedgerBucketAvailable, journal.Entries[0].Bucket)
+	require.Equal(t, big.NewInt(100), journal.Entries[0].Credit)
+	require.Equal(t, LedgerBucketLocked, journal.Entries[1].Bucket)
+	require.Equal(t, big.NewInt(100), journal.Entries[1].Debit)
+	require.True(t, journal.Balanced())
+
+	// 负数金额反向记账
+	journal.Transfer(AvailableAccount(hot, 1), LedgerExternalAccount, big.NewInt(-30))
+	require.Len(t, journal.Entries, 4)
+	require.Equal(t, LedgerBucketExternal, journal.Entries[2].Bucket)
+	require.Equal(t, big.NewInt(30), journal.Entries[2].Credit)
+	require.Equal(t, big.NewInt(30), journal.Entries[3].Debit)
+	require.True(t, journal.Balanced())
+
+	journal.Entries[3].Debit = big.NewInt(29)
+	require.False(t, journal.Balanced())
+}
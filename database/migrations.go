@@ -0,0 +1,321 @@
+package database
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/ethereum/go-ethereum/log"
+)
+
+var (
+	ErrMigrationChecksum    = errors.New("applied migration has been modified")
+	ErrMissingDownMigration = errors.New("down migration not found")
+)
+
+// migrationLockKey 迁移使用的 postgres advisory lock，多个实例同时迁移时依次执行
+const migrationLockKey int64 = 0x65746877616c6c
+
+// up 文件为 00001_name.sql 或 00001_name.up.sql，down 文件为 00001_name.down.sql
+var migrationFileRegex = regexp.MustCompile(`^(\d+)_(.+?)(\.up|\.down)?\.sql$`)
+
+const createSchemaMigrations = `CREATE TABLE IF NOT EXISTS schema_migrations (
+    version    BIGINT PRIMARY KEY,
+    name       VARCHAR NOT NULL,
+    checksum   VARCHAR NOT NULL,
+    applied_at INTEGER NOT NULL CHECK (applied_at > 0)
+)`
+
+// SchemaMigrations 已执行的迁移版本，checksum 为 up 文件的 sha256
+type SchemaMigrations struct {
+	Version   uint64 `gorm:"primaryKey" json:"version"`
+	Name      string `json:"name"`
+	Checksum  string `json:"checksum"`
+	AppliedAt uint64 `json:"applied_at"`
+}
+
+func (SchemaMigrations) TableName() string {
+	return "schema_migrations"
+}
+
+// Migration 迁移目录中同一版本的 up/down 文件
+type Migration struct {
+	Version  uint64
+	Name     string
+	UpSQL    string
+	DownSQL  string
+	Checksum string
+}
+
+// MigrationStatus 迁移文件与 schema_migrations 比对的结果，Missing 表示已执行但文件不存在
+type MigrationStatus struct {
+	Version          uint64
+	Name             string
+	Applied          bool
+	AppliedAt        uint64
+	ChecksumMismatch bool
+	Missing          bool
+}
+
+// LoadMigrations 读取迁移目录并按版本排序，同一版本只能有一个 up 文件和一个 down 文件
+func LoadMigrations(migrationsFolder string) ([]Migration, error) {
+	entries, err := os.ReadDir(migrationsFolder)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migrations folder %s: %w", migrationsFolder, err)
+	}
+	migrations := make(map[uint64]*Migration)
+	downs := make(map[uint64]string)
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".sql" {
+			continue
+		}
+		match := migrationFileRegex.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("invalid migration file name: %s", entry.Name())
+		}
+		version, err := strconv.ParseUint(match[1], 10, 64)
+		if err != nil || version == 0 {
+			return nil, fmt.Errorf("invalid migration version: %s", entry.Name())
+		}
+		content, err := os.ReadFile(filepath.Join(migrationsFolder, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("error reading SQL file %s: %w", entry.Name(), err)
+		}
+		if match[3] == ".down" {
+			if _, ok := downs[version]; ok {
+				return nil, fmt.Errorf("duplicate down migration version %d: %s", version, entry.Name())
+			}
+			downs[version] = string(content)
+			continue
+		}
+		if _, ok := migrations[version]; ok {
+			return nil, fmt.Errorf("duplicate migration version %d: %s", version, entry.Name())
+		}
+		checksum := sha256.Sum256(content)
+		migrations[version] = &Migration{
+			Version:  version,
+			Name:     match[2],
+			UpSQL:    string(content),
+			Checksum: hex.EncodeToString(checksum[:]),
+		}
+	}
+	for version, downSQL := range downs {
+		migration, ok := migrations[version]
+		if !ok {
+			return nil, fmt.Errorf("down migration version %d has no up migration", version)
+		}
+		migration.DownSQL = downSQL
+	}
+	migrationList := make([]Migration, 0, len(migrations))
+	for _, migration := range migrations {
+		migrationList = append(migrationList, *migration)
+	}
+	sort.Slice(migrationList, func(i, j int) bool {
+		return migrationList[i].Version < migrationList[j].Version
+	})
+	return migrationList, nil
+}
+
+// ExecuteSQLMigration 执行全部未执行的迁移
+func (db *DB) ExecuteSQLMigration(migrationsFolder string) error {
+	return db.MigrateTo(migrationsFolder, math.MaxUint64)
+}
+
+// MigrateTo 执行版本不大于 target 的未执行迁移，并按版本倒序回滚大于 target 的已执行迁移
+func (db *DB) MigrateTo(migrationsFolder string, target uint64) error {
+	migrationList, err := LoadMigrations(migrationsFolder)
+	if err != nil {
+		return err
+	}
+	return db.withMigrationLock(func(conn *gorm.DB) error {
+		applied, err := verifyMigrations(conn, migrationList)
+		if err != nil {
+			return err
+		}
+		migrations := make(map[uint64]Migration)
+		for _, migration := range migrationList {
+			migrations[migration.Version] = migration
+		}
+		// 先确认需要回滚的版本都有 down 文件，避免回滚到一半中止
+		var revertList []Migration
+		for version := range applied {
+			if version <= target {
+				continue
+			}
+			migration, ok := migrations[version]
+			if !ok || migration.DownSQL == "" {
+				return fmt.Errorf("%w: version %d", ErrMissingDownMigration, version)
+			}
+			revertList = append(revertList, migration)
+		}
+		sort.Slice(revertList, func(i, j int) bool { return revertList[i].Version > revertList[j].Version })
+		for _, migration := range revertList {
+			if err := revertMigration(conn, migration); err != nil {
+				return err
+			}
+			delete(applied, migration.Version)
+		}
+		for _, migration := range migrationList {
+			if _, ok := applied[migration.Version]; !ok && migration.Version <= target {
+				if err := applyMigration(conn, migration); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+}
+
+// MigrateDown 按版本倒序回滚最近 steps 个已执行迁移
+func (db *DB) MigrateDown(migrationsFolder string, steps int) error {
+	migrationList, err := LoadMigrations(migrationsFolder)
+	if err != nil {
+		return err
+	}
+	migrations := make(map[uint64]Migration)
+	for _, migration := range migrationList {
+		migrations[migration.Version] = migration
+	}
+	return db.withMigrationLock(func(conn *gorm.DB) error {
+		applied, err := verifyMigrations(conn, migrationList)
+		if err != nil {
+			return err
+		}
+		versionList := make([]uint64, 0, len(applied))
+		for version := range applied {
+			versionList = append(versionList, version)
+		}
+		sort.Slice(versionList, func(i, j int) bool { return versionList[i] > versionList[j] })
+		for i := 0; i < steps && i < len(versionList); i++ {
+			migration, ok := migrations[versionList[i]]
+			if !ok {
+				return fmt.Errorf("%w: version %d", ErrMissingDownMigration, versionList[i])
+			}
+			if err := revertMigration(conn, migration); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// QueryMigrationStatus 返回每个迁移文件的执行状态，以及已执行但文件已不存在的版本
+func (db *DB) QueryMigrationStatus(migrationsFolder string) ([]MigrationStatus, error) {
+	migrationList, err := LoadMigrations(migrationsFolder)
+	if err != nil {
+		return nil, err
+	}
+	var exists bool
+	if err := db.gorm.Raw("SELECT to_regclass('schema_migrations') IS NOT NULL").Scan(&exists).Error; err != nil {
+		return nil, err
+	}
+	applied := make(map[uint64]SchemaMigrations)
+	if exists {
+		if applied, err = queryAppliedMigrations(db.gorm); err != nil {
+			return nil, err
+		}
+	}
+	var statusList []MigrationStatus
+	for _, migration := range migrationList {
+		status := MigrationStatus{Version: migration.Version, Name: migration.Name}
+		if record, ok := applied[migration.Version]; ok {
+			status.Applied = true
+			status.AppliedAt = record.AppliedAt
+			status.ChecksumMismatch = record.Checksum != migration.Checksum
+			delete(applied, migration.Version)
+		}
+		statusList = append(statusList, status)
+	}
+	for _, record := range applied {
+		statusList = append(statusList, MigrationStatus{Version: record.Version, Name: record.Name, Applied: true, AppliedAt: record.AppliedAt, Missing: true})
+	}
+	sort.Slice(statusList, func(i, j int) bool {
+		return statusList[i].Version < statusList[j].Version
+	})
+	return statusList, nil
+}
+
+// withMigrationLock 在同一连接上持有 advisory lock 执行迁移，会话级锁必须在同一连接上加锁和释放
+func (db *DB) withMigrationLock(fn func(conn *gorm.DB) error) error {
+	return db.gorm.Connection(func(conn *gorm.DB) error {
+		log.Info("acquiring migration lock")
+		if err := conn.Exec("SELECT pg_advisory_lock(?)", migrationLockKey).Error; err != nil {
+			return fmt.Errorf("failed to acquire migration lock: %w", err)
+		}
+		defer func() {
+			if err := conn.Exec("SELECT pg_advisory_unlock(?)", migrationLockKey).Error; err != nil {
+				log.Error("release migration lock fail", "err", err)
+			}
+		}()
+		if err := conn.Exec(createSchemaMigrations).Error; err != nil {
+			return fmt.Errorf("failed to create schema_migrations: %w", err)
+		}
+		return fn(conn)
+	})
+}
+
+func queryAppliedMigrations(conn *gorm.DB) (map[uint64]SchemaMigrations, error) {
+	var recordList []SchemaMigrations
+	if err := conn.Table("schema_migrations").Order("version").Find(&recordList).Error; err != nil {
+		return nil, err
+	}
+	applied := make(map[uint64]SchemaMigrations, len(recordList))
+	for _, record := range recordList {
+		applied[record.Version] = record
+	}
+	return applied, nil
+}
+
+// verifyMigrations 已执行迁移的文件被修改时拒绝继续迁移
+func verifyMigrations(conn *gorm.DB, migrationList []Migration) (map[uint64]SchemaMigrations, error) {
+	applied, err := queryAppliedMigrations(conn)
+	if err != nil {
+		return nil, err
+	}
+	for _, migration := range migrationList {
+		record, ok := applied[migration.Version]
+		if ok && record.Checksum != migration.Checksum {
+			return nil, fmt.Errorf("%w: version %d %s", ErrMigrationChecksum, migration.Version, migration.Name)
+		}
+	}
+	return applied, nil
+}
+
+// applyMigration 迁移 SQL 与版本记录在同一事务中提交
+func applyMigration(conn *gorm.DB, migration Migration) error {
+	log.Info("applying migration", "version", migration.Version, "name", migration.Name)
+	return conn.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec(migration.UpSQL).Error; err != nil {
+			return fmt.Errorf("error executing migration %d %s: %w", migration.Version, migration.Name, err)
+		}
+		return tx.Create(&SchemaMigrations{
+			Version:   migration.Version,
+			Name:      migration.Name,
+			Checksum:  migration.Checksum,
+			AppliedAt: uint64(time.Now().Unix()),
+		}).Error
+	})
+}
+
+func revertMigration(conn *gorm.DB, migration Migration) error {
+	if migration.DownSQL == "" {
+		return fmt.Errorf("%w: version %d %s", ErrMissingDownMigration, migration.Version, migration.Name)
+	}
+	log.Info("reverting migration", "version", migration.Version, "name", migration.Name)
+	return conn.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec(migration.DownSQL).Error; err != nil {
+			return fmt.Errorf("error reverting migration %d %s: %w", migration.Version, migration.Name, err)
+		}
+		return tx.Where("version = ?", migration.Version).Delete(&SchemaMigrations{}).Error
+	})
+}
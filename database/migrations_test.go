@@ -0,0 +1,46 @@
+package database
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadMigrations(t *testing.T) {
+	migrationList, err := LoadMigrations("../migrations")
+	require.NoError(t, err)
+	for i, migration := range migrationList {
+		require.Equal(t, uint64(i+1), migration.Version)
+		require.NotEmpty(t, migration.DownSQL, migration.Name)
+		require.Len(t, migration.Checksum, 64)
+	}
+
+	dir := t.TempDir()
+	write := func(name, content string) {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644))
+	}
+	write("00002_add_column.up.sql", "ALTER TABLE a ADD COLUMN b INTEGER;")
+	write("00002_add_column.down.sql", "ALTER TABLE a DROP COLUMN b;")
+	write("00001_create.sql", "CREATE TABLE a (id INTEGER);")
+	write("README.md", "ignored")
+	migrationList, err = LoadMigrations(dir)
+	require.NoError(t, err)
+	require.Len(t, migrationList, 2)
+	require.Equal(t, "create", migrationList[0].Name)
+	require.Empty(t, migrationList[0].DownSQL)
+	require.Equal(t, "add_column", migrationList[1].Name)
+	require.Equal(t, "ALTER TABLE a DROP COLUMN b;", migrationList[1].DownSQL)
+
+	// 同一版本两个 up 文件
+	write("00001_other.sql", "SELECT 1;")
+	_, err = LoadMigrations(dir)
+	require.Error(t, err)
+	require.NoError(t, os.Remove(filepath.Join(dir, "00001_other.sql")))
+
+	// down 文件没有对应的 up 文件
+	write("00003_orphan.down.sql", "SELECT 1;")
+	_, err = LoadMigrations(dir)
+	require.Error(t, err)
+}
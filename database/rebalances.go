@@ -0,0 +1,217 @@
+package database
+
+import (
+	"errors"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+const (
+	RebalanceHotToCold uint8 = 0
+	RebalanceColdToHot uint8 = 1
+)
+
+// Rebalances 冷热钱包之间的调拨，热转冷自动发起，冷转热需要人工审批后签名
+type Rebalances struct {
+	GUID         uuid.UUID      `gorm:"primaryKey" json:"guid"`
+	Direction    uint8          `json:"direction"` // 0:热转冷；1:冷转热
+	HotWallet    common.Address `json:"hot_wallet" gorm:"serializer:bytes"`
+	FromAddress  common.Address `json:"from_address" gorm:"serializer:bytes"`
+	ToAddress    common.Address `json:"to_address" gorm:"serializer:bytes"`
+	TokenAddress common.Address `json:"token_address" gorm:"serializer:bytes"`
+	Amount       *big.Int       `gorm:"serializer:u256;column:amount" db:"amount" json:"Amount" form:"amount"`
+	Status       uint8          `json:"status"` // 0:待审批；1:已审批待签名；2:已签名发送；3:上链成功；4:审批拒绝；5:上链失败；6:已导出待离线签名
+	Hash         common.Hash    `gorm:"column:hash;serializer:bytes" db:"hash" json:"hash"`
+	BlockNumber  *big.Int       `gorm:"serializer:u256;column:block_number" db:"block_number" json:"BlockNumber" form:"block_number"`
+	Fee          *big.Int       `gorm:"serializer:u256;column:fee" db:"fee" json:"Fee" form:"fee"`
+	TxSignHex    string         `json:"tx_sign_hex" gorm:"column:tx_sign_hex"`
+	UnsignedTx   string         `json:"unsigned_tx" gorm:"column:unsigned_tx"` // 导出给离线签名的交易，导入时按此校验签名交易
+	Operator     string         `json:"operator"`
+	Reason       string         `json:"reason"`
+	Timestamp    uint64
+	Updated      uint64 `json:"updated"`
+}
+
+func (Rebalances) TableName() string {
+	return "rebalances"
+}
+
+type RebalancesView interface {
+	QueryRebalanceByGuid(guid uuid.UUID) (*Rebalances, error)
+	QueryRebalanceByHash(hash common.Hash) (*Rebalances, error)
+	QueryActiveRebalance(hotWallet common.Address, tokenAddress common.Address) (*Rebalances, error)
+	QueryRebalancesByStatus(status uint8) ([]Rebalances, error)
+	QueryUnconfirmedRebalances() ([]Rebalances, error)
+	QueryRebalanceList(page int, pageSize int, order string) ([]Rebalances, int64)
+}
+
+type RebalancesDB interface {
+	RebalancesView
+
+	StoreRebalance(rebalance Rebalances) error
+	ReviewRebalance(guid uuid.UUID, approve bool, operator string, reason string) (bool, error)
+	MarkRebalanceExported(guid uuid.UUID, unsignedTx string) error
+	MarkRebalanceSent(guid uuid.UUID, hash common.Hash, txSignHex string) error
+	UpdateRebalanceStatus(rebalance Rebalances) error
+	ResetReplacedRebalance(rebalance Rebalances) error
+}
+
+type rebalancesDB struct {
+	gorm *gorm.DB
+}
+
+func NewRebalancesDB(db *gorm.DB) RebalancesDB {
+	return &rebalancesDB{gorm: db}
+}
+
+func (db *rebalancesDB) QueryRebalanceByGuid(guid uuid.UUID) (*Rebalances, error) {
+	var rebalance Rebalances
+	err := db.gorm.Table("rebalances").Where("guid = ?", guid.String()).Take(&rebalance).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &rebalance, nil
+}
+
+func (db *rebalancesDB) QueryRebalanceByHash(hash common.Hash) (*Rebalances, error) {
+	var rebalance Rebalances
+	err := db.gorm.Table("rebalances").Where("hash = ?", hash.String()).Take(&rebalance).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &rebalance, nil
+}
+
+// QueryActiveRebalance 查询热钱包某币种尚未结束的调拨，同一时间只允许一笔
+func (db *rebalancesDB) QueryActiveRebalance(hotWallet common.Address, tokenAddress common.Address) (*Rebalances, error) {
+	var rebalance Rebalances
+	err := db.gorm.Table("rebalances").
+		Where("hot_wallet = ? and token_address = ? and status in ?", strings.ToLower(hotWallet.String()), strings.ToLower(tokenAddress.String()), []uint8{0, 1, 2, 6}).
+		Take(&rebalance).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &rebalance, nil
+}
+
+func (db *rebalancesDB) QueryRebalancesByStatus(status uint8) ([]Rebalances, error) {
+	var rebalanceList []Rebalances
+	err := db.gorm.Table("rebalances").Where("status = ?", status).Order("timestamp asc").Find(&rebalanceList).Error
+	if err != nil {
+		return nil, err
+	}
+	return rebalanceList, nil
+}
+
+// QueryUnconfirmedRebalances 查询已签名并持久化、但还未确认上链的调拨
+func (db *rebalancesDB) QueryUnconfirmedRebalances() ([]Rebalances, error) {
+	var rebalanceList []Rebalances
+	err := db.gorm.Table("rebalances").Where("status = ? and tx_sign_hex <> ?", 2, "").Order("timestamp asc").Find(&rebalanceList).Error
+	if err != nil {
+		return nil, err
+	}
+	return rebalanceList, nil
+}
+
+func (db *rebalancesDB) QueryRebalanceList(page int, pageSize int, order string) ([]Rebalances, int64) {
+	var totalRecord int64
+	var rebalanceList []Rebalances
+	if err := db.gorm.Table("rebalances").Count(&totalRecord).Error; err != nil {
+		log.Error("get rebalance list count fail", "err", err)
+	}
+	queryStateRoot := db.gorm.Table("rebalances").Offset((page - 1) * pageSize).Limit(pageSize)
+	if strings.ToLower(order) == "asc" {
+		queryStateRoot.Order("timestamp asc")
+	} else {
+		queryStateRoot.Order("timestamp desc")
+	}
+	if err := queryStateRoot.Find(&rebalanceList).Error; err != nil {
+		log.Error("get rebalance list fail", "err", err)
+	}
+	return rebalanceList, totalRecord
+}
+
+func (db *rebalancesDB) StoreRebalance(rebalance Rebalances) error {
+	return db.gorm.Create(&rebalance).Error
+}
+
+// ReviewRebalance 审批待审批的冷转热申请，返回 false 表示申请不在待审批状态
+func (db *rebalancesDB) ReviewRebalance(guid uuid.UUID, approve bool, operator string, reason string) (bool, error) {
+	status := uint8(4)
+	if approve {
+		status = 1
+	}
+	result := db.gorm.Table("rebalances").Where("guid = ? and status = ?", guid.String(), 0).
+		Updates(map[string]interface{}{"status": status, "operator": operator, "reason": reason, "updated": time.Now().Unix()})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// MarkRebalanceExported 已审批的申请导出离线签名后保存导出的交易
+func (db *rebalancesDB) MarkRebalanceExported(guid uuid.UUID, unsignedTx string) error {
+	result := db.gorm.Table("rebalances").Where("guid = ? and status = ?", guid.String(), 1).
+		Updates(map[string]interface{}{"status": 6, "unsigned_tx": unsignedTx, "updated": time.Now().Unix()})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (db *rebalancesDB) MarkRebalanceSent(guid uuid.UUID, hash common.Hash, txSignHex string) error {
+	result := db.gorm.Table("rebalances").Where("guid = ? and status in ?", guid.String(), []uint8{1, 6}).
+		Updates(map[string]interface{}{"status": 2, "hash": hash.String(), "tx_sign_hex": txSignHex, "updated": time.Now().Unix()})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// UpdateRebalanceStatus 根据交易回执更新调拨上链结果
+func (db *rebalancesDB) UpdateRebalanceStatus(rebalance Rebalances) error {
+	return db.gorm.Table("rebalances").Where("guid = ? and status = ?", rebalance.GUID.String(), 2).
+		Updates(map[string]interface{}{
+			"status":       rebalance.Status,
+			"block_number": rebalance.BlockNumber.String(),
+			"fee":          rebalance.Fee.String(),
+			"updated":      time.Now().Unix(),
+		}).Error
+}
+
+// ResetReplacedRebalance nonce 被占用的调拨交易作废：热转冷标记为上链失败，由下一轮按余额重新发起；冷转热保留审批结果，回到已审批待签名
+func (db *rebalancesDB) ResetReplacedRebalance(rebalance Rebalances) error {
+	updates := map[string]interface{}{"status": 5, "updated": time.Now().Unix()}
+	if rebalance.Direction == RebalanceColdToHot {
+		updates = map[string]interface{}{
+			"status":      1,
+			"hash":        common.Hash{}.String(),
+			"tx_sign_hex": "",
+			"unsigned_tx": "",
+			"updated":     time.Now().Unix(),
+		}
+	}
+	return db.gorm.Table("rebalances").Where("guid = ? and status = ? and hash = ?", rebalance.GUID.String(), 2, rebalance.Hash.String()).Updates(updates).Error
+}
@@ -0,0 +1,104 @@
+package database
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Reconciliations 一次链上余额对账的汇总
+type Reconciliations struct {
+	GUID        uuid.UUID `gorm:"primaryKey" json:"guid"`
+	BlockNumber *big.Int  `gorm:"serializer:u256;column:block_number" json:"block_number"` // 对账使用的区块高度
+	Checked     uint64    `json:"checked"`
+	Mismatched  uint64    `json:"mismatched"`
+	Alerted     uint64    `json:"alerted"`
+	Timestamp   uint64
+}
+
+func (Reconciliations) TableName() string {
+	return "reconciliations"
+}
+
+// ReconciliationDiscrepancies 链上余额与 balance + lock_balance 不一致的地址和币种
+type ReconciliationDiscrepancies struct {
+	GUID               uuid.UUID      `gorm:"primaryKey" json:"guid"`
+	ReconciliationGuid uuid.UUID      `json:"reconciliation_guid"`
+	Address            common.Address `json:"address" gorm:"serializer:bytes"`
+	AddressType        uint8          `json:"address_type"`
+	TokenAddress       common.Address `json:"token_address" gorm:"serializer:bytes"`
+	BlockNumber        *big.Int       `gorm:"serializer:u256;column:block_number" json:"block_number"`
+	ChainBalance       *big.Int       `gorm:"serializer:u256;column:chain_balance" json:"chain_balance"`
+	Balance            *big.Int       `gorm:"serializer:u256;column:balance" json:"balance"`
+	LockBalance        *big.Int       `gorm:"serializer:u256;column:lock_balance" json:"lock_balance"` // 已发送未确认交易锁定的金额
+	Tolerance          *big.Int       `gorm:"serializer:u256;column:tolerance" json:"tolerance"`
+	Alert              bool           `json:"alert"` // 差额超过容差
+	Timestamp          uint64
+}
+
+func (ReconciliationDiscrepancies) TableName() string {
+	return "reconciliation_discrepancies"
+}
+
+// Difference 链上余额减去数据库余额及在途金额
+func (d ReconciliationDiscrepancies) Difference() *big.Int {
+	expected := new(big.Int).Add(d.Balance, d.LockBalance)
+	return expected.Sub(d.ChainBalance, expected)
+}
+
+type ReconciliationsView interface {
+	LatestReconciliation() (*Reconciliations, error)
+	QueryDiscrepanciesByReconciliation(guid uuid.UUID) ([]ReconciliationDiscrepancies, error)
+}
+
+type ReconciliationsDB interface {
+	ReconciliationsView
+
+	StoreReconciliation(reconciliation Reconciliations, discrepancyList []ReconciliationDiscrepancies) error
+}
+
+type reconciliationsDB struct {
+	gorm *gorm.DB
+}
+
+func NewReconciliationsDB(db *gorm.DB) ReconciliationsDB {
+	return &reconciliationsDB{gorm: db}
+}
+
+func (db *reconciliationsDB) LatestReconciliation() (*Reconciliations, error) {
+	var reconciliation Reconciliations
+	err := db.gorm.Table("reconciliations").Order("timestamp desc").Take(&reconciliation).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &reconciliation, nil
+}
+
+func (db *reconciliationsDB) QueryDiscrepanciesByReconciliation(guid uuid.UUID) ([]ReconciliationDiscrepancies, error) {
+	var discrepancyList []ReconciliationDiscrepancies
+	err := db.gorm.Table("reconciliation_discrepancies").Where("reconciliation_guid = ?", guid).Find(&discrepancyList).Error
+	if err != nil {
+		return nil, err
+	}
+	return discrepancyList, nil
+}
+
+// StoreReconciliation 同一事务写入对账汇总和差异明细
+func (db *reconciliationsDB) StoreReconciliation(reconciliation Reconciliations, discrepancyList []ReconciliationDiscrepancies) error {
+	return db.gorm.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&reconciliation).Error; err != nil {
+			return err
+		}
+		if len(discrepancyList) == 0 {
+			return nil
+		}
+		return tx.CreateInBatches(&discrepancyList, len(discrepancyList)).Error
+	})
+}
@@ -0,0 +1,165 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/the-web3/eth-wallet/config"
+)
+
+// replicaLagQuery 从库已回放到主库当前 WAL 位置时延迟为 0，否则取最后回放事务距今的时间，未回放过任何事务时为 NULL
+const replicaLagQuery = `SELECT CASE
+    WHEN NOT pg_is_in_recovery() THEN 0
+    WHEN pg_last_wal_replay_lsn() >= $1::pg_lsn THEN 0
+    ELSE EXTRACT(EPOCH FROM now() - pg_last_xact_replay_timestamp())
+END::float8`
+
+// ReplicaPool gorm 连接池：查询在从库延迟正常时走从库，写入、预编译和事务始终走主库
+type ReplicaPool struct {
+	primary *sql.DB
+	replica *sql.DB
+	conf    config.SlaveDbLagConfig
+	healthy atomic.Bool
+
+	stop     chan struct{}
+	stopOnce sync.Once
+	wg       sync.WaitGroup
+}
+
+// NewReplicaDB 返回读库，其中的 View 查询按从库延迟在从库和 primary 之间切换，关闭时不关闭 primary
+func NewReplicaDB(ctx context.Context, primary *DB, dbConfig config.DBConfig, lagConfig config.SlaveDbLagConfig) (*DB, error) {
+	if lagConfig.CheckInterval <= 0 {
+		return nil, fmt.Errorf("slave db lag check interval must be positive")
+	}
+	primarySQL, err := primary.gorm.DB()
+	if err != nil {
+		return nil, err
+	}
+	replicaDB, err := NewDB(ctx, dbConfig)
+	if err != nil {
+		return nil, err
+	}
+	replicaSQL, err := replicaDB.gorm.DB()
+	if err != nil {
+		return nil, err
+	}
+	pool := &ReplicaPool{
+		primary: primarySQL,
+		replica: replicaSQL,
+		conf:    lagConfig,
+		stop:    make(chan struct{}),
+	}
+	gorm, err := gorm.Open(postgres.New(postgres.Config{Conn: pool}), newGormConfig())
+	if err != nil {
+		_ = replicaSQL.Close()
+		return nil, fmt.Errorf("failed to open replica pool: %w", err)
+	}
+	pool.refresh()
+	log.Info("slave db enabled", "readFromSlave", pool.Healthy(), "maxLag", lagConfig.MaxLag)
+	pool.wg.Add(1)
+	go pool.monitor()
+
+	db := newDB(gorm)
+	db.replica = pool
+	return db, nil
+}
+
+func (p *ReplicaPool) reader() *sql.DB {
+	if p.healthy.Load() {
+		return p.replica
+	}
+	return p.primary
+}
+
+func (p *ReplicaPool) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
+	return p.primary.PrepareContext(ctx, query)
+}
+
+func (p *ReplicaPool) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	return p.primary.ExecContext(ctx, query, args...)
+}
+
+func (p *ReplicaPool) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	return p.reader().QueryContext(ctx, query, args...)
+}
+
+func (p *ReplicaPool) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	return p.reader().QueryRowContext(ctx, query, args...)
+}
+
+func (p *ReplicaPool) BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error) {
+	return p.primary.BeginTx(ctx, opts)
+}
+
+// GetDBConn gorm 取底层连接时返回从库
+func (p *ReplicaPool) GetDBConn() (*sql.DB, error) {
+	return p.replica, nil
+}
+
+// Healthy 从库延迟在阈值内，查询正在走从库
+func (p *ReplicaPool) Healthy() bool {
+	return p.healthy.Load()
+}
+
+// Lag 查询从库相对主库的复制延迟
+func (p *ReplicaPool) Lag(ctx context.Context) (time.Duration, error) {
+	var primaryLsn string
+	if err := p.primary.QueryRowContext(ctx, "SELECT pg_current_wal_lsn()::text").Scan(&primaryLsn); err != nil {
+		return 0, fmt.Errorf("failed to query primary wal lsn: %w", err)
+	}
+	var lagSeconds sql.NullFloat64
+	if err := p.replica.QueryRowContext(ctx, replicaLagQuery, primaryLsn).Scan(&lagSeconds); err != nil {
+		return 0, fmt.Errorf("failed to query replica lag: %w", err)
+	}
+	if !lagSeconds.Valid {
+		return 0, fmt.Errorf("replica has not replayed any transaction")
+	}
+	return time.Duration(lagSeconds.Float64 * float64(time.Second)), nil
+}
+
+func (p *ReplicaPool) refresh() {
+	ctx, cancel := context.WithTimeout(context.Background(), p.conf.CheckInterval)
+	defer cancel()
+	lag, err := p.Lag(ctx)
+	healthy := err == nil && lag <= p.conf.MaxLag
+	if p.healthy.Swap(healthy) == healthy {
+		return
+	}
+	if healthy {
+		log.Info("slave db caught up, reads routed to slave", "lag", lag)
+	} else if err != nil {
+		log.Warn("slave db lag check failed, reads fall back to master", "err", err)
+	} else {
+		log.Warn("slave db lag above threshold, reads fall back to master", "lag", lag, "maxLag", p.conf.MaxLag)
+	}
+}
+
+func (p *ReplicaPool) monitor() {
+	defer p.wg.Done()
+	ticker := time.NewTicker(p.conf.CheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-p.stop:
+			return
+		case <-ticker.C:
+			p.refresh()
+		}
+	}
+}
+
+// Close 停止延迟检测并关闭从库连接，primary 由主库的 DB 关闭
+func (p *ReplicaPool) Close() error {
+	p.stopOnce.Do(func() { close(p.stop) })
+	p.wg.Wait()
+	return p.replica.Close()
+}
@@ -0,0 +1,38 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/the-web3/eth-wallet/config"
+)
+
+func TestReplicaPoolFallback(t *testing.T) {
+	// 端口 1 无法连接，延迟检测失败
+	primary, err := sql.Open("pgx", "host=127.0.0.1 port=1 dbname=primary sslmode=disable connect_timeout=1")
+	require.NoError(t, err)
+	replica, err := sql.Open("pgx", "host=127.0.0.1 port=1 dbname=replica sslmode=disable connect_timeout=1")
+	require.NoError(t, err)
+	pool := &ReplicaPool{
+		primary: primary,
+		replica: replica,
+		conf:    config.SlaveDbLagConfig{MaxLag: time.Second, CheckInterval: time.Second},
+		stop:    make(chan struct{}),
+	}
+	defer pool.Close()
+	defer primary.Close()
+
+	pool.healthy.Store(true)
+	require.Same(t, replica, pool.reader())
+
+	// 检测失败时读请求回退主库
+	_, err = pool.Lag(context.Background())
+	require.Error(t, err)
+	pool.refresh()
+	require.False(t, pool.Healthy())
+	require.Same(t, primary, pool.reader())
+}
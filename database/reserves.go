@@ -0,0 +1,144 @@
+package database
+
+import (
+	"errors"
+	"math/big"
+	"strings"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// ReserveReports 某一币种的储备证明：用户负债默克尔求和树的根和总额，以及同一区块的链上持仓
+type ReserveReports struct {
+	GUID             uuid.UUID      `gorm:"primaryKey" json:"guid"`
+	TokenAddress     common.Address `json:"token_address" gorm:"serializer:bytes"`
+	BlockNumber      *big.Int       `gorm:"serializer:u256;column:block_number" json:"block_number"`
+	Root             common.Hash    `json:"root" gorm:"serializer:bytes"`
+	TotalLiabilities *big.Int       `gorm:"serializer:u256;column:total_liabilities" json:"total_liabilities"`
+	OnchainHoldings  *big.Int       `gorm:"serializer:u256;column:onchain_holdings" json:"onchain_holdings"` // 热钱包、冷钱包和用户地址的链上余额合计
+	UserCount        uint64         `json:"user_count"`
+	Timestamp        uint64
+}
+
+func (ReserveReports) TableName() string {
+	return "reserve_reports"
+}
+
+// ReserveProofs 用户在某次储备证明中的叶子和证明路径，proof 为 JSON 编码的兄弟节点列表
+type ReserveProofs struct {
+	GUID         uuid.UUID      `gorm:"primaryKey" json:"guid"`
+	ReportGuid   uuid.UUID      `json:"report_guid"`
+	UserUid      string         `json:"user_uid"`
+	TokenAddress common.Address `json:"token_address" gorm:"serializer:bytes"`
+	LeafIndex    uint64         `json:"leaf_index"`
+	Salt         common.Hash    `json:"salt" gorm:"serializer:bytes"`
+	Balance      *big.Int       `gorm:"serializer:u256;column:balance" json:"balance"`
+	Proof        string         `json:"proof"`
+	Timestamp    uint64
+}
+
+func (ReserveProofs) TableName() string {
+	return "reserve_proofs"
+}
+
+// UserLiability 用户在某一币种上的负债：已入账充值减去已上链提现
+type UserLiability struct {
+	UserUid      string
+	TokenAddress string
+	Amount       string
+}
+
+type ReservesView interface {
+	QueryUserLiabilities(blockNumber *big.Int) ([]UserLiability, error)
+	QueryLatestReserveReports() ([]ReserveReports, error)
+	QueryReserveReportByGuid(guid uuid.UUID) (*ReserveReports, error)
+	QueryLatestReserveProof(userUid string, tokenAddress common.Address) (*ReserveProofs, error)
+}
+
+type ReservesDB interface {
+	ReservesView
+
+	StoreReserveReport(report ReserveReports, proofList []ReserveProofs) error
+}
+
+type reservesDB struct {
+	gorm *gorm.DB
+}
+
+func NewReservesDB(db *gorm.DB) ReservesDB {
+	return &reservesDB{gorm: db}
+}
+
+// QueryUserLiabilities 统计截至 blockNumber 每个用户每个币种的充值减提现，提现只计入已上链成功的部分
+func (db *reservesDB) QueryUserLiabilities(blockNumber *big.Int) ([]UserLiability, error) {
+	var liabilityList []UserLiability
+	err := db.gorm.Raw(`WITH credited AS (
+		SELECT a.user_uid, d.token_address, SUM(d.amount) AS amount
+		FROM deposits d JOIN addresses a ON a.address = d.to_address
+		WHERE a.address_type = 0 AND a.user_uid <> '' AND d.block_number <= ?
+		GROUP BY a.user_uid, d.token_address
+	), debited AS (
+		SELECT user_uid, token_address, SUM(amount) AS amount
+		FROM withdraws
+		WHERE status IN (2, 3, 4, 5) AND user_uid <> '' AND block_number <= ?
+		GROUP BY user_uid, token_address
+	)
+	SELECT COALESCE(c.user_uid, w.user_uid) AS user_uid, COALESCE(c.token_address, w.token_address) AS token_address,
+		(COALESCE(c.amount, 0) - COALESCE(w.amount, 0))::text AS amount
+	FROM credited c FULL OUTER JOIN debited w ON c.user_uid = w.user_uid AND c.token_address = w.token_address
+	ORDER BY token_address, user_uid`, blockNumber.Uint64(), blockNumber.Uint64()).Scan(&liabilityList).Error
+	if err != nil {
+		return nil, err
+	}
+	return liabilityList, nil
+}
+
+// QueryLatestReserveReports 每个币种最近一次储备证明
+func (db *reservesDB) QueryLatestReserveReports() ([]ReserveReports, error) {
+	var reportList []ReserveReports
+	err := db.gorm.Table("reserve_reports").Select("DISTINCT ON (token_address) *").Order("token_address, timestamp desc").Find(&reportList).Error
+	if err != nil {
+		return nil, err
+	}
+	return reportList, nil
+}
+
+func (db *reservesDB) QueryReserveReportByGuid(guid uuid.UUID) (*ReserveReports, error) {
+	var report ReserveReports
+	err := db.gorm.Table("reserve_reports").Where("guid = ?", guid).Take(&report).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &report, nil
+}
+
+func (db *reservesDB) QueryLatestReserveProof(userUid string, tokenAddress common.Address) (*ReserveProofs, error) {
+	var proof ReserveProofs
+	err := db.gorm.Table("reserve_proofs").Where("user_uid = ? and token_address = ?", userUid, strings.ToLower(tokenAddress.String())).Order("timestamp desc").Take(&proof).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &proof, nil
+}
+
+// StoreReserveReport 同一事务写入储备证明和全部用户证明
+func (db *reservesDB) StoreReserveReport(report ReserveReports, proofList []ReserveProofs) error {
+	return db.gorm.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&report).Error; err != nil {
+			return err
+		}
+		if len(proofList) == 0 {
+			return nil
+		}
+		return tx.CreateInBatches(&proofList, 1000).Error
+	})
+}
@@ -0,0 +1,111 @@
+package database
+
+import (
+	"errors"
+	"math/big"
+	"strings"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+type RiskRules struct {
+	GUID          uuid.UUID `gorm:"primaryKey" json:"guid"`
+	RuleName      string    `json:"rule_name"`
+	RuleType      uint8     `json:"rule_type"`     // 1:地址黑名单；2:用户提现频率；3:币种提现频率；4:单笔金额阈值；5:新地址冷却期
+	TokenAddress  string    `json:"token_address"` // 为空表示对所有币种生效
+	Threshold     *big.Int  `gorm:"serializer:u256;column:threshold" db:"threshold" json:"Threshold" form:"threshold"`
+	MaxCount      uint64    `json:"max_count"`
+	WindowSeconds uint64    `json:"window_seconds"`
+	Action        uint8     `json:"action"` // 1:转人工审核；2:直接拒绝
+	Enable        bool      `json:"enable"`
+	Timestamp     uint64
+}
+
+func (RiskRules) TableName() string {
+	return "risk_rules"
+}
+
+type RiskBlocklist struct {
+	GUID      uuid.UUID      `gorm:"primaryKey" json:"guid"`
+	Address   common.Address `json:"address" gorm:"serializer:bytes"`
+	Reason    string         `json:"reason"`
+	Timestamp uint64
+}
+
+func (RiskBlocklist) TableName() string {
+	return "risk_blocklist"
+}
+
+type RiskDecisions struct {
+	GUID           uuid.UUID `gorm:"primaryKey" json:"guid"`
+	SubjectType    string    `json:"subject_type"` // withdraw, review
+	Subject        string    `json:"subject"`
+	WithdrawGuid   string    `json:"withdraw_guid"`
+	Decision       uint8     `json:"decision"` // 0:通过；1:人工审核；2:拒绝
+	TriggeredRules string    `json:"triggered_rules"`
+	Timestamp      uint64
+}
+
+func (RiskDecisions) TableName() string {
+	return "risk_decisions"
+}
+
+type RiskView interface {
+	QueryEnableRiskRules() ([]RiskRules, error)
+	QueryBlocklistByAddress(address common.Address) (*RiskBlocklist, error)
+	LatestDecisionByWithdraw(withdrawGuid string) (*RiskDecisions, error)
+}
+
+type RiskDB interface {
+	RiskView
+
+	StoreRiskDecision(decision RiskDecisions) error
+}
+
+type riskDB struct {
+	gorm *gorm.DB
+}
+
+func NewRiskDB(db *gorm.DB) RiskDB {
+	return &riskDB{gorm: db}
+}
+
+func (db *riskDB) QueryEnableRiskRules() ([]RiskRules, error) {
+	var ruleList []RiskRules
+	err := db.gorm.Table("risk_rules").Where("enable = ?", true).Find(&ruleList).Error
+	if err != nil {
+		return nil, err
+	}
+	return ruleList, nil
+}
+
+func (db *riskDB) QueryBlocklistByAddress(address common.Address) (*RiskBlocklist, error) {
+	var blockEntry RiskBlocklist
+	err := db.gorm.Table("risk_blocklist").Where("address = ?", strings.ToLower(address.String())).Take(&blockEntry).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &blockEntry, nil
+}
+
+func (db *riskDB) LatestDecisionByWithdraw(withdrawGuid string) (*RiskDecisions, error) {
+	var decision RiskDecisions
+	err := db.gorm.Table("risk_decisions").Where("withdraw_guid = ?", withdrawGuid).Order("timestamp desc").Take(&decision).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &decision, nil
+}
+
+func (db *riskDB) StoreRiskDecision(decision RiskDecisions) error {
+	return db.gorm.Create(&decision).Error
+}
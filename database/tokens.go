@@ -14,7 +14,7 @@ import (
 type Tokens struct {
 	GUID          uuid.UUID      `gorm:"primaryKey" json:"guid"`
 	TokenAddress  common.Address `json:"token_address" gorm:"serializer:bytes"`
-	Uint          uint8          `json:"uint"`
+	Uint          uint8          `json:"uint" gorm:"column:unit"`
 	TokenName     string         `json:"tokens_name"`
 	CollectAmount *big.Int       `gorm:"serializer:u256;column:collect_amount" db:"collect_amount" json:"CollectAmount" form:"collect_amount"`
 	Timestamp     uint64
@@ -20,14 +20,16 @@ type Transactions struct {
 	TokenAddress     common.Address `json:"token_address" gorm:"serializer:bytes"`
 	Fee              *big.Int       `gorm:"serializer:u256;column:fee" db:"fee" json:"Fee" form:"fee"`
 	Amount           *big.Int       `gorm:"serializer:u256;column:amount" db:"amount" json:"Amount" form:"amount"`
-	Status           uint8          `json:"status"`  // 0:交易确认中,1:钱包交易已到账；2:交易已通知业务层；3:交易完成
-	TxType           uint8          `json:"tx_type"` // 0:充值；1:提现；2:归集；3:热转冷；4:冷转热
+	Status           uint8          `json:"status"`  // 0:交易确认中,1:钱包交易已到账；2:交易已通知业务层；3:交易完成；4:nonce 被占用，交易不会上链；5:交易上链失败
+	TxType           uint8          `json:"tx_type"` // 0:充值；1:提现；2:归集；3:热转冷；4:冷转热；5:gas 补充
 	TransactionIndex *big.Int       `gorm:"serializer:u256;column:transaction_index" db:"transaction_index" json:"TransactionIndex" form:"transaction_index"`
+	TxSignHex        string         `json:"tx_sign_hex" gorm:"column:tx_sign_hex"`
 	Timestamp        uint64
 }
 
 type TransactionsView interface {
 	QueryTransactionByHash(hash common.Hash) (*Transactions, error)
+	QueryUnconfirmedCollections() ([]Transactions, error)
 }
 
 type TransactionsDB interface {
@@ -36,6 +38,7 @@ type TransactionsDB interface {
 	StoreTransactions([]Transactions, uint64) error
 	UpdateTransactionsStatus(blockNumber *big.Int) error
 	UpdateTransactionStatus(txList []Transactions) error
+	MarkCollectionReplaced(hash common.Hash) (bool, error)
 }
 
 type transactionsDB struct {
@@ -54,6 +57,22 @@ func (db *transactionsDB) QueryTransactionByHash(hash common.Hash) (*Transaction
 	return &transactionEntry, nil
 }
 
+// QueryUnconfirmedCollections 已签名持久化但尚未上链的归集交易
+func (db *transactionsDB) QueryUnconfirmedCollections() ([]Transactions, error) {
+	var collectionList []Transactions
+	err := db.gorm.Table("transactions").Where("tx_type = ? and status = ? and tx_sign_hex <> ?", 2, 0, "").Find(&collectionList).Error
+	if err != nil {
+		return nil, err
+	}
+	return collectionList, nil
+}
+
+// MarkCollectionReplaced nonce 被占用的归集交易标记为不会上链，扫块已处理该交易时返回 false
+func (db *transactionsDB) MarkCollectionReplaced(hash common.Hash) (bool, error) {
+	result := db.gorm.Table("transactions").Where("hash = ? and tx_type = ? and status = ?", hash.String(), 2, 0).Update("status", 4)
+	return result.RowsAffected > 0, result.Error
+}
+
 func (db *transactionsDB) UpdateTransactionsStatus(blockNumber *big.Int) error {
 	result := db.gorm.Model(&Transactions{}).Where("status = ? and block_number = ?", 0, blockNumber).Updates(map[string]interface{}{"status": gorm.Expr("GREATEST(1)")})
 	if result.Error != nil {
@@ -0,0 +1,89 @@
+package database
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+type WithdrawBatches struct {
+	GUID          uuid.UUID      `gorm:"primaryKey" json:"guid"`
+	BlockHash     common.Hash    `gorm:"column:block_hash;serializer:bytes"  db:"block_hash" json:"block_hash"`
+	BlockNumber   *big.Int       `gorm:"serializer:u256;column:block_number" db:"block_number" json:"BlockNumber" form:"block_number"`
+	Hash          common.Hash    `gorm:"column:hash;serializer:bytes"  db:"hash" json:"hash"`
+	FromAddress   common.Address `json:"from_address" gorm:"serializer:bytes;column:from_address"`
+	TokenAddress  common.Address `json:"token_address" gorm:"serializer:bytes;column:token_address"`
+	Fee           *big.Int       `gorm:"serializer:u256;column:fee" db:"fee" json:"Fee" form:"fee"`
+	TotalAmount   *big.Int       `gorm:"serializer:u256;column:total_amount" db:"total_amount" json:"TotalAmount" form:"total_amount"`
+	WithdrawCount uint64         `json:"withdraw_count"`
+	Status        uint8          `json:"status"` // 0:批量交易已签名发送；1:批量交易上链成功；2:批量交易上链失败；3:批量交易 nonce 被其它交易占用已作废
+	TxSignHex     string         `json:"tx_sign_hex" gorm:"column:tx_sign_hex"`
+	Timestamp     uint64
+}
+
+func (WithdrawBatches) TableName() string {
+	return "withdraw_batches"
+}
+
+type WithdrawBatchesView interface {
+	QueryWithdrawBatchByHash(hash common.Hash) (*WithdrawBatches, error)
+	QueryUnconfirmedWithdrawBatches() ([]WithdrawBatches, error)
+}
+
+type WithdrawBatchesDB interface {
+	WithdrawBatchesView
+
+	StoreWithdrawBatch(batch WithdrawBatches) error
+	UpdateWithdrawBatchStatus(batch WithdrawBatches) error
+	MarkWithdrawBatchReplaced(guid uuid.UUID) error
+}
+
+type withdrawBatchesDB struct {
+	gorm *gorm.DB
+}
+
+func NewWithdrawBatchesDB(db *gorm.DB) WithdrawBatchesDB {
+	return &withdrawBatchesDB{gorm: db}
+}
+
+func (db *withdrawBatchesDB) QueryWithdrawBatchByHash(hash common.Hash) (*WithdrawBatches, error) {
+	var batchEntity WithdrawBatches
+	result := db.gorm.Table("withdraw_batches").Where("hash = ?", hash.String()).Take(&batchEntity)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, result.Error
+	}
+	return &batchEntity, nil
+}
+
+func (db *withdrawBatchesDB) QueryUnconfirmedWithdrawBatches() ([]WithdrawBatches, error) {
+	var batchList []WithdrawBatches
+	err := db.gorm.Table("withdraw_batches").Where("status = ?", 0).Find(&batchList).Error
+	if err != nil {
+		return nil, err
+	}
+	return batchList, nil
+}
+
+func (db *withdrawBatchesDB) StoreWithdrawBatch(batch WithdrawBatches) error {
+	return db.gorm.Create(&batch).Error
+}
+
+func (db *withdrawBatchesDB) UpdateWithdrawBatchStatus(batch WithdrawBatches) error {
+	return db.gorm.Model(&WithdrawBatches{}).Where("guid = ?", batch.GUID.String()).Updates(map[string]interface{}{
+		"status":       batch.Status,
+		"block_hash":   batch.BlockHash.String(),
+		"block_number": batch.BlockNumber.String(),
+		"fee":          batch.Fee.String(),
+	}).Error
+}
+
+func (db *withdrawBatchesDB) MarkWithdrawBatchReplaced(guid uuid.UUID) error {
+	return db.gorm.Table("withdraw_batches").Where("guid = ? and status = ?", guid.String(), 0).Update("status", 3).Error
+}
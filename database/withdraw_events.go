@@ -0,0 +1,79 @@
+package database
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	WithdrawEventSubmitted       = "submitted"
+	WithdrawEventAmended         = "amended"
+	WithdrawEventCancelled       = "cancelled"
+	WithdrawEventCancelRequested = "cancel_requested"
+	WithdrawEventCancelSigned    = "cancel_signed"
+	WithdrawEventSigned          = "signed"
+	WithdrawEventReplaced        = "replaced"
+	WithdrawEventReviewed        = "reviewed"
+)
+
+type WithdrawEvents struct {
+	GUID         uuid.UUID `gorm:"primaryKey" json:"guid"`
+	WithdrawGuid uuid.UUID `json:"withdraw_guid"`
+	Event        string    `json:"event"`
+	FromStatus   uint8     `json:"from_status"`
+	ToStatus     uint8     `json:"to_status"`
+	Detail       string    `json:"detail"`
+	Timestamp    uint64
+}
+
+func (WithdrawEvents) TableName() string {
+	return "withdraw_events"
+}
+
+func NewWithdrawEvent(withdrawGuid uuid.UUID, event string, fromStatus uint8, toStatus uint8, detail string) WithdrawEvents {
+	return WithdrawEvents{
+		GUID:         uuid.New(),
+		WithdrawGuid: withdrawGuid,
+		Event:        event,
+		FromStatus:   fromStatus,
+		ToStatus:     toStatus,
+		Detail:       detail,
+		Timestamp:    uint64(time.Now().Unix()),
+	}
+}
+
+type WithdrawEventsView interface {
+	QueryWithdrawEvents(withdrawGuid uuid.UUID) ([]WithdrawEvents, error)
+}
+
+type WithdrawEventsDB interface {
+	WithdrawEventsView
+
+	StoreWithdrawEvents(eventList []WithdrawEvents) error
+}
+
+type withdrawEventsDB struct {
+	gorm *gorm.DB
+}
+
+func NewWithdrawEventsDB(db *gorm.DB) WithdrawEventsDB {
+	return &withdrawEventsDB{gorm: db}
+}
+
+func (db *withdrawEventsDB) QueryWithdrawEvents(withdrawGuid uuid.UUID) ([]WithdrawEvents, error) {
+	var eventList []WithdrawEvents
+	err := db.gorm.Table("withdraw_events").Where("withdraw_guid = ?", withdrawGuid.String()).Order("timestamp asc").Find(&eventList).Error
+	if err != nil {
+		return nil, err
+	}
+	return eventList, nil
+}
+
+func (db *withdrawEventsDB) StoreWithdrawEvents(eventList []WithdrawEvents) error {
+	if len(eventList) == 0 {
+		return nil
+	}
+	return db.gorm.CreateInBatches(&eventList, len(eventList)).Error
+}
@@ -0,0 +1,57 @@
+package database
+
+import (
+	"math/big"
+	"strings"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+const WithdrawLimitEachUser = "*"
+
+type WithdrawLimits struct {
+	GUID         uuid.UUID `gorm:"primaryKey" json:"guid"`
+	LimitName    string    `json:"limit_name"`
+	UserUid      string    `json:"user_uid"`                                                                                     // 为空表示所有用户合计；"*" 表示对每个用户分别生效；其它值只对该用户生效
+	TokenAddress string    `json:"token_address"`                                                                                // 为空表示所有币种，此时只能限制笔数，设置金额的记录会被拒绝
+	SingleMax    *big.Int  `gorm:"serializer:u256;column:single_max" db:"single_max" json:"SingleMax" form:"single_max"`         // 单笔最大金额，0 表示不限制
+	DailyAmount  *big.Int  `gorm:"serializer:u256;column:daily_amount" db:"daily_amount" json:"DailyAmount" form:"daily_amount"` // 滚动 24 小时累计金额，0 表示不限制
+	DailyCount   uint64    `json:"daily_count"`                                                                                  // 滚动 24 小时累计笔数，0 表示不限制
+	Enable       bool      `json:"enable"`
+	Timestamp    uint64
+}
+
+func (WithdrawLimits) TableName() string {
+	return "withdraw_limits"
+}
+
+type WithdrawLimitsView interface {
+	QueryEnableWithdrawLimits(userUid string, tokenAddress common.Address) ([]WithdrawLimits, error)
+}
+
+type WithdrawLimitsDB interface {
+	WithdrawLimitsView
+}
+
+type withdrawLimitsDB struct {
+	gorm *gorm.DB
+}
+
+func NewWithdrawLimitsDB(db *gorm.DB) WithdrawLimitsDB {
+	return &withdrawLimitsDB{gorm: db}
+}
+
+// QueryEnableWithdrawLimits 查询对该用户和币种生效的提现限额
+func (db *withdrawLimitsDB) QueryEnableWithdrawLimits(userUid string, tokenAddress common.Address) ([]WithdrawLimits, error) {
+	var limitList []WithdrawLimits
+	err := db.gorm.Table("withdraw_limits").
+		Where("enable = ? and user_uid in ? and token_address in ?", true, []string{"", WithdrawLimitEachUser, userUid}, []string{"", strings.ToLower(tokenAddress.String())}).
+		Order("timestamp asc").Find(&limitList).Error
+	if err != nil {
+		return nil, err
+	}
+	return limitList, nil
+}
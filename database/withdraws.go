@@ -23,18 +23,43 @@ type Withdraws struct {
 	TokenAddress     common.Address `json:"token_address" gorm:"serializer:bytes;column:token_address"`
 	Fee              *big.Int       `gorm:"serializer:u256;column:fee" db:"fee" json:"Fee" form:"fee"`
 	Amount           *big.Int       `gorm:"serializer:u256;column:amount" db:"amount" json:"Amount" form:"amount"`
-	Status           uint8          `json:"status"` // 0:提现未签名发送,1:提现已经发送到区块链网络；2:提现已上链；3:提现在钱包层已完成；4:提现已通知业务；5:提现成功
+	Status           uint8          `json:"status"` // 0:提现未签名发送,1:提现已经发送到区块链网络；2:提现已上链；3:提现在钱包层已完成；4:提现已通知业务；5:提现成功；6:风控审核中；7:风控拒绝；8:提现交易上链失败；9:提现已取消；10:链上取消中
 	TransactionIndex *big.Int       `gorm:"serializer:u256;column:transaction_index" db:"transaction_index" json:"TransactionIndex" form:"transaction_index"`
 	TxSignHex        string         `json:"tx_sign_hex" gorm:"column:tx_sign_hex"`
+	ConsumerToken    string         `json:"consumer_token" gorm:"column:consumer_token"`
+	RequestId        string         `json:"request_id" gorm:"column:request_id"`
+	UserUid          string         `json:"user_uid" gorm:"column:user_uid"`
+	BatchGuid        string         `json:"batch_guid" gorm:"column:batch_guid"`
+	FailReason       string         `json:"fail_reason" gorm:"column:fail_reason"`
+	SignDigest       string         `json:"sign_digest" gorm:"column:sign_digest"`     // 业务方 EIP-712 签名的摘要，用于防重放
+	CancelTxHex      string         `json:"cancel_tx_hex" gorm:"column:cancel_tx_hex"` // 链上取消时同 nonce 的零金额自转交易
 	Timestamp        uint64
 }
 
+type WithdrawStats struct {
+	Count  int64
+	Amount *big.Int `gorm:"serializer:u256;column:amount"`
+}
+
+var ErrWithdrawRequestConflict = errors.New("withdraw request id already submitted with different params")
+
+// ErrWithdrawStatusChanged 提现状态已被并发修改（例如签名前被取消）
+var ErrWithdrawStatusChanged = errors.New("withdraw status changed")
+
 type WithdrawsView interface {
 	QueryWithdrawsByHash(hash common.Hash) (*Withdraws, error)
+	QueryWithdrawsByRequestId(consumerToken string, requestId string) (*Withdraws, error)
+	QueryWithdrawsBySignDigest(signDigest string) (*Withdraws, error)
+	QueryWithdrawsByGuid(guid uuid.UUID) (*Withdraws, error)
+	QueryCancellingWithdraws() ([]Withdraws, error)
 	UnSendWithdrawsList() ([]Withdraws, error)
+	QueryUnconfirmedWithdraws() ([]Withdraws, error)
+	WithdrawStatsSince(userUid string, tokenAddress string, since uint64, excludeGuid uuid.UUID) (*WithdrawStats, error)
+	SignedWithdrawStatsSince(userUid string, tokenAddress string, since uint64, excludeGuid uuid.UUID) (*WithdrawStats, error)
+	FirstWithdrawTimestampToAddress(toAddress common.Address) (uint64, error)
 	ApiWithdrawList(string, int, int, string) ([]Withdraws, int64)
 
-	SubmitWithdrawFromBusiness(fromAddress common.Address, toAddress common.Address, TokenAddress common.Address, amount *big.Int) error
+	SubmitWithdrawFromBusiness(consumerToken string, requestId string, userUid string, fromAddress common.Address, toAddress common.Address, TokenAddress common.Address, amount *big.Int, signDigest string) (*Withdraws, error)
 }
 
 type WithdrawsDB interface {
@@ -43,6 +68,19 @@ type WithdrawsDB interface {
 	StoreWithdraws([]Withdraws, uint64) error
 	UpdateTransactionStatus(withdrawsList []Withdraws) error
 	MarkWithdrawsToSend(withdrawsList []Withdraws) error
+	UpdateWithdrawStatus(guid uuid.UUID, status uint8) error
+	UpdateWithdrawFailReason(guid uuid.UUID, status uint8, failReason string) error
+	ReviewWithdraw(guid uuid.UUID, approve bool) (bool, error)
+	MarkWithdrawSigned(withdraw Withdraws, hash common.Hash, txSignHex string) error
+	ResetReplacedWithdraw(guid uuid.UUID) error
+	ResetReplacedBatchWithdraws(batchGuid uuid.UUID) error
+	MarkBatchWithdrawsToSend(batchGuid uuid.UUID, hash common.Hash, withdrawList []Withdraws) error
+	UpdateBatchWithdrawsStatus(batch WithdrawBatches) error
+	CancelPendingWithdraw(guid uuid.UUID, detail string) (bool, error)
+	AmendPendingWithdraw(guid uuid.UUID, toAddress common.Address, amount *big.Int, signDigest string, detail string) (bool, error)
+	RequestWithdrawCancel(guid uuid.UUID, detail string) (bool, error)
+	MarkWithdrawCancelSigned(guid uuid.UUID, cancelTxHex string) error
+	MarkWithdrawCancelled(guid uuid.UUID) error
 }
 
 type withdrawsDB struct {
@@ -90,7 +128,41 @@ func (db *withdrawsDB) QueryWithdrawsByHash(hash common.Hash) (*Withdraws, error
 	return &withdrawsEntity, nil
 }
 
-func (db *withdrawsDB) SubmitWithdrawFromBusiness(fromAddress common.Address, toAddress common.Address, TokenAddress common.Address, amount *big.Int) error {
+func (db *withdrawsDB) QueryWithdrawsByRequestId(consumerToken string, requestId string) (*Withdraws, error) {
+	var withdrawsEntity Withdraws
+	result := db.gorm.Table("withdraws").Where("consumer_token = ? and request_id = ?", consumerToken, requestId).Take(&withdrawsEntity)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, result.Error
+	}
+	return &withdrawsEntity, nil
+}
+
+func (db *withdrawsDB) QueryWithdrawsBySignDigest(signDigest string) (*Withdraws, error) {
+	var withdrawsEntity Withdraws
+	result := db.gorm.Table("withdraws").Where("sign_digest = ?", signDigest).Take(&withdrawsEntity)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, result.Error
+	}
+	return &withdrawsEntity, nil
+}
+
+// SubmitWithdrawFromBusiness 按 consumer_token + request_id 幂等提交提现：重复提交返回原提现记录，参数不一致的重复提交返回 ErrWithdrawRequestConflict
+func (db *withdrawsDB) SubmitWithdrawFromBusiness(consumerToken string, requestId string, userUid string, fromAddress common.Address, toAddress common.Address, TokenAddress common.Address, amount *big.Int, signDigest string) (*Withdraws, error) {
+	existWithdraw, err := db.QueryWithdrawsByRequestId(consumerToken, requestId)
+	if err != nil {
+		log.Error("query withdraw by request id fail", "requestId", requestId, "err", err)
+		return nil, err
+	}
+	if existWithdraw != nil {
+		return matchWithdrawRequest(existWithdraw, userUid, fromAddress, toAddress, TokenAddress, amount)
+	}
+
 	withdrawS := Withdraws{
 		GUID:             uuid.New(),
 		BlockHash:        common.Hash{},
@@ -104,14 +176,36 @@ func (db *withdrawsDB) SubmitWithdrawFromBusiness(fromAddress common.Address, to
 		Status:           0,
 		TransactionIndex: big.NewInt(time.Now().Unix()),
 		TxSignHex:        "",
+		ConsumerToken:    consumerToken,
+		RequestId:        requestId,
+		UserUid:          userUid,
+		SignDigest:       signDigest,
 		Timestamp:        uint64(time.Now().Unix()),
 	}
-	errC := db.gorm.Create(withdrawS).Error
+	errC := db.gorm.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&withdrawS).Error; err != nil {
+			return err
+		}
+		return NewWithdrawEventsDB(tx).StoreWithdrawEvents([]WithdrawEvents{NewWithdrawEvent(withdrawS.GUID, WithdrawEventSubmitted, 0, 0, "")})
+	})
 	if errC != nil {
+		// 并发重复提交会触发唯一索引冲突，此时以先写入的记录为准
+		existWithdraw, err := db.QueryWithdrawsByRequestId(consumerToken, requestId)
+		if err == nil && existWithdraw != nil {
+			return matchWithdrawRequest(existWithdraw, userUid, fromAddress, toAddress, TokenAddress, amount)
+		}
 		log.Error("create withdraw fail", "err", errC)
-		return errC
+		return nil, errC
 	}
-	return nil
+	return &withdrawS, nil
+}
+
+func matchWithdrawRequest(withdraw *Withdraws, userUid string, fromAddress common.Address, toAddress common.Address, TokenAddress common.Address, amount *big.Int) (*Withdraws, error) {
+	if withdraw.UserUid != userUid || withdraw.FromAddress != fromAddress || withdraw.ToAddress != toAddress || withdraw.TokenAddress != TokenAddress || withdraw.Amount.Cmp(amount) != 0 {
+		log.Warn("withdraw request id conflict", "requestId", withdraw.RequestId, "guid", withdraw.GUID)
+		return nil, ErrWithdrawRequestConflict
+	}
+	return withdraw, nil
 }
 
 func (db *withdrawsDB) UpdateTransactionStatus(withdrawsList []Withdraws) error {
@@ -125,7 +219,11 @@ func (db *withdrawsDB) UpdateTransactionStatus(withdrawsList []Withdraws) error
 			}
 			return result.Error
 		}
+		// 回执失败的提现与批量提现一致标记为上链失败
 		withdrawsSingle.Status = 2
+		if withdrawsList[i].Status != 1 {
+			withdrawsSingle.Status = 8
+		}
 		withdrawsSingle.Fee = withdrawsList[i].Fee
 		err := db.gorm.Save(&withdrawsSingle).Error
 		if err != nil {
@@ -156,6 +254,253 @@ func (db *withdrawsDB) UnSendWithdrawsList() ([]Withdraws, error) {
 	return withdrawsList, nil
 }
 
+// QueryUnconfirmedWithdraws 查询已签名并持久化、但还未确认上链的单笔提现
+func (db *withdrawsDB) QueryUnconfirmedWithdraws() ([]Withdraws, error) {
+	var withdrawsList []Withdraws
+	err := db.gorm.Table("withdraws").Where("status = ? and batch_guid = ? and tx_sign_hex <> ?", 1, "", "").Find(&withdrawsList).Error
+	if err != nil {
+		return nil, err
+	}
+	return withdrawsList, nil
+}
+
+// WithdrawStatsSince 统计 since 之后的提现笔数和金额，userUid 和 tokenAddress 为空时不按该维度过滤，风控拒绝的提现不计入
+func (db *withdrawsDB) WithdrawStatsSince(userUid string, tokenAddress string, since uint64, excludeGuid uuid.UUID) (*WithdrawStats, error) {
+	return db.withdrawStats(db.gorm.Where("status not in ?", []uint8{7, 9}), userUid, tokenAddress, since, excludeGuid)
+}
+
+// SignedWithdrawStatsSince 只统计已签名出款的提现，签名前复核限额时使用
+func (db *withdrawsDB) SignedWithdrawStatsSince(userUid string, tokenAddress string, since uint64, excludeGuid uuid.UUID) (*WithdrawStats, error) {
+	return db.withdrawStats(db.gorm.Where("status in ?", []uint8{1, 2, 3, 4, 5, 10}), userUid, tokenAddress, since, excludeGuid)
+}
+
+func (db *withdrawsDB) withdrawStats(statusQuery *gorm.DB, userUid string, tokenAddress string, since uint64, excludeGuid uuid.UUID) (*WithdrawStats, error) {
+	var stats WithdrawStats
+	query := db.gorm.Table("withdraws").Select("count(*) as count, coalesce(sum(amount), 0) as amount").Where("timestamp >= ? and guid <> ?", since, excludeGuid.String()).Where(statusQuery)
+	if userUid != "" {
+		query = query.Where("user_uid = ?", userUid)
+	}
+	if tokenAddress != "" {
+		query = query.Where("token_address = ?", strings.ToLower(tokenAddress))
+	}
+	err := query.Scan(&stats).Error
+	if err != nil {
+		return nil, err
+	}
+	if stats.Amount == nil {
+		stats.Amount = big.NewInt(0)
+	}
+	return &stats, nil
+}
+
+func (db *withdrawsDB) FirstWithdrawTimestampToAddress(toAddress common.Address) (uint64, error) {
+	var timestamp uint64
+	err := db.gorm.Table("withdraws").Select("coalesce(min(timestamp), 0)").Where("to_address = ?", strings.ToLower(toAddress.String())).Scan(&timestamp).Error
+	if err != nil {
+		return 0, err
+	}
+	return timestamp, nil
+}
+
+// UpdateWithdrawStatus 未发送的提现转入风控审核或拒绝状态，已签名、已取消或已审核的提现不受影响
+func (db *withdrawsDB) UpdateWithdrawStatus(guid uuid.UUID, status uint8) error {
+	return db.gorm.Table("withdraws").Where("guid = ? and status = ?", guid.String(), 0).Update("status", status).Error
+}
+
+// UpdateWithdrawFailReason 未发送的提现转入指定状态并记录原因
+func (db *withdrawsDB) UpdateWithdrawFailReason(guid uuid.UUID, status uint8, failReason string) error {
+	return db.gorm.Table("withdraws").Where("guid = ? and status = ?", guid.String(), 0).Updates(map[string]interface{}{
+		"status":      status,
+		"fail_reason": failReason,
+	}).Error
+}
+
+// ReviewWithdraw 人工审核风控审核状态的提现，通过后回到未发送状态，拒绝后转入风控拒绝状态；返回 false 表示提现不在审核状态
+func (db *withdrawsDB) ReviewWithdraw(guid uuid.UUID, approve bool) (bool, error) {
+	status := uint8(7)
+	if approve {
+		status = 0
+	}
+	result := db.gorm.Table("withdraws").Where("guid = ? and status = ?", guid.String(), 6).Updates(map[string]interface{}{
+		"status":      status,
+		"fail_reason": "",
+	})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// signedWithdraw 修改提现不改变状态，除状态外还需比对签名时的目标地址、金额和签名摘要
+func signedWithdraw(tx *gorm.DB, withdraw Withdraws) *gorm.DB {
+	return tx.Table("withdraws").Where("guid = ? and status = ? and to_address = ? and amount = ? and sign_digest = ?",
+		withdraw.GUID.String(), 0, strings.ToLower(withdraw.ToAddress.String()), withdraw.Amount.String(), withdraw.SignDigest)
+}
+
+// MarkWithdrawSigned 在广播前保存签名后的交易和哈希，进程在广播前后崩溃都不会重复签名出款
+func (db *withdrawsDB) MarkWithdrawSigned(withdraw Withdraws, hash common.Hash, txSignHex string) error {
+	result := signedWithdraw(db.gorm, withdraw).Updates(map[string]interface{}{
+		"hash":        hash.String(),
+		"tx_sign_hex": txSignHex,
+		"status":      1,
+	})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrWithdrawStatusChanged
+	}
+	return NewWithdrawEventsDB(db.gorm).StoreWithdrawEvents([]WithdrawEvents{NewWithdrawEvent(withdraw.GUID, WithdrawEventSigned, 0, 1, hash.String())})
+}
+
+func (db *withdrawsDB) QueryWithdrawsByGuid(guid uuid.UUID) (*Withdraws, error) {
+	var withdrawsEntity Withdraws
+	result := db.gorm.Table("withdraws").Where("guid = ?", guid.String()).Take(&withdrawsEntity)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, result.Error
+	}
+	return &withdrawsEntity, nil
+}
+
+// QueryCancellingWithdraws 查询已请求链上取消、等待取消交易上链的提现
+func (db *withdrawsDB) QueryCancellingWithdraws() ([]Withdraws, error) {
+	var withdrawsList []Withdraws
+	err := db.gorm.Table("withdraws").Where("status = ?", 10).Find(&withdrawsList).Error
+	if err != nil {
+		return nil, err
+	}
+	return withdrawsList, nil
+}
+
+// transitWithdraw 在提现处于 fromStatusList 之一时更新并记录事件，返回 false 表示当前状态不允许该操作
+func (db *withdrawsDB) transitWithdraw(guid uuid.UUID, fromStatusList []uint8, event string, detail string, updates map[string]interface{}) (bool, error) {
+	transited := false
+	err := db.gorm.Transaction(func(tx *gorm.DB) error {
+		var withdraw Withdraws
+		if err := tx.Table("withdraws").Where("guid = ? and status in ?", guid.String(), fromStatusList).Take(&withdraw).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil
+			}
+			return err
+		}
+		result := tx.Table("withdraws").Where("guid = ? and status = ?", guid.String(), withdraw.Status).Updates(updates)
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		transited = true
+		toStatus := withdraw.Status
+		if status, ok := updates["status"].(uint8); ok {
+			toStatus = status
+		}
+		return NewWithdrawEventsDB(tx).StoreWithdrawEvents([]WithdrawEvents{NewWithdrawEvent(guid, event, withdraw.Status, toStatus, detail)})
+	})
+	if err != nil {
+		return false, err
+	}
+	return transited, nil
+}
+
+// CancelPendingWithdraw 取消还未签名的提现（未发送或审核中）
+func (db *withdrawsDB) CancelPendingWithdraw(guid uuid.UUID, detail string) (bool, error) {
+	return db.transitWithdraw(guid, []uint8{0, 6}, WithdrawEventCancelled, detail, map[string]interface{}{
+		"status": uint8(9),
+	})
+}
+
+// AmendPendingWithdraw 修改还未签名提现的目标地址和金额，修改后重新走风控
+func (db *withdrawsDB) AmendPendingWithdraw(guid uuid.UUID, toAddress common.Address, amount *big.Int, signDigest string, detail string) (bool, error) {
+	return db.transitWithdraw(guid, []uint8{0, 6}, WithdrawEventAmended, detail, map[string]interface{}{
+		"to_address":  strings.ToLower(toAddress.String()),
+		"amount":      amount.String(),
+		"sign_digest": signDigest,
+		"fail_reason": "",
+		"status":      uint8(0),
+	})
+}
+
+// RequestWithdrawCancel 对已广播未上链的单笔提现请求链上取消，由出款任务发送同 nonce 的取消交易
+func (db *withdrawsDB) RequestWithdrawCancel(guid uuid.UUID, detail string) (bool, error) {
+	withdraw, err := db.QueryWithdrawsByGuid(guid)
+	if err != nil || withdraw == nil {
+		return false, err
+	}
+	// 批量出款的交易包含多笔提现，不能单独取消
+	if withdraw.BatchGuid != "" || withdraw.TxSignHex == "" {
+		return false, nil
+	}
+	return db.transitWithdraw(guid, []uint8{1}, WithdrawEventCancelRequested, detail, map[string]interface{}{
+		"status": uint8(10),
+	})
+}
+
+func (db *withdrawsDB) MarkWithdrawCancelSigned(guid uuid.UUID, cancelTxHex string) error {
+	return db.gorm.Table("withdraws").Where("guid = ? and status = ?", guid.String(), 10).Update("cancel_tx_hex", cancelTxHex).Error
+}
+
+// MarkWithdrawCancelled 取消交易占用了原交易的 nonce，提现取消完成
+func (db *withdrawsDB) MarkWithdrawCancelled(guid uuid.UUID) error {
+	result := db.gorm.Table("withdraws").Where("guid = ? and status = ?", guid.String(), 10).Update("status", 9)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrWithdrawStatusChanged
+	}
+	return NewWithdrawEventsDB(db.gorm).StoreWithdrawEvents([]WithdrawEvents{NewWithdrawEvent(guid, WithdrawEventCancelled, 10, 9, "cancel tx mined")})
+}
+
+// ResetReplacedWithdraw 交易的 nonce 已被其它交易占用、永远不会上链时，提现退回未发送状态重新出款
+func (db *withdrawsDB) ResetReplacedWithdraw(guid uuid.UUID) error {
+	return db.gorm.Table("withdraws").Where("guid = ? and status = ?", guid.String(), 1).Updates(map[string]interface{}{
+		"hash":        common.Hash{}.String(),
+		"tx_sign_hex": "",
+		"status":      0,
+	}).Error
+}
+
+func (db *withdrawsDB) ResetReplacedBatchWithdraws(batchGuid uuid.UUID) error {
+	return db.gorm.Table("withdraws").Where("batch_guid = ? and status = ?", batchGuid.String(), 1).Updates(map[string]interface{}{
+		"hash":       common.Hash{}.String(),
+		"batch_guid": "",
+		"status":     0,
+	}).Error
+}
+
+func (db *withdrawsDB) MarkBatchWithdrawsToSend(batchGuid uuid.UUID, hash common.Hash, withdrawList []Withdraws) error {
+	eventList := make([]WithdrawEvents, len(withdrawList))
+	for i, withdraw := range withdrawList {
+		result := signedWithdraw(db.gorm, withdraw).Updates(map[string]interface{}{
+			"hash":       hash.String(),
+			"batch_guid": batchGuid.String(),
+			"status":     1,
+		})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrWithdrawStatusChanged
+		}
+		eventList[i] = NewWithdrawEvent(withdraw.GUID, WithdrawEventSigned, 0, 1, hash.String())
+	}
+	return NewWithdrawEventsDB(db.gorm).StoreWithdrawEvents(eventList)
+}
+
+// UpdateBatchWithdrawsStatus 根据批量交易回执更新批次内所有提现的状态
+func (db *withdrawsDB) UpdateBatchWithdrawsStatus(batch WithdrawBatches) error {
+	var status uint8 = 2
+	if batch.Status != 1 {
+		status = 8
+	}
+	return db.gorm.Table("withdraws").Where("batch_guid = ? and status = ?", batch.GUID.String(), 1).Updates(map[string]interface{}{
+		"status":       status,
+		"block_hash":   batch.BlockHash.String(),
+		"block_number": batch.BlockNumber.String(),
+	}).Error
+}
+
 func (db *withdrawsDB) MarkWithdrawsToSend(withdrawsList []Withdraws) error {
 	for i := 0; i < len(withdrawsList); i++ {
 		var withdrawsSingle = Withdraws{}
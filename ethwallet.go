@@ -6,7 +6,10 @@ import (
 	"github.com/the-web3/eth-wallet/config"
 	"github.com/the-web3/eth-wallet/database"
 	"github.com/the-web3/eth-wallet/wallet"
+	"github.com/the-web3/eth-wallet/wallet/hdwallet"
+	"github.com/the-web3/eth-wallet/wallet/kms"
 	"github.com/the-web3/eth-wallet/wallet/node"
+	"github.com/the-web3/eth-wallet/wallet/signer"
 	"sync/atomic"
 )
 
@@ -14,6 +17,7 @@ type EthWallet struct {
 	deposit        *wallet.Deposit
 	withdraw       *wallet.Withdraw
 	collectionCold *wallet.CollectionCold
+	reconciler     *wallet.Reconciler
 
 	shutdown context.CancelCauseFunc
 	stopped  atomic.Bool
@@ -31,14 +35,34 @@ func NewEthWallet(ctx context.Context, cfg *config.Config, shutdown context.Canc
 		return nil, err
 	}
 
+	keyManager, err := kms.NewKMS(&cfg.KMS)
+	if err != nil {
+		log.Error("init kms fail", "err", err)
+		return nil, err
+	}
+
+	hd, err := hdwallet.NewHDWallet(&cfg.HDWallet)
+	if err != nil {
+		log.Error("init hd wallet fail", "err", err)
+		return nil, err
+	}
+
+	txSigner, err := signer.NewSigner(ctx, &cfg.Signer, db, keyManager, hd)
+	if err != nil {
+		log.Error("init signer fail", "err", err)
+		return nil, err
+	}
+
 	deposit, _ := wallet.NewDeposit(cfg, db, ethClient, shutdown)
-	withdraw, _ := wallet.NewWithdraw(cfg, db, ethClient, shutdown)
-	collectionCold, _ := wallet.NewCollectionCold(cfg, db, ethClient, shutdown)
+	withdraw, _ := wallet.NewWithdraw(cfg, db, ethClient, txSigner, shutdown)
+	collectionCold, _ := wallet.NewCollectionCold(cfg, db, ethClient, txSigner, shutdown)
+	reconciler, _ := wallet.NewReconciler(cfg, db, ethClient, shutdown)
 
 	out := &EthWallet{
 		deposit:        deposit,
 		withdraw:       withdraw,
 		collectionCold: collectionCold,
+		reconciler:     reconciler,
 		shutdown:       shutdown,
 	}
 
@@ -58,6 +82,10 @@ func (ew *EthWallet) Start(ctx context.Context) error {
 	if err != nil {
 		return err
 	}
+	err = ew.reconciler.Start()
+	if err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -75,6 +103,10 @@ func (ew *EthWallet) Stop(ctx context.Context) error {
 	if err != nil {
 		return err
 	}
+	err = ew.reconciler.Close()
+	if err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -74,6 +74,194 @@ var (
 		EnvVars: prefixEnvVars("BLOCKS_STEP"),
 		Value:   500,
 	}
+	WithdrawBatchEnableFlag = &cli.BoolFlag{
+		Name:    "withdraw-batch-enable",
+		Usage:   "Whether to batch withdrawals of the same token through the disperse contract",
+		EnvVars: prefixEnvVars("WITHDRAW_BATCH_ENABLE"),
+	}
+	WithdrawBatchSizeFlag = &cli.UintFlag{
+		Name:    "withdraw-batch-size",
+		Usage:   "The max number of withdrawals in one disperse transaction",
+		EnvVars: prefixEnvVars("WITHDRAW_BATCH_SIZE"),
+		Value:   100,
+	}
+	DisperseContractFlag = &cli.StringFlag{
+		Name:    "disperse-contract",
+		Usage:   "The address of the disperse contract used by batched withdrawals",
+		EnvVars: prefixEnvVars("DISPERSE_CONTRACT"),
+	}
+	GasStationAddressFlag = &cli.StringFlag{
+		Name:    "gas-station-address",
+		Usage:   "The wallet that tops up user deposit addresses with ETH for token collection gas",
+		EnvVars: prefixEnvVars("GAS_STATION_ADDRESS"),
+	}
+	ForwarderFactoryFlag = &cli.StringFlag{
+		Name:    "forwarder-factory",
+		Usage:   "The CREATE2 factory of user deposit forwarders, collection sweeps forwarders through it",
+		EnvVars: prefixEnvVars("FORWARDER_FACTORY"),
+	}
+	ForwarderInitCodeHashFlag = &cli.StringFlag{
+		Name:    "forwarder-init-code-hash",
+		Usage:   "The keccak256 hash of the forwarder init code deployed by the factory",
+		EnvVars: prefixEnvVars("FORWARDER_INIT_CODE_HASH"),
+	}
+	ForwarderUserUidFlag = &cli.StringSliceFlag{
+		Name:    "forwarder-user-uid",
+		Usage:   "User uids to create forwarder deposit addresses for, used by generate-forwarder-address",
+		EnvVars: prefixEnvVars("FORWARDER_USER_UID"),
+	}
+	ReconcileIntervalFlag = &cli.DurationFlag{
+		Name:    "reconcile-interval",
+		Usage:   "How often to reconcile database balances against on-chain balances, 0 disables the worker",
+		EnvVars: prefixEnvVars("RECONCILE_INTERVAL"),
+		Value:   30 * time.Minute,
+	}
+	ReconcileBatchSizeFlag = &cli.UintFlag{
+		Name:    "reconcile-batch-size",
+		Usage:   "Number of balances queried in one batched rpc request during reconciliation",
+		EnvVars: prefixEnvVars("RECONCILE_BATCH_SIZE"),
+		Value:   100,
+	}
+	ReconcileToleranceFlag = &cli.StringFlag{
+		Name:    "reconcile-tolerance",
+		Usage:   "Default difference in wei or token units tolerated before a reconciliation discrepancy raises an alert",
+		EnvVars: prefixEnvVars("RECONCILE_TOLERANCE"),
+		Value:   "0",
+	}
+	ReconcileTokenTolerancesFlag = &cli.StringFlag{
+		Name:    "reconcile-token-tolerances",
+		Usage:   "Per token tolerances as token:amount pairs separated by commas, the zero address stands for ETH",
+		EnvVars: prefixEnvVars("RECONCILE_TOKEN_TOLERANCES"),
+	}
+	BalanceSnapshotIntervalFlag = &cli.DurationFlag{
+		Name:    "balance-snapshot-interval",
+		Usage:   "How often to snapshot all changed balances besides the per batch snapshot, 0 disables it",
+		EnvVars: prefixEnvVars("BALANCE_SNAPSHOT_INTERVAL"),
+		Value:   time.Hour,
+	}
+	ReservesFileFlag = &cli.StringFlag{
+		Name:    "reserves-file",
+		Usage:   "File the proof-of-reserves command publishes the merkle roots, liabilities and on-chain holdings to",
+		EnvVars: prefixEnvVars("RESERVES_FILE"),
+		Value:   "reserves.json",
+	}
+	HotWalletStrategyFlag = &cli.StringFlag{
+		Name:    "hot-wallet-strategy",
+		Usage:   "The hot wallet selection strategy: round-robin, most-funded or token-assign",
+		EnvVars: prefixEnvVars("HOT_WALLET_STRATEGY"),
+		Value:   "round-robin",
+	}
+	HotWalletTokenAssignmentsFlag = &cli.StringFlag{
+		Name:    "hot-wallet-token-assignments",
+		Usage:   "Comma separated token:hotWallet pairs used by the token-assign strategy",
+		EnvVars: prefixEnvVars("HOT_WALLET_TOKEN_ASSIGNMENTS"),
+	}
+	HotWalletMaxPendingTxFlag = &cli.UintFlag{
+		Name:    "hot-wallet-max-pending-tx",
+		Usage:   "Skip a hot wallet while it has more pending transactions than this",
+		EnvVars: prefixEnvVars("HOT_WALLET_MAX_PENDING_TX"),
+		Value:   16,
+	}
+	SignerTypeFlag = &cli.StringFlag{
+		Name:    "signer-type",
+		Usage:   "The transaction signer: local, keystore or remote",
+		EnvVars: prefixEnvVars("SIGNER_TYPE"),
+		Value:   "local",
+	}
+	SignerKeystoreDirFlag = &cli.StringFlag{
+		Name:    "signer-keystore-dir",
+		Usage:   "The directory of encrypted keystore files for the keystore signer",
+		EnvVars: prefixEnvVars("SIGNER_KEYSTORE_DIR"),
+	}
+	SignerKeystorePasswordFileFlag = &cli.StringFlag{
+		Name:    "signer-keystore-password-file",
+		Usage:   "The file holding the password that unlocks the keystore files",
+		EnvVars: prefixEnvVars("SIGNER_KEYSTORE_PASSWORD_FILE"),
+	}
+	SignerRemoteUrlFlag = &cli.StringFlag{
+		Name:    "signer-remote-url",
+		Usage:   "The JSON-RPC endpoint of the remote signer",
+		EnvVars: prefixEnvVars("SIGNER_REMOTE_URL"),
+	}
+	SignerRemoteMethodFlag = &cli.StringFlag{
+		Name:    "signer-remote-method",
+		Usage:   "The JSON-RPC method of the remote signer, eth_signTransaction or account_signTransaction",
+		EnvVars: prefixEnvVars("SIGNER_REMOTE_METHOD"),
+		Value:   "eth_signTransaction",
+	}
+	KmsMasterKeyFlag = &cli.StringFlag{
+		Name:    "kms-master-key",
+		Usage:   "The hex encoded 32 byte master key that wraps private key data keys",
+		EnvVars: prefixEnvVars("KMS_MASTER_KEY"),
+	}
+	KmsMasterKeyFileFlag = &cli.StringFlag{
+		Name:    "kms-master-key-file",
+		Usage:   "The file holding the hex encoded master key, used when kms-master-key is empty",
+		EnvVars: prefixEnvVars("KMS_MASTER_KEY_FILE"),
+	}
+	HdMnemonicFlag = &cli.StringFlag{
+		Name:    "hd-mnemonic",
+		Usage:   "The BIP-39 mnemonic used to derive addresses along m/44'/60'/0'/0/i",
+		EnvVars: prefixEnvVars("HD_MNEMONIC"),
+	}
+	HdMnemonicFileFlag = &cli.StringFlag{
+		Name:    "hd-mnemonic-file",
+		Usage:   "The file holding the BIP-39 mnemonic, used when hd-mnemonic is empty",
+		EnvVars: prefixEnvVars("HD_MNEMONIC_FILE"),
+	}
+	HdPassphraseFlag = &cli.StringFlag{
+		Name:    "hd-passphrase",
+		Usage:   "The optional BIP-39 passphrase",
+		EnvVars: prefixEnvVars("HD_PASSPHRASE"),
+	}
+	HdColdWalletAddressFlag = &cli.StringFlag{
+		Name:    "hd-cold-wallet-address",
+		Usage:   "The cold wallet address stored by generate-address when deriving from a mnemonic, its key must not come from that mnemonic",
+		EnvVars: prefixEnvVars("HD_COLD_WALLET_ADDRESS"),
+	}
+	AddressBookEnforceFlag = &cli.BoolFlag{
+		Name:    "address-book-enforce",
+		Usage:   "Reject withdrawals to destinations not in the address book or still cooling off",
+		EnvVars: prefixEnvVars("ADDRESS_BOOK_ENFORCE"),
+	}
+	AddressBookCoolingPeriodFlag = &cli.DurationFlag{
+		Name:    "address-book-cooling-period",
+		Usage:   "How long a newly added address book entry waits before it can receive withdrawals",
+		EnvVars: prefixEnvVars("ADDRESS_BOOK_COOLING_PERIOD"),
+		Value:   24 * time.Hour,
+	}
+	ColdSignOfflineFlag = &cli.BoolFlag{
+		Name:    "cold-sign-offline",
+		Usage:   "Sign cold wallet transactions on an air-gapped machine through exported tx files instead of the signer",
+		EnvVars: prefixEnvVars("COLD_SIGN_OFFLINE"),
+	}
+	ColdTxFileFlag = &cli.StringFlag{
+		Name:    "cold-tx-file",
+		Usage:   "The unsigned cold wallet tx file written by cold-export and read by cold-sign",
+		EnvVars: prefixEnvVars("COLD_TX_FILE"),
+		Value:   "cold-unsigned.json",
+	}
+	ColdSignedTxFileFlag = &cli.StringFlag{
+		Name:    "cold-signed-tx-file",
+		Usage:   "The signed cold wallet tx file written by cold-sign and read by cold-import",
+		EnvVars: prefixEnvVars("COLD_SIGNED_TX_FILE"),
+		Value:   "cold-signed.json",
+	}
+	ColdKeyFileFlag = &cli.StringFlag{
+		Name:    "cold-key-file",
+		Usage:   "File holding the hex encoded cold wallet private key, only used by cold-sign on the offline machine",
+		EnvVars: prefixEnvVars("COLD_KEY_FILE"),
+	}
+	WithdrawSignRequiredFlag = &cli.BoolFlag{
+		Name:    "withdraw-sign-required",
+		Usage:   "Require an EIP-712 signature from a registered business signer on every withdrawal submission",
+		EnvVars: prefixEnvVars("WITHDRAW_SIGN_REQUIRED"),
+	}
+	KmsPreviousMasterKeyFilesFlag = &cli.StringSliceFlag{
+		Name:    "kms-previous-master-key-files",
+		Usage:   "Files holding retired master keys, only used to unwrap data keys during rekey",
+		EnvVars: prefixEnvVars("KMS_PREVIOUS_MASTER_KEY_FILES"),
+	}
 	// Rest api flags
 	HttpHostFlag = &cli.StringFlag{
 		Name:     "http-host",
@@ -189,6 +377,18 @@ var (
 		Usage:   "The db name of the slave database",
 		EnvVars: prefixEnvVars("SLAVE_DB_NAME"),
 	}
+	SlaveDbMaxLagFlag = &cli.DurationFlag{
+		Name:    "slave-db-max-lag",
+		Usage:   "Replication lag above which reads fall back to the master database",
+		EnvVars: prefixEnvVars("SLAVE_DB_MAX_LAG"),
+		Value:   10 * time.Second,
+	}
+	SlaveDbLagCheckIntervalFlag = &cli.DurationFlag{
+		Name:    "slave-db-lag-check-interval",
+		Usage:   "How often to measure the replication lag of the slave database",
+		EnvVars: prefixEnvVars("SLAVE_DB_LAG_CHECK_INTERVAL"),
+		Value:   5 * time.Second,
+	}
 
 	// cache flags
 	ApiCacheListSizeFlag = &cli.UintFlag{
@@ -241,6 +441,10 @@ var requireFlags = []cli.Flag{
 }
 
 var optionalFlags = []cli.Flag{
+	WithdrawBatchEnableFlag,
+	WithdrawBatchSizeFlag,
+	DisperseContractFlag,
+	GasStationAddressFlag,
 	SlaveDbHostFlag,
 	SlaveDbPortFlag,
 	SlaveDbUserFlag,
@@ -250,6 +454,39 @@ var optionalFlags = []cli.Flag{
 	ApiCacheDetailSizeFlag,
 	ApiCacheListExpireTimeFlag,
 	ApiCacheDetailExpireTimeFlag,
+	HotWalletStrategyFlag,
+	HotWalletTokenAssignmentsFlag,
+	HotWalletMaxPendingTxFlag,
+	SignerTypeFlag,
+	SignerKeystoreDirFlag,
+	SignerKeystorePasswordFileFlag,
+	SignerRemoteUrlFlag,
+	SignerRemoteMethodFlag,
+	KmsMasterKeyFlag,
+	KmsMasterKeyFileFlag,
+	KmsPreviousMasterKeyFilesFlag,
+	HdMnemonicFlag,
+	HdMnemonicFileFlag,
+	HdPassphraseFlag,
+	HdColdWalletAddressFlag,
+	AddressBookEnforceFlag,
+	AddressBookCoolingPeriodFlag,
+	WithdrawSignRequiredFlag,
+	ColdSignOfflineFlag,
+	ColdTxFileFlag,
+	ColdSignedTxFileFlag,
+	ColdKeyFileFlag,
+	ForwarderFactoryFlag,
+	ForwarderInitCodeHashFlag,
+	ForwarderUserUidFlag,
+	ReconcileIntervalFlag,
+	ReconcileBatchSizeFlag,
+	ReconcileToleranceFlag,
+	ReconcileTokenTolerancesFlag,
+	BalanceSnapshotIntervalFlag,
+	ReservesFileFlag,
+	SlaveDbMaxLagFlag,
+	SlaveDbLagCheckIntervalFlag,
 }
 
 func init() {
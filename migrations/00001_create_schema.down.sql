@@ -0,0 +1,8 @@
+DROP TABLE IF EXISTS withdraws;
+DROP TABLE IF EXISTS deposits;
+DROP TABLE IF EXISTS transactions;
+DROP TABLE IF EXISTS balances;
+DROP TABLE IF EXISTS addresses;
+DROP TABLE IF EXISTS tokens;
+DROP TABLE IF EXISTS blocks;
+DROP DOMAIN IF EXISTS UINT256;
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS withdraws_consumer_request_id;
+ALTER TABLE withdraws DROP COLUMN IF EXISTS request_id;
+ALTER TABLE withdraws DROP COLUMN IF EXISTS consumer_token;
@@ -0,0 +1,3 @@
+ALTER TABLE withdraws ADD COLUMN IF NOT EXISTS consumer_token VARCHAR NOT NULL DEFAULT '';
+ALTER TABLE withdraws ADD COLUMN IF NOT EXISTS request_id VARCHAR NOT NULL DEFAULT '';
+CREATE UNIQUE INDEX IF NOT EXISTS withdraws_consumer_request_id ON withdraws(consumer_token, request_id) WHERE request_id <> '';
@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS risk_decisions;
+DROP TABLE IF EXISTS risk_blocklist;
+DROP TABLE IF EXISTS risk_rules;
+DROP INDEX IF EXISTS withdraws_to_address;
+DROP INDEX IF EXISTS withdraws_user_uid;
+ALTER TABLE withdraws DROP COLUMN IF EXISTS user_uid;
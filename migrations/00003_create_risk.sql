@@ -0,0 +1,39 @@
+ALTER TABLE withdraws ADD COLUMN IF NOT EXISTS user_uid VARCHAR NOT NULL DEFAULT '';
+CREATE INDEX IF NOT EXISTS withdraws_user_uid ON withdraws(user_uid);
+CREATE INDEX IF NOT EXISTS withdraws_to_address ON withdraws(to_address);
+
+
+CREATE TABLE IF NOT EXISTS risk_rules (
+    guid  VARCHAR PRIMARY KEY,
+    rule_name VARCHAR NOT NULL,
+    rule_type SMALLINT NOT NULL,
+    token_address VARCHAR NOT NULL DEFAULT '',
+    threshold UINT256 NOT NULL DEFAULT 0,
+    max_count INTEGER NOT NULL DEFAULT 0,
+    window_seconds INTEGER NOT NULL DEFAULT 0,
+    action SMALLINT NOT NULL DEFAULT 1,
+    enable BOOLEAN NOT NULL DEFAULT TRUE,
+    timestamp INTEGER NOT NULL CHECK(timestamp>0)
+);
+CREATE INDEX IF NOT EXISTS risk_rules_rule_type ON risk_rules(rule_type);
+
+
+CREATE TABLE IF NOT EXISTS risk_blocklist (
+    guid  VARCHAR PRIMARY KEY,
+    address VARCHAR NOT NULL UNIQUE,
+    reason VARCHAR NOT NULL DEFAULT '',
+    timestamp INTEGER NOT NULL CHECK(timestamp>0)
+);
+
+
+CREATE TABLE IF NOT EXISTS risk_decisions (
+    guid  VARCHAR PRIMARY KEY,
+    subject_type VARCHAR NOT NULL,
+    subject VARCHAR NOT NULL,
+    withdraw_guid VARCHAR NOT NULL DEFAULT '',
+    decision SMALLINT NOT NULL DEFAULT 0,
+    triggered_rules VARCHAR NOT NULL DEFAULT '',
+    timestamp INTEGER NOT NULL CHECK(timestamp>0)
+);
+CREATE INDEX IF NOT EXISTS risk_decisions_withdraw_guid ON risk_decisions(withdraw_guid);
+CREATE INDEX IF NOT EXISTS risk_decisions_subject ON risk_decisions(subject);
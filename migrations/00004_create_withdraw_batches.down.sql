@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS withdraws_batch_guid;
+ALTER TABLE withdraws DROP COLUMN IF EXISTS batch_guid;
+DROP TABLE IF EXISTS withdraw_batches;
@@ -0,0 +1,19 @@
+CREATE TABLE IF NOT EXISTS withdraw_batches (
+    guid  VARCHAR PRIMARY KEY,
+    block_hash VARCHAR NOT NULL,
+    block_number UINT256 NOT NULL,
+    hash VARCHAR NOT NULL,
+    from_address VARCHAR NOT NULL,
+    token_address VARCHAR NOT NULL,
+    fee UINT256 NOT NULL,
+    total_amount UINT256 NOT NULL,
+    withdraw_count INTEGER NOT NULL,
+    status SMALLINT NOT NULL DEFAULT 0,
+    tx_sign_hex VARCHAR NOT NULL,
+    timestamp INTEGER NOT NULL CHECK(timestamp>0)
+);
+CREATE INDEX IF NOT EXISTS withdraw_batches_hash ON withdraw_batches(hash);
+CREATE INDEX IF NOT EXISTS withdraw_batches_timestamp ON withdraw_batches(timestamp);
+
+ALTER TABLE withdraws ADD COLUMN IF NOT EXISTS batch_guid VARCHAR NOT NULL DEFAULT '';
+CREATE INDEX IF NOT EXISTS withdraws_batch_guid ON withdraws(batch_guid);
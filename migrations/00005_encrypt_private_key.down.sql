@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS addresses_key_id;
+ALTER TABLE addresses DROP COLUMN IF EXISTS key_id;
+ALTER TABLE addresses DROP COLUMN IF EXISTS data_key;
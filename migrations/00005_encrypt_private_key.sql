@@ -0,0 +1,3 @@
+ALTER TABLE addresses ADD COLUMN IF NOT EXISTS data_key VARCHAR NOT NULL DEFAULT '';
+ALTER TABLE addresses ADD COLUMN IF NOT EXISTS key_id VARCHAR NOT NULL DEFAULT '';
+CREATE INDEX IF NOT EXISTS addresses_key_id ON addresses(key_id);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS addresses_derivation_index;
+ALTER TABLE addresses DROP COLUMN IF EXISTS derivation_index;
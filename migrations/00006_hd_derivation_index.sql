@@ -0,0 +1,2 @@
+ALTER TABLE addresses ADD COLUMN IF NOT EXISTS derivation_index BIGINT;
+CREATE UNIQUE INDEX IF NOT EXISTS addresses_derivation_index ON addresses(derivation_index) WHERE derivation_index IS NOT NULL;
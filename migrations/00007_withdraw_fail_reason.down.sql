@@ -0,0 +1 @@
+ALTER TABLE withdraws DROP COLUMN IF EXISTS fail_reason;
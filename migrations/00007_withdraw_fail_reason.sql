@@ -0,0 +1 @@
+ALTER TABLE withdraws ADD COLUMN IF NOT EXISTS fail_reason VARCHAR NOT NULL DEFAULT '';
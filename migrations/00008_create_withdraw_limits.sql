@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS withdraw_limits (
+    guid  VARCHAR PRIMARY KEY,
+    limit_name VARCHAR NOT NULL,
+    user_uid VARCHAR NOT NULL DEFAULT '',
+    token_address VARCHAR NOT NULL DEFAULT '',
+    single_max UINT256 NOT NULL DEFAULT 0,
+    daily_amount UINT256 NOT NULL DEFAULT 0,
+    daily_count INTEGER NOT NULL DEFAULT 0,
+    enable BOOLEAN NOT NULL DEFAULT TRUE,
+    timestamp INTEGER NOT NULL CHECK(timestamp>0)
+);
+CREATE INDEX IF NOT EXISTS withdraw_limits_user_uid ON withdraw_limits(user_uid);
+CREATE INDEX IF NOT EXISTS withdraw_limits_token_address ON withdraw_limits(token_address);
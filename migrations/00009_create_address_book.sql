@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS address_book (
+    guid  VARCHAR PRIMARY KEY,
+    user_uid VARCHAR NOT NULL DEFAULT '',
+    address VARCHAR NOT NULL,
+    label VARCHAR NOT NULL DEFAULT '',
+    effective_time INTEGER NOT NULL,
+    timestamp INTEGER NOT NULL CHECK(timestamp>0)
+);
+CREATE UNIQUE INDEX IF NOT EXISTS address_book_user_address ON address_book(user_uid, address);
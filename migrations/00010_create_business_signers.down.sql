@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS withdraws_sign_digest;
+ALTER TABLE withdraws DROP COLUMN IF EXISTS sign_digest;
+DROP TABLE IF EXISTS business_signers;
@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS business_signers (
+    guid  VARCHAR PRIMARY KEY,
+    consumer_token VARCHAR NOT NULL,
+    signer_address VARCHAR NOT NULL,
+    enable BOOLEAN NOT NULL DEFAULT TRUE,
+    timestamp INTEGER NOT NULL CHECK(timestamp>0)
+);
+CREATE UNIQUE INDEX IF NOT EXISTS business_signers_consumer_signer ON business_signers(consumer_token, signer_address);
+
+ALTER TABLE withdraws ADD COLUMN IF NOT EXISTS sign_digest VARCHAR NOT NULL DEFAULT '';
+CREATE UNIQUE INDEX IF NOT EXISTS withdraws_sign_digest ON withdraws(sign_digest) WHERE sign_digest <> '';
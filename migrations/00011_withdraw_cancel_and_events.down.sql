@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS withdraw_events;
+ALTER TABLE withdraws DROP COLUMN IF EXISTS cancel_tx_hex;
@@ -0,0 +1,12 @@
+ALTER TABLE withdraws ADD COLUMN IF NOT EXISTS cancel_tx_hex VARCHAR NOT NULL DEFAULT '';
+
+CREATE TABLE IF NOT EXISTS withdraw_events (
+    guid  VARCHAR PRIMARY KEY,
+    withdraw_guid VARCHAR NOT NULL,
+    event VARCHAR NOT NULL,
+    from_status SMALLINT NOT NULL DEFAULT 0,
+    to_status SMALLINT NOT NULL DEFAULT 0,
+    detail VARCHAR NOT NULL DEFAULT '',
+    timestamp INTEGER NOT NULL CHECK(timestamp>0)
+);
+CREATE INDEX IF NOT EXISTS withdraw_events_withdraw_guid ON withdraw_events(withdraw_guid);
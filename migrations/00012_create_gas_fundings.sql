@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS gas_fundings (
+    guid  VARCHAR PRIMARY KEY,
+    address VARCHAR NOT NULL,
+    token_address VARCHAR NOT NULL,
+    funding_hash VARCHAR NOT NULL,
+    funding_amount UINT256 NOT NULL,
+    collection_hash VARCHAR NOT NULL DEFAULT '',
+    status SMALLINT NOT NULL DEFAULT 0,
+    timestamp INTEGER NOT NULL CHECK(timestamp>0)
+);
+CREATE INDEX IF NOT EXISTS gas_fundings_address_token ON gas_fundings(address, token_address);
+CREATE INDEX IF NOT EXISTS gas_fundings_funding_hash ON gas_fundings(funding_hash);
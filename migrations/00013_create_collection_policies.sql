@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS collection_policies (
+    guid  VARCHAR PRIMARY KEY,
+    token_address VARCHAR NOT NULL DEFAULT '',
+    threshold UINT256 NOT NULL DEFAULT 0,
+    window_start SMALLINT NOT NULL DEFAULT 0,
+    window_end SMALLINT NOT NULL DEFAULT 0,
+    max_gas_price UINT256 NOT NULL DEFAULT 0,
+    min_value_fee_ratio INTEGER NOT NULL DEFAULT 0,
+    batch_size INTEGER NOT NULL DEFAULT 0,
+    enable BOOLEAN NOT NULL DEFAULT TRUE,
+    timestamp INTEGER NOT NULL CHECK(timestamp>0)
+);
+CREATE UNIQUE INDEX IF NOT EXISTS collection_policies_token_address ON collection_policies(token_address);
@@ -0,0 +1,32 @@
+CREATE TABLE IF NOT EXISTS hot_wallet_bands (
+    guid  VARCHAR PRIMARY KEY,
+    token_address VARCHAR NOT NULL,
+    low_watermark UINT256 NOT NULL,
+    target UINT256 NOT NULL,
+    high_watermark UINT256 NOT NULL,
+    enable BOOLEAN NOT NULL DEFAULT TRUE,
+    timestamp INTEGER NOT NULL CHECK(timestamp>0)
+);
+CREATE UNIQUE INDEX IF NOT EXISTS hot_wallet_bands_token_address ON hot_wallet_bands(token_address);
+
+CREATE TABLE IF NOT EXISTS rebalances (
+    guid  VARCHAR PRIMARY KEY,
+    direction SMALLINT NOT NULL,
+    hot_wallet VARCHAR NOT NULL,
+    from_address VARCHAR NOT NULL,
+    to_address VARCHAR NOT NULL,
+    token_address VARCHAR NOT NULL,
+    amount UINT256 NOT NULL,
+    status SMALLINT NOT NULL DEFAULT 0,
+    hash VARCHAR NOT NULL DEFAULT '',
+    block_number UINT256,
+    fee UINT256 NOT NULL DEFAULT 0,
+    tx_sign_hex VARCHAR NOT NULL DEFAULT '',
+    operator VARCHAR NOT NULL DEFAULT '',
+    reason VARCHAR NOT NULL DEFAULT '',
+    timestamp INTEGER NOT NULL CHECK(timestamp>0),
+    updated INTEGER NOT NULL DEFAULT 0
+);
+CREATE INDEX IF NOT EXISTS rebalances_hot_wallet_token ON rebalances(hot_wallet, token_address);
+CREATE INDEX IF NOT EXISTS rebalances_hash ON rebalances(hash);
+CREATE INDEX IF NOT EXISTS rebalances_status ON rebalances(status);
@@ -0,0 +1 @@
+ALTER TABLE rebalances DROP COLUMN IF EXISTS unsigned_tx;
@@ -0,0 +1 @@
+ALTER TABLE rebalances ADD COLUMN IF NOT EXISTS unsigned_tx VARCHAR NOT NULL DEFAULT '';
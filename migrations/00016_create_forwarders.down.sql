@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS forwarder_sweeps;
+DROP INDEX IF EXISTS addresses_forwarder_salt;
+ALTER TABLE addresses DROP COLUMN IF EXISTS forwarder_salt;
@@ -0,0 +1,17 @@
+ALTER TABLE addresses ADD COLUMN IF NOT EXISTS forwarder_salt VARCHAR NOT NULL DEFAULT '';
+CREATE UNIQUE INDEX IF NOT EXISTS addresses_forwarder_salt ON addresses(forwarder_salt) WHERE forwarder_salt <> '';
+
+CREATE TABLE IF NOT EXISTS forwarder_sweeps (
+    guid  VARCHAR PRIMARY KEY,
+    hash VARCHAR NOT NULL,
+    hot_wallet VARCHAR NOT NULL,
+    forwarder_address VARCHAR NOT NULL,
+    token_address VARCHAR NOT NULL,
+    amount UINT256 NOT NULL,
+    fee UINT256 NOT NULL,
+    block_number UINT256 NOT NULL,
+    status SMALLINT NOT NULL DEFAULT 0,
+    timestamp INTEGER NOT NULL CHECK(timestamp>0)
+);
+CREATE INDEX IF NOT EXISTS forwarder_sweeps_hash ON forwarder_sweeps(hash);
+CREATE INDEX IF NOT EXISTS forwarder_sweeps_forwarder_address ON forwarder_sweeps(forwarder_address);
@@ -0,0 +1,30 @@
+CREATE TABLE IF NOT EXISTS ledger_entries (
+    guid  VARCHAR PRIMARY KEY,
+    journal_guid VARCHAR NOT NULL,
+    address VARCHAR NOT NULL,
+    address_type SMALLINT NOT NULL DEFAULT 0,
+    bucket VARCHAR NOT NULL,
+    token_address VARCHAR NOT NULL,
+    debit UINT256 NOT NULL DEFAULT 0,
+    credit UINT256 NOT NULL DEFAULT 0,
+    reason VARCHAR NOT NULL,
+    source_hash VARCHAR NOT NULL,
+    timestamp INTEGER NOT NULL CHECK(timestamp>0),
+    CHECK(debit = 0 OR credit = 0)
+);
+CREATE INDEX IF NOT EXISTS ledger_entries_journal_guid ON ledger_entries(journal_guid);
+CREATE INDEX IF NOT EXISTS ledger_entries_account ON ledger_entries(address, token_address, bucket);
+CREATE INDEX IF NOT EXISTS ledger_entries_source_hash ON ledger_entries(source_hash);
+
+-- 期初分录：账本为空时把已有余额记为外部转入，只执行一次
+INSERT INTO ledger_entries (guid, journal_guid, address, address_type, bucket, token_address, debit, credit, reason, source_hash, timestamp)
+SELECT md5(random()::text || clock_timestamp()::text)::uuid::text, '00000000-0000-0000-0000-000000000000', opening.address, opening.address_type, opening.bucket, opening.token_address, opening.debit, opening.credit, 'opening',
+       '0x0000000000000000000000000000000000000000000000000000000000000000', extract(epoch from now())::integer
+FROM (
+    SELECT address, address_type, 'available' AS bucket, token_address, balance AS debit, 0 AS credit FROM balances WHERE balance > 0
+    UNION ALL
+    SELECT address, address_type, 'locked', token_address, lock_balance, 0 FROM balances WHERE lock_balance > 0
+    UNION ALL
+    SELECT '0x0000000000000000000000000000000000000000', 0, 'external', token_address, 0, SUM(balance + lock_balance) FROM balances GROUP BY token_address HAVING SUM(balance + lock_balance) > 0
+) AS opening
+WHERE NOT EXISTS (SELECT 1 FROM ledger_entries);
@@ -0,0 +1,26 @@
+CREATE TABLE IF NOT EXISTS reconciliations (
+    guid  VARCHAR PRIMARY KEY,
+    block_number UINT256 NOT NULL,
+    checked INTEGER NOT NULL DEFAULT 0,
+    mismatched INTEGER NOT NULL DEFAULT 0,
+    alerted INTEGER NOT NULL DEFAULT 0,
+    timestamp INTEGER NOT NULL CHECK(timestamp>0)
+);
+CREATE INDEX IF NOT EXISTS reconciliations_timestamp ON reconciliations(timestamp);
+
+CREATE TABLE IF NOT EXISTS reconciliation_discrepancies (
+    guid  VARCHAR PRIMARY KEY,
+    reconciliation_guid VARCHAR NOT NULL,
+    address VARCHAR NOT NULL,
+    address_type SMALLINT NOT NULL DEFAULT 0,
+    token_address VARCHAR NOT NULL,
+    block_number UINT256 NOT NULL,
+    chain_balance UINT256 NOT NULL,
+    balance UINT256 NOT NULL,
+    lock_balance UINT256 NOT NULL,
+    tolerance UINT256 NOT NULL,
+    alert BOOLEAN NOT NULL DEFAULT FALSE,
+    timestamp INTEGER NOT NULL CHECK(timestamp>0)
+);
+CREATE INDEX IF NOT EXISTS reconciliation_discrepancies_reconciliation_guid ON reconciliation_discrepancies(reconciliation_guid);
+CREATE INDEX IF NOT EXISTS reconciliation_discrepancies_address ON reconciliation_discrepancies(address, token_address);
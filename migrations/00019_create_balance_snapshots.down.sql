@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS ledger_entries_timestamp;
+DROP TABLE IF EXISTS balance_snapshots;
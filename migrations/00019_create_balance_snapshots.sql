@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS balance_snapshots (
+    guid  VARCHAR PRIMARY KEY,
+    address VARCHAR NOT NULL,
+    address_type SMALLINT NOT NULL DEFAULT 0,
+    token_address VARCHAR NOT NULL,
+    balance UINT256 NOT NULL,
+    lock_balance UINT256 NOT NULL,
+    block_number UINT256 NOT NULL,
+    block_timestamp INTEGER NOT NULL,
+    timestamp INTEGER NOT NULL CHECK(timestamp>0)
+);
+CREATE UNIQUE INDEX IF NOT EXISTS balance_snapshots_account_block ON balance_snapshots(address, token_address, block_number);
+CREATE INDEX IF NOT EXISTS balance_snapshots_account_block_timestamp ON balance_snapshots(address, token_address, block_timestamp);
+
+CREATE INDEX IF NOT EXISTS ledger_entries_timestamp ON ledger_entries(timestamp);
@@ -0,0 +1,2 @@
+-- 合并的重复余额行无法拆分，只移除唯一索引
+DROP INDEX IF EXISTS balances_address_token_address;
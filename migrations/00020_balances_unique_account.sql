@@ -0,0 +1,17 @@
+-- 同一地址和币种只保留一行余额，重复行合并到 guid 最小的一行
+UPDATE balances SET balance = merged.balance, lock_balance = merged.lock_balance
+FROM (
+    SELECT address, token_address, min(guid) AS guid, sum(balance) AS balance, sum(lock_balance) AS lock_balance
+    FROM balances GROUP BY address, token_address HAVING count(*) > 1
+) AS merged
+WHERE balances.guid = merged.guid;
+
+DELETE FROM balances
+USING (
+    SELECT address, token_address, min(guid) AS guid
+    FROM balances GROUP BY address, token_address HAVING count(*) > 1
+) AS kept
+WHERE balances.address = kept.address AND balances.token_address = kept.token_address AND balances.guid <> kept.guid;
+
+-- 余额非负由 UINT256 约束保证
+CREATE UNIQUE INDEX IF NOT EXISTS balances_address_token_address ON balances(address, token_address);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS reserve_proofs;
+DROP TABLE IF EXISTS reserve_reports;
@@ -0,0 +1,25 @@
+CREATE TABLE IF NOT EXISTS reserve_reports (
+    guid  VARCHAR PRIMARY KEY,
+    token_address VARCHAR NOT NULL,
+    block_number UINT256 NOT NULL,
+    root VARCHAR NOT NULL,
+    total_liabilities UINT256 NOT NULL,
+    onchain_holdings UINT256 NOT NULL,
+    user_count INTEGER NOT NULL DEFAULT 0,
+    timestamp INTEGER NOT NULL CHECK(timestamp>0)
+);
+CREATE INDEX IF NOT EXISTS reserve_reports_token_address ON reserve_reports(token_address, timestamp);
+
+CREATE TABLE IF NOT EXISTS reserve_proofs (
+    guid  VARCHAR PRIMARY KEY,
+    report_guid VARCHAR NOT NULL,
+    user_uid VARCHAR NOT NULL,
+    token_address VARCHAR NOT NULL,
+    leaf_index INTEGER NOT NULL,
+    salt VARCHAR NOT NULL,
+    balance UINT256 NOT NULL,
+    proof TEXT NOT NULL,
+    timestamp INTEGER NOT NULL CHECK(timestamp>0)
+);
+CREATE INDEX IF NOT EXISTS reserve_proofs_report_guid ON reserve_proofs(report_guid);
+CREATE INDEX IF NOT EXISTS reserve_proofs_user_uid ON reserve_proofs(user_uid, token_address);
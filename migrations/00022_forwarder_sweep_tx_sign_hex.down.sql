@@ -0,0 +1 @@
+ALTER TABLE forwarder_sweeps DROP COLUMN IF EXISTS tx_sign_hex;
@@ -0,0 +1 @@
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS tx_sign_hex VARCHAR NOT NULL DEFAULT '';
@@ -0,0 +1 @@
+ALTER TABLE withdraw_limits DROP CONSTRAINT IF EXISTS withdraw_limits_amount_token;
@@ -0,0 +1,2 @@
+-- 金额限制只对单一币种有意义，不指定币种的限额只能限制笔数；NOT VALID 只约束新写入的记录，已有记录由加载时校验拒绝
+ALTER TABLE withdraw_limits ADD CONSTRAINT withdraw_limits_amount_token CHECK (token_address <> '' OR (single_max = 0 AND daily_amount = 0)) NOT VALID;
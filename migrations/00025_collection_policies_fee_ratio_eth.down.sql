@@ -0,0 +1 @@
+ALTER TABLE collection_policies DROP CONSTRAINT IF EXISTS collection_policies_fee_ratio_eth;
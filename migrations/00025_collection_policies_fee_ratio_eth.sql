@@ -0,0 +1,2 @@
+-- 代币归集金额与 ETH 手续费单位不同，价值手续费比只能配置在 ETH 和默认策略上；NOT VALID 只约束新写入的记录，已有记录由加载时校验拒绝
+ALTER TABLE collection_policies ADD CONSTRAINT collection_policies_fee_ratio_eth CHECK (min_value_fee_ratio = 0 OR token_address = '' OR lower(token_address) = '0x0000000000000000000000000000000000000000') NOT VALID;
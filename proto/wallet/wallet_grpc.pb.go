@@ -25,6 +25,12 @@ const (
 	WalletService_VerifyAddress_FullMethodName        = "/services.thewebthree.wallet.WalletService/verifyAddress"
 	WalletService_VerifyWithdrawSign_FullMethodName   = "/services.thewebthree.wallet.WalletService/verifyWithdrawSign"
 	WalletService_VerifyRiskDOrWNotify_FullMethodName = "/services.thewebthree.wallet.WalletService/verifyRiskDOrWNotify"
+	WalletService_AddAddressBook_FullMethodName       = "/services.thewebthree.wallet.WalletService/addAddressBook"
+	WalletService_RemoveAddressBook_FullMethodName    = "/services.thewebthree.wallet.WalletService/removeAddressBook"
+	WalletService_ListAddressBook_FullMethodName      = "/services.thewebthree.wallet.WalletService/listAddressBook"
+	WalletService_CancelWithdraw_FullMethodName       = "/services.thewebthree.wallet.WalletService/cancelWithdraw"
+	WalletService_AmendWithdraw_FullMethodName        = "/services.thewebthree.wallet.WalletService/amendWithdraw"
+	WalletService_GetWithdrawDetail_FullMethodName    = "/services.thewebthree.wallet.WalletService/getWithdrawDetail"
 )
 
 // WalletServiceClient is the client API for WalletService service.
@@ -37,6 +43,12 @@ type WalletServiceClient interface {
 	VerifyAddress(ctx context.Context, in *RiskVerifyAddressReq, opts ...grpc.CallOption) (*RiskVerifyAddressRep, error)
 	VerifyWithdrawSign(ctx context.Context, in *RiskWithdrawVerifyReq, opts ...grpc.CallOption) (*RiskWithdrawVerifyRep, error)
 	VerifyRiskDOrWNotify(ctx context.Context, in *RiskDOrWNotifyVerifyReq, opts ...grpc.CallOption) (*RiskDOrWNotifyVerifyRep, error)
+	AddAddressBook(ctx context.Context, in *AddressBookReq, opts ...grpc.CallOption) (*AddressBookRep, error)
+	RemoveAddressBook(ctx context.Context, in *AddressBookReq, opts ...grpc.CallOption) (*AddressBookRep, error)
+	ListAddressBook(ctx context.Context, in *ListAddressBookReq, opts ...grpc.CallOption) (*ListAddressBookRep, error)
+	CancelWithdraw(ctx context.Context, in *CancelWithdrawReq, opts ...grpc.CallOption) (*CancelWithdrawRep, error)
+	AmendWithdraw(ctx context.Context, in *AmendWithdrawReq, opts ...grpc.CallOption) (*AmendWithdrawRep, error)
+	GetWithdrawDetail(ctx context.Context, in *WithdrawDetailReq, opts ...grpc.CallOption) (*WithdrawDetailRep, error)
 }
 
 type walletServiceClient struct {
@@ -101,6 +113,60 @@ func (c *walletServiceClient) VerifyRiskDOrWNotify(ctx context.Context, in *Risk
 	return out, nil
 }
 
+func (c *walletServiceClient) AddAddressBook(ctx context.Context, in *AddressBookReq, opts ...grpc.CallOption) (*AddressBookRep, error) {
+	out := new(AddressBookRep)
+	err := c.cc.Invoke(ctx, WalletService_AddAddressBook_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *walletServiceClient) RemoveAddressBook(ctx context.Context, in *AddressBookReq, opts ...grpc.CallOption) (*AddressBookRep, error) {
+	out := new(AddressBookRep)
+	err := c.cc.Invoke(ctx, WalletService_RemoveAddressBook_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *walletServiceClient) ListAddressBook(ctx context.Context, in *ListAddressBookReq, opts ...grpc.CallOption) (*ListAddressBookRep, error) {
+	out := new(ListAddressBookRep)
+	err := c.cc.Invoke(ctx, WalletService_ListAddressBook_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *walletServiceClient) CancelWithdraw(ctx context.Context, in *CancelWithdrawReq, opts ...grpc.CallOption) (*CancelWithdrawRep, error) {
+	out := new(CancelWithdrawRep)
+	err := c.cc.Invoke(ctx, WalletService_CancelWithdraw_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *walletServiceClient) AmendWithdraw(ctx context.Context, in *AmendWithdrawReq, opts ...grpc.CallOption) (*AmendWithdrawRep, error) {
+	out := new(AmendWithdrawRep)
+	err := c.cc.Invoke(ctx, WalletService_AmendWithdraw_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *walletServiceClient) GetWithdrawDetail(ctx context.Context, in *WithdrawDetailReq, opts ...grpc.CallOption) (*WithdrawDetailRep, error) {
+	out := new(WithdrawDetailRep)
+	err := c.cc.Invoke(ctx, WalletService_GetWithdrawDetail_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // WalletServiceServer is the server API for WalletService service.
 // All implementations must embed UnimplementedWalletServiceServer
 // for forward compatibility
@@ -111,6 +177,12 @@ type WalletServiceServer interface {
 	VerifyAddress(context.Context, *RiskVerifyAddressReq) (*RiskVerifyAddressRep, error)
 	VerifyWithdrawSign(context.Context, *RiskWithdrawVerifyReq) (*RiskWithdrawVerifyRep, error)
 	VerifyRiskDOrWNotify(context.Context, *RiskDOrWNotifyVerifyReq) (*RiskDOrWNotifyVerifyRep, error)
+	AddAddressBook(context.Context, *AddressBookReq) (*AddressBookRep, error)
+	RemoveAddressBook(context.Context, *AddressBookReq) (*AddressBookRep, error)
+	ListAddressBook(context.Context, *ListAddressBookReq) (*ListAddressBookRep, error)
+	CancelWithdraw(context.Context, *CancelWithdrawReq) (*CancelWithdrawRep, error)
+	AmendWithdraw(context.Context, *AmendWithdrawReq) (*AmendWithdrawRep, error)
+	GetWithdrawDetail(context.Context, *WithdrawDetailReq) (*WithdrawDetailRep, error)
 	mustEmbedUnimplementedWalletServiceServer()
 }
 
@@ -136,6 +208,24 @@ func (UnimplementedWalletServiceServer) VerifyWithdrawSign(context.Context, *Ris
 func (UnimplementedWalletServiceServer) VerifyRiskDOrWNotify(context.Context, *RiskDOrWNotifyVerifyReq) (*RiskDOrWNotifyVerifyRep, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method VerifyRiskDOrWNotify not implemented")
 }
+func (UnimplementedWalletServiceServer) AddAddressBook(context.Context, *AddressBookReq) (*AddressBookRep, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AddAddressBook not implemented")
+}
+func (UnimplementedWalletServiceServer) RemoveAddressBook(context.Context, *AddressBookReq) (*AddressBookRep, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RemoveAddressBook not implemented")
+}
+func (UnimplementedWalletServiceServer) ListAddressBook(context.Context, *ListAddressBookReq) (*ListAddressBookRep, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListAddressBook not implemented")
+}
+func (UnimplementedWalletServiceServer) CancelWithdraw(context.Context, *CancelWithdrawReq) (*CancelWithdrawRep, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CancelWithdraw not implemented")
+}
+func (UnimplementedWalletServiceServer) AmendWithdraw(context.Context, *AmendWithdrawReq) (*AmendWithdrawRep, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AmendWithdraw not implemented")
+}
+func (UnimplementedWalletServiceServer) GetWithdrawDetail(context.Context, *WithdrawDetailReq) (*WithdrawDetailRep, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetWithdrawDetail not implemented")
+}
 func (UnimplementedWalletServiceServer) mustEmbedUnimplementedWalletServiceServer() {}
 
 // UnsafeWalletServiceServer may be embedded to opt out of forward compatibility for this service.
@@ -257,6 +347,114 @@ func _WalletService_VerifyRiskDOrWNotify_Handler(srv interface{}, ctx context.Co
 	return interceptor(ctx, in, info, handler)
 }
 
+func _WalletService_AddAddressBook_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AddressBookReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WalletServiceServer).AddAddressBook(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: WalletService_AddAddressBook_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WalletServiceServer).AddAddressBook(ctx, req.(*AddressBookReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WalletService_RemoveAddressBook_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AddressBookReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WalletServiceServer).RemoveAddressBook(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: WalletService_RemoveAddressBook_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WalletServiceServer).RemoveAddressBook(ctx, req.(*AddressBookReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WalletService_ListAddressBook_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListAddressBookReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WalletServiceServer).ListAddressBook(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: WalletService_ListAddressBook_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WalletServiceServer).ListAddressBook(ctx, req.(*ListAddressBookReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WalletService_CancelWithdraw_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CancelWithdrawReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WalletServiceServer).CancelWithdraw(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: WalletService_CancelWithdraw_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WalletServiceServer).CancelWithdraw(ctx, req.(*CancelWithdrawReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WalletService_AmendWithdraw_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AmendWithdrawReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WalletServiceServer).AmendWithdraw(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: WalletService_AmendWithdraw_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WalletServiceServer).AmendWithdraw(ctx, req.(*AmendWithdrawReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WalletService_GetWithdrawDetail_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(WithdrawDetailReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WalletServiceServer).GetWithdrawDetail(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: WalletService_GetWithdrawDetail_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WalletServiceServer).GetWithdrawDetail(ctx, req.(*WithdrawDetailReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // WalletService_ServiceDesc is the grpc.ServiceDesc for WalletService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -288,6 +486,30 @@ var WalletService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "verifyRiskDOrWNotify",
 			Handler:    _WalletService_VerifyRiskDOrWNotify_Handler,
 		},
+		{
+			MethodName: "addAddressBook",
+			Handler:    _WalletService_AddAddressBook_Handler,
+		},
+		{
+			MethodName: "removeAddressBook",
+			Handler:    _WalletService_RemoveAddressBook_Handler,
+		},
+		{
+			MethodName: "listAddressBook",
+			Handler:    _WalletService_ListAddressBook_Handler,
+		},
+		{
+			MethodName: "cancelWithdraw",
+			Handler:    _WalletService_CancelWithdraw_Handler,
+		},
+		{
+			MethodName: "amendWithdraw",
+			Handler:    _WalletService_AmendWithdraw_Handler,
+		},
+		{
+			MethodName: "getWithdrawDetail",
+			Handler:    _WalletService_GetWithdrawDetail_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "rpc/wallet.proto",
@@ -0,0 +1,55 @@
+package risk
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/the-web3/eth-wallet/database"
+)
+
+var ErrDestinationNotAllowed = errors.New("withdraw destination not allowed")
+
+// checkDestination 目标地址必须已登记且过了冷却期
+func checkDestination(entry *database.AddressBook, address common.Address, now uint64) error {
+	if entry == nil {
+		return fmt.Errorf("%w: %s is not in address book", ErrDestinationNotAllowed, address.String())
+	}
+	if now < entry.EffectiveTime {
+		return fmt.Errorf("%w: %s is cooling until %d", ErrDestinationNotAllowed, address.String(), entry.EffectiveTime)
+	}
+	return nil
+}
+
+// CheckWithdrawDestination 校验提现目标地址在用户或业务方的地址簿中且已过冷却期
+func (e *Engine) CheckWithdrawDestination(userUid string, toAddress common.Address) error {
+	entry, err := e.db.AddressBook.QueryWithdrawDestination(userUid, toAddress)
+	if err != nil {
+		log.Error("query address book fail", "err", err)
+		return err
+	}
+	return checkDestination(entry, toAddress, uint64(time.Now().Unix()))
+}
+
+// AddAddressBookEntry 登记提现目标地址，新地址需等待冷却期后才能收款
+func (e *Engine) AddAddressBookEntry(userUid string, address common.Address, label string, coolingPeriod time.Duration) (*database.AddressBook, error) {
+	now := uint64(time.Now().Unix())
+	return e.db.AddressBook.StoreAddressBook(database.AddressBook{
+		GUID:          uuid.New(),
+		UserUid:       userUid,
+		Address:       address,
+		Label:         label,
+		EffectiveTime: now + uint64(coolingPeriod.Seconds()),
+		Timestamp:     now,
+	})
+}
+
+// RemoveAddressBookEntry 删除登记的地址，返回是否存在该地址
+func (e *Engine) RemoveAddressBookEntry(userUid string, address common.Address) (bool, error) {
+	return e.db.AddressBook.RemoveAddressBook(userUid, address)
+}
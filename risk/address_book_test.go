@@ -0,0 +1,27 @@
+package risk
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/the-web3/eth-wallet/database"
+)
+
+func TestCheckDestination(t *testing.T) {
+	address := common.HexToAddress("0x02")
+
+	err := checkDestination(nil, address, 1000)
+	require.True(t, errors.Is(err, ErrDestinationNotAllowed))
+	require.Contains(t, err.Error(), "not in address book")
+
+	entry := &database.AddressBook{Address: address, EffectiveTime: 2000}
+	err = checkDestination(entry, address, 1999)
+	require.True(t, errors.Is(err, ErrDestinationNotAllowed))
+	require.Contains(t, err.Error(), "cooling until 2000")
+
+	require.NoError(t, checkDestination(entry, address, 2000))
+}
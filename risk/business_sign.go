@@ -0,0 +1,127 @@
+package risk
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+)
+
+const (
+	WithdrawDomainName    = "EthWallet"
+	WithdrawDomainVersion = "1"
+	withdrawPrimaryType   = "Withdraw"
+)
+
+var ErrWithdrawUnauthorized = errors.New("withdraw signature unauthorized")
+
+var withdrawTypes = apitypes.Types{
+	"EIP712Domain": {
+		{Name: "name", Type: "string"},
+		{Name: "version", Type: "string"},
+		{Name: "chainId", Type: "uint256"},
+	},
+	withdrawPrimaryType: {
+		{Name: "requestId", Type: "string"},
+		{Name: "from", Type: "address"},
+		{Name: "to", Type: "address"},
+		{Name: "token", Type: "address"},
+		{Name: "amount", Type: "uint256"},
+		{Name: "deadline", Type: "uint256"},
+	},
+}
+
+// WithdrawAuthorization 业务方对提现请求的 EIP-712 签名授权
+type WithdrawAuthorization struct {
+	ConsumerToken string
+	RequestId     string
+	FromAddress   common.Address
+	ToAddress     common.Address
+	TokenAddress  common.Address
+	Amount        *big.Int
+	Deadline      uint64
+	Signature     []byte
+}
+
+// WithdrawTypedData 构造提现请求的 EIP-712 结构化数据，chainId 放在 domain 中
+func WithdrawTypedData(auth *WithdrawAuthorization, chainId *big.Int) apitypes.TypedData {
+	return apitypes.TypedData{
+		Types:       withdrawTypes,
+		PrimaryType: withdrawPrimaryType,
+		Domain: apitypes.TypedDataDomain{
+			Name:    WithdrawDomainName,
+			Version: WithdrawDomainVersion,
+			ChainId: (*math.HexOrDecimal256)(chainId),
+		},
+		Message: apitypes.TypedDataMessage{
+			"requestId": auth.RequestId,
+			"from":      auth.FromAddress.String(),
+			"to":        auth.ToAddress.String(),
+			"token":     auth.TokenAddress.String(),
+			"amount":    (*math.HexOrDecimal256)(auth.Amount),
+			"deadline":  (*math.HexOrDecimal256)(new(big.Int).SetUint64(auth.Deadline)),
+		},
+	}
+}
+
+// WithdrawSignHash 返回业务方需要签名的 EIP-712 摘要
+func WithdrawSignHash(auth *WithdrawAuthorization, chainId *big.Int) (common.Hash, error) {
+	hash, _, err := apitypes.TypedDataAndHash(WithdrawTypedData(auth, chainId))
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return common.BytesToHash(hash), nil
+}
+
+// recoverWithdrawSigner 校验有效期并恢复签名地址，签名的 v 兼容 0/1 和 27/28
+func recoverWithdrawSigner(auth *WithdrawAuthorization, chainId *big.Int, now uint64) (common.Address, common.Hash, error) {
+	if auth.Deadline < now {
+		return common.Address{}, common.Hash{}, fmt.Errorf("%w: request expired at %d", ErrWithdrawUnauthorized, auth.Deadline)
+	}
+	if len(auth.Signature) != crypto.SignatureLength {
+		return common.Address{}, common.Hash{}, fmt.Errorf("%w: invalid signature length %d", ErrWithdrawUnauthorized, len(auth.Signature))
+	}
+	digest, err := WithdrawSignHash(auth, chainId)
+	if err != nil {
+		return common.Address{}, common.Hash{}, fmt.Errorf("%w: %v", ErrWithdrawUnauthorized, err)
+	}
+	sig := common.CopyBytes(auth.Signature)
+	if sig[crypto.RecoveryIDOffset] >= 27 {
+		sig[crypto.RecoveryIDOffset] -= 27
+	}
+	pubKey, err := crypto.SigToPub(digest.Bytes(), sig)
+	if err != nil {
+		return common.Address{}, common.Hash{}, fmt.Errorf("%w: %v", ErrWithdrawUnauthorized, err)
+	}
+	return crypto.PubkeyToAddress(*pubKey), digest, nil
+}
+
+// VerifyWithdrawAuthorization 校验签名来自该业务方登记的签名地址、未过期且未被其它请求使用过，返回签名摘要
+func (e *Engine) VerifyWithdrawAuthorization(auth *WithdrawAuthorization, chainId *big.Int) (string, error) {
+	signerAddress, digest, err := recoverWithdrawSigner(auth, chainId, uint64(time.Now().Unix()))
+	if err != nil {
+		return "", err
+	}
+	signer, err := e.db.BusinessSigners.QueryEnableBusinessSigner(auth.ConsumerToken, signerAddress)
+	if err != nil {
+		log.Error("query business signer fail", "err", err)
+		return "", err
+	}
+	if signer == nil {
+		return "", fmt.Errorf("%w: signer %s is not registered", ErrWithdrawUnauthorized, signerAddress.String())
+	}
+	existWithdraw, err := e.db.Withdraws.QueryWithdrawsBySignDigest(digest.String())
+	if err != nil {
+		return "", err
+	}
+	if existWithdraw != nil && (existWithdraw.ConsumerToken != auth.ConsumerToken || existWithdraw.RequestId != auth.RequestId) {
+		return "", fmt.Errorf("%w: signature already used by request %s", ErrWithdrawUnauthorized, existWithdraw.RequestId)
+	}
+	return digest.String(), nil
+}
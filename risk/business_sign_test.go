@@ -0,0 +1,68 @@
+package risk
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func signedAuthorization(t *testing.T, chainId *big.Int) (*WithdrawAuthorization, common.Address) {
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	auth := &WithdrawAuthorization{
+		ConsumerToken: "business",
+		RequestId:     "11111",
+		FromAddress:   common.HexToAddress("0x01"),
+		ToAddress:     common.HexToAddress("0x02"),
+		TokenAddress:  common.HexToAddress("0x03"),
+		Amount:        big.NewInt(1000),
+		Deadline:      2000,
+	}
+	digest, err := WithdrawSignHash(auth, chainId)
+	require.NoError(t, err)
+	auth.Signature, err = crypto.Sign(digest.Bytes(), key)
+	require.NoError(t, err)
+	return auth, crypto.PubkeyToAddress(key.PublicKey)
+}
+
+func TestRecoverWithdrawSigner(t *testing.T) {
+	chainId := big.NewInt(1)
+	auth, signerAddress := signedAuthorization(t, chainId)
+
+	recovered, digest, err := recoverWithdrawSigner(auth, chainId, 1000)
+	require.NoError(t, err)
+	require.Equal(t, signerAddress, recovered)
+	require.NotEqual(t, common.Hash{}, digest)
+
+	// 钱包常用 27/28 作为 v
+	auth.Signature[crypto.RecoveryIDOffset] += 27
+	recovered, _, err = recoverWithdrawSigner(auth, chainId, 1000)
+	require.NoError(t, err)
+	require.Equal(t, signerAddress, recovered)
+
+	// 篡改金额或换链后恢复出的地址不同
+	auth.Amount = big.NewInt(1001)
+	recovered, _, err = recoverWithdrawSigner(auth, chainId, 1000)
+	require.NoError(t, err)
+	require.NotEqual(t, signerAddress, recovered)
+	auth.Amount = big.NewInt(1000)
+	recovered, _, err = recoverWithdrawSigner(auth, big.NewInt(10), 1000)
+	require.NoError(t, err)
+	require.NotEqual(t, signerAddress, recovered)
+}
+
+func TestRecoverWithdrawSignerExpired(t *testing.T) {
+	auth, _ := signedAuthorization(t, big.NewInt(1))
+	_, _, err := recoverWithdrawSigner(auth, big.NewInt(1), 2001)
+	require.True(t, errors.Is(err, ErrWithdrawUnauthorized))
+	require.Contains(t, err.Error(), "expired")
+
+	auth.Signature = auth.Signature[:64]
+	_, _, err = recoverWithdrawSigner(auth, big.NewInt(1), 1000)
+	require.True(t, errors.Is(err, ErrWithdrawUnauthorized))
+}
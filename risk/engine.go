@@ -0,0 +1,207 @@
+package risk
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/the-web3/eth-wallet/database"
+)
+
+type Decision uint8
+
+const (
+	DecisionPass   Decision = 0
+	DecisionReview Decision = 1
+	DecisionReject Decision = 2
+)
+
+// 只有提现 worker 和人工审核记录风控结果，Verify* 查询接口只评估不记录
+const (
+	SubjectWithdraw = "withdraw"
+	SubjectReview   = "review" // 人工审核结果，subject 为审核人
+)
+
+type Result struct {
+	Decision  Decision
+	Triggered []string
+}
+
+func (r *Result) trigger(rule database.RiskRules, reason string) {
+	r.Triggered = append(r.Triggered, rule.RuleName+": "+reason)
+	if action := ruleAction(rule); action > r.Decision {
+		r.Decision = action
+	}
+}
+
+func (r *Result) Pass() bool {
+	return r.Decision == DecisionPass
+}
+
+type Engine struct {
+	db *database.DB
+}
+
+func NewEngine(db *database.DB) *Engine {
+	return &Engine{db: db}
+}
+
+type dbStore struct {
+	database.RiskView
+	database.WithdrawsView
+}
+
+func (e *Engine) EvaluateWithdraw(withdraw *database.Withdraws) (*Result, error) {
+	rules, err := e.db.Risk.QueryEnableRiskRules()
+	if err != nil {
+		log.Error("query risk rules fail", "err", err)
+		return nil, err
+	}
+	return evaluateWithdrawRules(dbStore{e.db.Risk, e.db.Withdraws}, rules, withdraw, uint64(time.Now().Unix()))
+}
+
+// CheckWithdraw 评估提现风险，首次评估或结果变化时记录，未通过的提现转入风控审核或风控拒绝状态；人工审核通过的提现不再按规则拦截
+func (e *Engine) CheckWithdraw(withdraw *database.Withdraws) (*Result, error) {
+	latest, err := e.db.Risk.LatestDecisionByWithdraw(withdraw.GUID.String())
+	if err != nil {
+		log.Error("query latest risk decision fail", "guid", withdraw.GUID, "err", err)
+		return nil, err
+	}
+	if latest != nil && latest.SubjectType == SubjectReview && Decision(latest.Decision) == DecisionPass {
+		return &Result{Decision: DecisionPass}, nil
+	}
+	result, err := e.EvaluateWithdraw(withdraw)
+	if err != nil {
+		return nil, err
+	}
+	changed := latest == nil || Decision(latest.Decision) != result.Decision
+	if !changed && result.Pass() {
+		return result, nil
+	}
+	decision := result.record(SubjectWithdraw, withdraw.GUID.String(), withdraw.GUID.String())
+	err = e.db.Transaction(func(tx *database.DB) error {
+		if changed {
+			if err := tx.Risk.StoreRiskDecision(decision); err != nil {
+				return err
+			}
+		}
+		switch result.Decision {
+		case DecisionReview:
+			return tx.Withdraws.UpdateWithdrawStatus(withdraw.GUID, 6)
+		case DecisionReject:
+			return tx.Withdraws.UpdateWithdrawStatus(withdraw.GUID, 7)
+		}
+		return nil
+	})
+	if err != nil {
+		log.Error("store risk decision fail", "guid", withdraw.GUID, "err", err)
+		return nil, err
+	}
+	if !result.Pass() {
+		log.Warn("withdraw blocked by risk engine", "guid", withdraw.GUID, "decision", result.Decision, "rules", result.Triggered)
+	}
+	return result, nil
+}
+
+// ReviewWithdraw 人工审核风控审核或模拟失败的提现，返回 false 表示提现不在审核状态
+func (e *Engine) ReviewWithdraw(guid uuid.UUID, approve bool, operator string, reason string) (bool, error) {
+	result := &Result{Decision: DecisionReject}
+	toStatus := uint8(7)
+	if approve {
+		result.Decision = DecisionPass
+		toStatus = 0
+	}
+	if reason != "" {
+		result.Triggered = []string{reason}
+	}
+	decision := result.record(SubjectReview, operator, guid.String())
+	reviewed := false
+	err := e.db.Transaction(func(tx *database.DB) error {
+		var err error
+		reviewed, err = tx.Withdraws.ReviewWithdraw(guid, approve)
+		if err != nil || !reviewed {
+			return err
+		}
+		if err := tx.Risk.StoreRiskDecision(decision); err != nil {
+			return err
+		}
+		return tx.WithdrawEvents.StoreWithdrawEvents([]database.WithdrawEvents{
+			database.NewWithdrawEvent(guid, database.WithdrawEventReviewed, 6, toStatus, operator),
+		})
+	})
+	if err != nil {
+		log.Error("review withdraw fail", "guid", guid, "err", err)
+		return false, err
+	}
+	return reviewed, nil
+}
+
+func (e *Engine) VerifyAddress(address common.Address) (*Result, error) {
+	return e.evaluateAddress(address)
+}
+
+// VerifyWithdrawByHash 返回该提现最近一次风控结果，没有记录时只评估不记录，也不改变提现状态
+func (e *Engine) VerifyWithdrawByHash(hash common.Hash) (*Result, bool, error) {
+	withdraw, err := e.db.Withdraws.QueryWithdrawsByHash(hash)
+	if err != nil || withdraw == nil {
+		return nil, false, err
+	}
+	decision, err := e.db.Risk.LatestDecisionByWithdraw(withdraw.GUID.String())
+	if err != nil {
+		return nil, false, err
+	}
+	if decision != nil {
+		result := &Result{Decision: Decision(decision.Decision)}
+		if decision.TriggeredRules != "" {
+			result.Triggered = strings.Split(decision.TriggeredRules, "; ")
+		}
+		return result, true, nil
+	}
+	result, err := e.EvaluateWithdraw(withdraw)
+	if err != nil {
+		return nil, false, err
+	}
+	return result, true, nil
+}
+
+// VerifyNotify 对充值或提现到账通知做风控，提现沿用提现风控结果，充值校验来源地址
+func (e *Engine) VerifyNotify(hash common.Hash) (*Result, bool, error) {
+	result, found, err := e.VerifyWithdrawByHash(hash)
+	if err != nil || found {
+		return result, found, err
+	}
+	deposit, err := e.db.Deposits.QueryDepositsByHash(hash)
+	if err != nil || deposit == nil {
+		return nil, false, err
+	}
+	result, err = e.evaluateAddress(deposit.FromAddress)
+	if err != nil {
+		return nil, false, err
+	}
+	return result, true, nil
+}
+
+func (e *Engine) evaluateAddress(address common.Address) (*Result, error) {
+	rules, err := e.db.Risk.QueryEnableRiskRules()
+	if err != nil {
+		log.Error("query risk rules fail", "err", err)
+		return nil, err
+	}
+	return evaluateAddressRules(dbStore{e.db.Risk, e.db.Withdraws}, rules, address)
+}
+
+func (r *Result) record(subjectType string, subject string, withdrawGuid string) database.RiskDecisions {
+	return database.RiskDecisions{
+		GUID:           uuid.New(),
+		SubjectType:    subjectType,
+		Subject:        subject,
+		WithdrawGuid:   withdrawGuid,
+		Decision:       uint8(r.Decision),
+		TriggeredRules: strings.Join(r.Triggered, "; "),
+		Timestamp:      uint64(time.Now().Unix()),
+	}
+}
@@ -0,0 +1,218 @@
+package risk
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/the-web3/eth-wallet/database"
+)
+
+const limitWindowSeconds uint64 = 24 * 60 * 60
+
+var (
+	ErrWithdrawLimitExceeded = errors.New("withdraw limit exceeded")
+	ErrInvalidWithdrawLimit  = errors.New("invalid withdraw limit")
+)
+
+// LimitUsage 限额的当前使用情况，AmountHeadroom 为 nil 表示金额不限制，CountHeadroom 为 -1 表示笔数不限制
+type LimitUsage struct {
+	Limit          database.WithdrawLimits `json:"limit"`
+	UsedAmount     *big.Int                `json:"used_amount"`
+	UsedCount      int64                   `json:"used_count"`
+	AmountHeadroom *big.Int                `json:"amount_headroom"`
+	CountHeadroom  int64                   `json:"count_headroom"`
+}
+
+// limitStore 限额计算依赖的统计查询，提交时统计全部未拒绝的提现，签名前只统计已签名的提现
+type limitStore interface {
+	WithdrawStatsSince(userUid string, tokenAddress string, since uint64, excludeGuid uuid.UUID) (*database.WithdrawStats, error)
+}
+
+type signedLimitStore struct {
+	database.WithdrawsView
+}
+
+func (s signedLimitStore) WithdrawStatsSince(userUid string, tokenAddress string, since uint64, excludeGuid uuid.UUID) (*database.WithdrawStats, error) {
+	return s.SignedWithdrawStatsSince(userUid, tokenAddress, since, excludeGuid)
+}
+
+// SignLimitTally 同一轮中已通过签名前复核、但还未持久化为已签名的提现，复核后续提现时计入限额使用量
+type SignLimitTally struct {
+	withdrawList []database.Withdraws
+}
+
+func (t *SignLimitTally) Add(withdraw database.Withdraws) {
+	t.withdrawList = append(t.withdrawList, withdraw)
+}
+
+type tallyLimitStore struct {
+	limitStore
+	tally *SignLimitTally
+}
+
+func (s tallyLimitStore) WithdrawStatsSince(userUid string, tokenAddress string, since uint64, excludeGuid uuid.UUID) (*database.WithdrawStats, error) {
+	stats, err := s.limitStore.WithdrawStatsSince(userUid, tokenAddress, since, excludeGuid)
+	if err != nil {
+		return nil, err
+	}
+	amount := new(big.Int).Set(stats.Amount)
+	count := stats.Count
+	for _, withdraw := range s.tally.withdrawList {
+		if withdraw.GUID == excludeGuid || withdraw.Timestamp < since {
+			continue
+		}
+		if userUid != "" && withdraw.UserUid != userUid {
+			continue
+		}
+		if tokenAddress != "" && withdraw.TokenAddress != common.HexToAddress(tokenAddress) {
+			continue
+		}
+		amount.Add(amount, withdraw.Amount)
+		count++
+	}
+	return &database.WithdrawStats{Count: count, Amount: amount}, nil
+}
+
+func limitAmountEnabled(limit database.WithdrawLimits) bool {
+	return limit.TokenAddress != ""
+}
+
+// validateLimit 不同币种金额不能相加，不指定币种的限额设置了金额时拒绝使用，而不是忽略金额只限制笔数
+func validateLimit(limit database.WithdrawLimits) error {
+	if !limitAmountEnabled(limit) && (positive(limit.SingleMax) || positive(limit.DailyAmount)) {
+		return fmt.Errorf("%w: %s sets single_max or daily_amount without token_address", ErrInvalidWithdrawLimit, limit.LimitName)
+	}
+	return nil
+}
+
+func positive(value *big.Int) bool {
+	return value != nil && value.Sign() > 0
+}
+
+// limitUsage 统计限额在滚动窗口内的使用量，对每个用户生效的限额在用户为空时不适用
+func limitUsage(store limitStore, limits []database.WithdrawLimits, userUid string, excludeGuid uuid.UUID, now uint64) ([]LimitUsage, error) {
+	var since uint64
+	if now > limitWindowSeconds {
+		since = now - limitWindowSeconds
+	}
+	var usages []LimitUsage
+	for _, limit := range limits {
+		if err := validateLimit(limit); err != nil {
+			log.Error("withdraw limit misconfigured", "guid", limit.GUID, "err", err)
+			return nil, err
+		}
+		statsUser := ""
+		if limit.UserUid != "" {
+			if userUid == "" {
+				continue
+			}
+			statsUser = userUid
+		}
+		stats, err := store.WithdrawStatsSince(statsUser, limit.TokenAddress, since, excludeGuid)
+		if err != nil {
+			return nil, err
+		}
+		usage := LimitUsage{Limit: limit, UsedAmount: big.NewInt(0), UsedCount: stats.Count, CountHeadroom: -1}
+		if limitAmountEnabled(limit) {
+			usage.UsedAmount = stats.Amount
+			if positive(limit.DailyAmount) {
+				usage.AmountHeadroom = new(big.Int).Sub(limit.DailyAmount, stats.Amount)
+				if usage.AmountHeadroom.Sign() < 0 {
+					usage.AmountHeadroom = big.NewInt(0)
+				}
+			}
+		}
+		if limit.DailyCount > 0 {
+			usage.CountHeadroom = int64(limit.DailyCount) - stats.Count
+			if usage.CountHeadroom < 0 {
+				usage.CountHeadroom = 0
+			}
+		}
+		usages = append(usages, usage)
+	}
+	return usages, nil
+}
+
+// checkLimits 校验再提一笔 amount 是否超出限额
+func checkLimits(usages []LimitUsage, amount *big.Int) error {
+	for _, usage := range usages {
+		limit := usage.Limit
+		if limitAmountEnabled(limit) && positive(limit.SingleMax) && amount.Cmp(limit.SingleMax) > 0 {
+			return fmt.Errorf("%w: %s single amount %s exceeds %s", ErrWithdrawLimitExceeded, limit.LimitName, amount.String(), limit.SingleMax.String())
+		}
+		if usage.CountHeadroom >= 0 && usage.CountHeadroom < 1 {
+			return fmt.Errorf("%w: %s daily count %d reaches %d", ErrWithdrawLimitExceeded, limit.LimitName, usage.UsedCount, limit.DailyCount)
+		}
+		if usage.AmountHeadroom != nil && amount.Cmp(usage.AmountHeadroom) > 0 {
+			return fmt.Errorf("%w: %s daily amount %s exceeds %s", ErrWithdrawLimitExceeded, limit.LimitName, new(big.Int).Add(usage.UsedAmount, amount).String(), limit.DailyAmount.String())
+		}
+	}
+	return nil
+}
+
+// CheckSubmitLimits 提交提现时校验限额，已存在的请求为重复提交，不再校验
+func (e *Engine) CheckSubmitLimits(consumerToken string, requestId string, userUid string, tokenAddress common.Address, amount *big.Int) error {
+	if requestId != "" {
+		existWithdraw, err := e.db.Withdraws.QueryWithdrawsByRequestId(consumerToken, requestId)
+		if err != nil {
+			return err
+		}
+		if existWithdraw != nil {
+			return nil
+		}
+	}
+	return e.checkSubmitLimits(userUid, tokenAddress, amount, uuid.Nil)
+}
+
+// CheckAmendLimits 修改提现金额时校验限额，不统计该提现原来的金额
+func (e *Engine) CheckAmendLimits(withdraw *database.Withdraws, amount *big.Int) error {
+	return e.checkSubmitLimits(withdraw.UserUid, withdraw.TokenAddress, amount, withdraw.GUID)
+}
+
+func (e *Engine) checkSubmitLimits(userUid string, tokenAddress common.Address, amount *big.Int, excludeGuid uuid.UUID) error {
+	limits, err := e.db.WithdrawLimits.QueryEnableWithdrawLimits(userUid, tokenAddress)
+	if err != nil {
+		log.Error("query withdraw limits fail", "err", err)
+		return err
+	}
+	usages, err := limitUsage(e.db.Withdraws, limits, userUid, excludeGuid, uint64(time.Now().Unix()))
+	if err != nil {
+		return err
+	}
+	return checkLimits(usages, amount)
+}
+
+// CheckSignLimits 签名前按已签名的提现复核限额，防止提交后并发或改限额导致超限；tally 不为空时同时计入本轮已通过复核的提现
+func (e *Engine) CheckSignLimits(withdraw *database.Withdraws, tally *SignLimitTally) error {
+	limits, err := e.db.WithdrawLimits.QueryEnableWithdrawLimits(withdraw.UserUid, withdraw.TokenAddress)
+	if err != nil {
+		log.Error("query withdraw limits fail", "err", err)
+		return err
+	}
+	var store limitStore = signedLimitStore{e.db.Withdraws}
+	if tally != nil {
+		store = tallyLimitStore{limitStore: store, tally: tally}
+	}
+	usages, err := limitUsage(store, limits, withdraw.UserUid, withdraw.GUID, uint64(time.Now().Unix()))
+	if err != nil {
+		return err
+	}
+	return checkLimits(usages, withdraw.Amount)
+}
+
+// WithdrawLimitUsage 查询对该用户和币种生效的限额及使用量
+func (e *Engine) WithdrawLimitUsage(userUid string, tokenAddress common.Address) ([]LimitUsage, error) {
+	limits, err := e.db.WithdrawLimits.QueryEnableWithdrawLimits(userUid, tokenAddress)
+	if err != nil {
+		log.Error("query withdraw limits fail", "err", err)
+		return nil, err
+	}
+	return limitUsage(e.db.Withdraws, limits, userUid, uuid.Nil, uint64(time.Now().Unix()))
+}
@@ -0,0 +1,119 @@
+package risk
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/the-web3/eth-wallet/database"
+)
+
+type limitFakeStore struct {
+	stats     map[string]database.WithdrawStats
+	queryUser []string
+}
+
+func (f *limitFakeStore) WithdrawStatsSince(userUid string, tokenAddress string, since uint64, excludeGuid uuid.UUID) (*database.WithdrawStats, error) {
+	f.queryUser = append(f.queryUser, userUid)
+	stats, ok := f.stats[userUid+"/"+tokenAddress]
+	if !ok {
+		return &database.WithdrawStats{Amount: big.NewInt(0)}, nil
+	}
+	return &stats, nil
+}
+
+const testToken = "0x0000000000000000000000000000000000000001"
+
+func TestLimitUsageScope(t *testing.T) {
+	store := &limitFakeStore{stats: map[string]database.WithdrawStats{
+		"/" + testToken:       {Count: 10, Amount: big.NewInt(5000)},
+		"user-1/" + testToken: {Count: 2, Amount: big.NewInt(300)},
+	}}
+	limits := []database.WithdrawLimits{
+		{LimitName: "global", TokenAddress: testToken, DailyAmount: big.NewInt(10000)},
+		{LimitName: "each-user", UserUid: database.WithdrawLimitEachUser, TokenAddress: testToken, DailyAmount: big.NewInt(1000), DailyCount: 3},
+		{LimitName: "all-token-count", UserUid: database.WithdrawLimitEachUser, DailyCount: 5},
+	}
+	usages, err := limitUsage(store, limits, "user-1", uuid.Nil, 100000)
+	require.NoError(t, err)
+	require.Equal(t, []string{"", "user-1", "user-1"}, store.queryUser)
+	require.Len(t, usages, 3)
+	require.Equal(t, big.NewInt(5000), usages[0].AmountHeadroom)
+	require.Equal(t, int64(-1), usages[0].CountHeadroom)
+	require.Equal(t, big.NewInt(700), usages[1].AmountHeadroom)
+	require.Equal(t, int64(1), usages[1].CountHeadroom)
+	// 未指定币种的限额只限制笔数
+	require.Nil(t, usages[2].AmountHeadroom)
+
+	usages, err = limitUsage(store, limits, "", uuid.Nil, 100000)
+	require.NoError(t, err)
+	require.Len(t, usages, 1)
+}
+
+func TestCheckLimits(t *testing.T) {
+	usages := []LimitUsage{
+		{Limit: database.WithdrawLimits{LimitName: "single", TokenAddress: testToken, SingleMax: big.NewInt(500)}, UsedAmount: big.NewInt(0), CountHeadroom: -1},
+		{Limit: database.WithdrawLimits{LimitName: "daily", TokenAddress: testToken, DailyAmount: big.NewInt(1000), DailyCount: 3}, UsedAmount: big.NewInt(700), AmountHeadroom: big.NewInt(300), CountHeadroom: 1},
+	}
+	require.NoError(t, checkLimits(usages, big.NewInt(300)))
+
+	err := checkLimits(usages, big.NewInt(501))
+	require.True(t, errors.Is(err, ErrWithdrawLimitExceeded))
+	require.Contains(t, err.Error(), "single")
+
+	err = checkLimits(usages, big.NewInt(301))
+	require.True(t, errors.Is(err, ErrWithdrawLimitExceeded))
+	require.Contains(t, err.Error(), "daily amount")
+
+	usages[1].CountHeadroom = 0
+	err = checkLimits(usages, big.NewInt(1))
+	require.True(t, errors.Is(err, ErrWithdrawLimitExceeded))
+	require.Contains(t, err.Error(), "daily count")
+}
+
+func TestSignLimitTally(t *testing.T) {
+	store := &limitFakeStore{stats: map[string]database.WithdrawStats{
+		"user-1/" + testToken: {Count: 1, Amount: big.NewInt(400)},
+	}}
+	limits := []database.WithdrawLimits{
+		{LimitName: "each-user", UserUid: database.WithdrawLimitEachUser, TokenAddress: testToken, DailyAmount: big.NewInt(1000), DailyCount: 3},
+	}
+	tally := &SignLimitTally{}
+	tallyStore := tallyLimitStore{limitStore: store, tally: tally}
+	current := database.Withdraws{GUID: uuid.New(), UserUid: "user-1", TokenAddress: common.HexToAddress(testToken), Amount: big.NewInt(400), Timestamp: 100000}
+
+	usages, err := limitUsage(tallyStore, limits, "user-1", current.GUID, 100000)
+	require.NoError(t, err)
+	require.NoError(t, checkLimits(usages, current.Amount))
+
+	// 同一轮已通过复核的提现计入使用量，其他用户、其他币种和窗口外的不计入
+	tally.Add(database.Withdraws{GUID: uuid.New(), UserUid: "user-1", TokenAddress: common.HexToAddress(testToken), Amount: big.NewInt(300), Timestamp: 100000})
+	tally.Add(database.Withdraws{GUID: uuid.New(), UserUid: "user-2", TokenAddress: common.HexToAddress(testToken), Amount: big.NewInt(900), Timestamp: 100000})
+	tally.Add(database.Withdraws{GUID: uuid.New(), UserUid: "user-1", TokenAddress: common.HexToAddress("0x02"), Amount: big.NewInt(900), Timestamp: 100000})
+	tally.Add(database.Withdraws{GUID: uuid.New(), UserUid: "user-1", TokenAddress: common.HexToAddress(testToken), Amount: big.NewInt(900), Timestamp: 1})
+	usages, err = limitUsage(tallyStore, limits, "user-1", current.GUID, 100000)
+	require.NoError(t, err)
+	require.Equal(t, big.NewInt(700), usages[0].UsedAmount)
+	require.Equal(t, int64(1), usages[0].CountHeadroom)
+	err = checkLimits(usages, current.Amount)
+	require.True(t, errors.Is(err, ErrWithdrawLimitExceeded))
+	// 底层统计结果不被修改
+	require.Equal(t, big.NewInt(400), store.stats["user-1/"+testToken].Amount)
+}
+
+func TestLimitUsageRejectsAmountWithoutToken(t *testing.T) {
+	store := &limitFakeStore{stats: map[string]database.WithdrawStats{}}
+	limits := []database.WithdrawLimits{{LimitName: "global", SingleMax: big.NewInt(500), DailyAmount: big.NewInt(0)}}
+	_, err := limitUsage(store, limits, "user-1", uuid.Nil, 100000)
+	require.True(t, errors.Is(err, ErrInvalidWithdrawLimit))
+
+	limits = []database.WithdrawLimits{{LimitName: "global", SingleMax: big.NewInt(0), DailyAmount: big.NewInt(0), DailyCount: 3}}
+	usages, err := limitUsage(store, limits, "user-1", uuid.Nil, 100000)
+	require.NoError(t, err)
+	require.Len(t, usages, 1)
+}
@@ -0,0 +1,135 @@
+package risk
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/the-web3/eth-wallet/database"
+)
+
+const (
+	RuleTypeAddressBlocklist  uint8 = 1
+	RuleTypeUserVelocity      uint8 = 2
+	RuleTypeTokenVelocity     uint8 = 3
+	RuleTypeAmountThreshold   uint8 = 4
+	RuleTypeNewAddressCooling uint8 = 5
+)
+
+// ruleStore 规则计算依赖的数据查询
+type ruleStore interface {
+	QueryBlocklistByAddress(address common.Address) (*database.RiskBlocklist, error)
+	WithdrawStatsSince(userUid string, tokenAddress string, since uint64, excludeGuid uuid.UUID) (*database.WithdrawStats, error)
+	FirstWithdrawTimestampToAddress(toAddress common.Address) (uint64, error)
+}
+
+func ruleMatchToken(rule database.RiskRules, tokenAddress common.Address) bool {
+	return rule.TokenAddress == "" || strings.EqualFold(rule.TokenAddress, tokenAddress.String())
+}
+
+func ruleAction(rule database.RiskRules) Decision {
+	if rule.Action == uint8(DecisionReject) {
+		return DecisionReject
+	}
+	return DecisionReview
+}
+
+// evaluateWithdrawRule 返回规则是否命中以及命中原因
+func evaluateWithdrawRule(store ruleStore, rule database.RiskRules, withdraw *database.Withdraws, now uint64) (bool, string, error) {
+	switch rule.RuleType {
+	case RuleTypeAddressBlocklist:
+		for _, address := range []common.Address{withdraw.ToAddress, withdraw.FromAddress} {
+			blockEntry, err := store.QueryBlocklistByAddress(address)
+			if err != nil {
+				return false, "", err
+			}
+			if blockEntry != nil {
+				return true, fmt.Sprintf("address %s is blocklisted: %s", address.String(), blockEntry.Reason), nil
+			}
+		}
+		return false, "", nil
+	case RuleTypeUserVelocity, RuleTypeTokenVelocity:
+		if !ruleMatchToken(rule, withdraw.TokenAddress) {
+			return false, "", nil
+		}
+		var userUid string
+		if rule.RuleType == RuleTypeUserVelocity {
+			if withdraw.UserUid == "" {
+				return false, "", nil
+			}
+			userUid = withdraw.UserUid
+		}
+		var since uint64
+		if now > rule.WindowSeconds {
+			since = now - rule.WindowSeconds
+		}
+		stats, err := store.WithdrawStatsSince(userUid, withdraw.TokenAddress.String(), since, withdraw.GUID)
+		if err != nil {
+			return false, "", err
+		}
+		if rule.MaxCount > 0 && uint64(stats.Count)+1 > rule.MaxCount {
+			return true, fmt.Sprintf("withdraw count %d exceeds %d in %ds", stats.Count+1, rule.MaxCount, rule.WindowSeconds), nil
+		}
+		totalAmount := new(big.Int).Add(stats.Amount, withdraw.Amount)
+		if rule.Threshold != nil && rule.Threshold.Sign() > 0 && totalAmount.Cmp(rule.Threshold) > 0 {
+			return true, fmt.Sprintf("withdraw amount %s exceeds %s in %ds", totalAmount.String(), rule.Threshold.String(), rule.WindowSeconds), nil
+		}
+		return false, "", nil
+	case RuleTypeAmountThreshold:
+		if !ruleMatchToken(rule, withdraw.TokenAddress) || rule.Threshold == nil {
+			return false, "", nil
+		}
+		if withdraw.Amount.Cmp(rule.Threshold) > 0 {
+			return true, fmt.Sprintf("withdraw amount %s exceeds %s", withdraw.Amount.String(), rule.Threshold.String()), nil
+		}
+		return false, "", nil
+	case RuleTypeNewAddressCooling:
+		firstTimestamp, err := store.FirstWithdrawTimestampToAddress(withdraw.ToAddress)
+		if err != nil {
+			return false, "", err
+		}
+		if firstTimestamp == 0 || firstTimestamp > withdraw.Timestamp {
+			firstTimestamp = withdraw.Timestamp
+		}
+		if now < firstTimestamp+rule.WindowSeconds {
+			return true, fmt.Sprintf("address %s first seen at %d is cooling until %d", withdraw.ToAddress.String(), firstTimestamp, firstTimestamp+rule.WindowSeconds), nil
+		}
+		return false, "", nil
+	}
+	return false, "", nil
+}
+
+func evaluateWithdrawRules(store ruleStore, rules []database.RiskRules, withdraw *database.Withdraws, now uint64) (*Result, error) {
+	result := &Result{Decision: DecisionPass}
+	for _, rule := range rules {
+		hit, reason, err := evaluateWithdrawRule(store, rule, withdraw, now)
+		if err != nil {
+			return nil, err
+		}
+		if hit {
+			result.trigger(rule, reason)
+		}
+	}
+	return result, nil
+}
+
+func evaluateAddressRules(store ruleStore, rules []database.RiskRules, address common.Address) (*Result, error) {
+	result := &Result{Decision: DecisionPass}
+	for _, rule := range rules {
+		if rule.RuleType != RuleTypeAddressBlocklist {
+			continue
+		}
+		blockEntry, err := store.QueryBlocklistByAddress(address)
+		if err != nil {
+			return nil, err
+		}
+		if blockEntry != nil {
+			result.trigger(rule, fmt.Sprintf("address %s is blocklisted: %s", address.String(), blockEntry.Reason))
+		}
+	}
+	return result, nil
+}
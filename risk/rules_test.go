@@ -0,0 +1,125 @@
+package risk
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/the-web3/eth-wallet/database"
+)
+
+type fakeStore struct {
+	blocked        map[common.Address]string
+	stats          database.WithdrawStats
+	firstTimestamp uint64
+}
+
+func (f *fakeStore) QueryBlocklistByAddress(address common.Address) (*database.RiskBlocklist, error) {
+	if reason, ok := f.blocked[address]; ok {
+		return &database.RiskBlocklist{Address: address, Reason: reason}, nil
+	}
+	return nil, nil
+}
+
+func (f *fakeStore) WithdrawStatsSince(userUid string, tokenAddress string, since uint64, excludeGuid uuid.UUID) (*database.WithdrawStats, error) {
+	return &f.stats, nil
+}
+
+func (f *fakeStore) FirstWithdrawTimestampToAddress(toAddress common.Address) (uint64, error) {
+	return f.firstTimestamp, nil
+}
+
+func testWithdraw() *database.Withdraws {
+	return &database.Withdraws{
+		GUID:         uuid.New(),
+		FromAddress:  common.HexToAddress("0x01"),
+		ToAddress:    common.HexToAddress("0x02"),
+		TokenAddress: common.Address{},
+		Amount:       big.NewInt(100),
+		UserUid:      "user-1",
+		Timestamp:    1000,
+	}
+}
+
+func TestEvaluateWithdrawRulesPass(t *testing.T) {
+	store := &fakeStore{stats: database.WithdrawStats{Amount: big.NewInt(0)}}
+	rules := []database.RiskRules{
+		{RuleName: "blocklist", RuleType: RuleTypeAddressBlocklist, Action: 2},
+		{RuleName: "amount", RuleType: RuleTypeAmountThreshold, Threshold: big.NewInt(1000), Action: 1},
+	}
+	result, err := evaluateWithdrawRules(store, rules, testWithdraw(), 2000)
+	require.NoError(t, err)
+	require.True(t, result.Pass())
+	require.Empty(t, result.Triggered)
+}
+
+func TestEvaluateWithdrawRulesBlocklist(t *testing.T) {
+	withdraw := testWithdraw()
+	store := &fakeStore{blocked: map[common.Address]string{withdraw.ToAddress: "sanctioned"}}
+	rules := []database.RiskRules{
+		{RuleName: "amount", RuleType: RuleTypeAmountThreshold, Threshold: big.NewInt(10), Action: 1},
+		{RuleName: "blocklist", RuleType: RuleTypeAddressBlocklist, Action: 2},
+	}
+	result, err := evaluateWithdrawRules(store, rules, withdraw, 2000)
+	require.NoError(t, err)
+	require.Equal(t, DecisionReject, result.Decision)
+	require.Len(t, result.Triggered, 2)
+}
+
+func TestEvaluateWithdrawRulesVelocity(t *testing.T) {
+	store := &fakeStore{stats: database.WithdrawStats{Count: 2, Amount: big.NewInt(950)}}
+	countRule := database.RiskRules{RuleName: "user-count", RuleType: RuleTypeUserVelocity, MaxCount: 3, WindowSeconds: 3600, Action: 1}
+	result, err := evaluateWithdrawRules(store, []database.RiskRules{countRule}, testWithdraw(), 2000)
+	require.NoError(t, err)
+	require.True(t, result.Pass())
+
+	countRule.MaxCount = 2
+	result, err = evaluateWithdrawRules(store, []database.RiskRules{countRule}, testWithdraw(), 2000)
+	require.NoError(t, err)
+	require.Equal(t, DecisionReview, result.Decision)
+
+	amountRule := database.RiskRules{RuleName: "token-amount", RuleType: RuleTypeTokenVelocity, Threshold: big.NewInt(1000), WindowSeconds: 3600, Action: 2}
+	result, err = evaluateWithdrawRules(store, []database.RiskRules{amountRule}, testWithdraw(), 2000)
+	require.NoError(t, err)
+	require.Equal(t, DecisionReject, result.Decision)
+
+	amountRule.TokenAddress = "0x0000000000000000000000000000000000000003"
+	result, err = evaluateWithdrawRules(store, []database.RiskRules{amountRule}, testWithdraw(), 2000)
+	require.NoError(t, err)
+	require.True(t, result.Pass())
+}
+
+func TestEvaluateWithdrawRulesNewAddressCooling(t *testing.T) {
+	rule := database.RiskRules{RuleName: "cooling", RuleType: RuleTypeNewAddressCooling, WindowSeconds: 3600, Action: 1}
+
+	store := &fakeStore{}
+	result, err := evaluateWithdrawRules(store, []database.RiskRules{rule}, testWithdraw(), 2000)
+	require.NoError(t, err)
+	require.Equal(t, DecisionReview, result.Decision)
+
+	result, err = evaluateWithdrawRules(store, []database.RiskRules{rule}, testWithdraw(), 5000)
+	require.NoError(t, err)
+	require.True(t, result.Pass())
+}
+
+func TestEvaluateAddressRules(t *testing.T) {
+	address := common.HexToAddress("0x05")
+	store := &fakeStore{blocked: map[common.Address]string{address: "phishing"}}
+
+	result, err := evaluateAddressRules(store, nil, address)
+	require.NoError(t, err)
+	require.True(t, result.Pass())
+
+	rules := []database.RiskRules{{RuleName: "blocklist", RuleType: RuleTypeAddressBlocklist, Action: 2}}
+	result, err = evaluateAddressRules(store, rules, address)
+	require.NoError(t, err)
+	require.Equal(t, DecisionReject, result.Decision)
+
+	result, err = evaluateAddressRules(store, rules, common.HexToAddress("0x06"))
+	require.NoError(t, err)
+	require.True(t, result.Pass())
+}
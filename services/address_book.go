@@ -0,0 +1,91 @@
+package services
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/the-web3/eth-wallet/database"
+	"github.com/the-web3/eth-wallet/proto/wallet"
+)
+
+func (s *RpcServer) AddAddressBook(ctx context.Context, in *wallet.AddressBookReq) (*wallet.AddressBookRep, error) {
+	if !common.IsHexAddress(in.Address) {
+		return &wallet.AddressBookRep{
+			Code: strconv.Itoa(4000),
+			Msg:  "invalid address",
+		}, nil
+	}
+	entry, err := s.riskEngine.AddAddressBookEntry(in.UserUid, common.HexToAddress(in.Address), in.Label, s.AddressBook.CoolingPeriod)
+	if err != nil {
+		log.Error("add address book fail", "userUid", in.UserUid, "address", in.Address, "err", err)
+		return &wallet.AddressBookRep{
+			Code: strconv.Itoa(4000),
+			Msg:  "add address book fail",
+		}, nil
+	}
+	return &wallet.AddressBookRep{
+		Code:  strconv.Itoa(2000),
+		Msg:   "add address book success",
+		Entry: toAddressBookEntry(entry),
+	}, nil
+}
+
+func (s *RpcServer) RemoveAddressBook(ctx context.Context, in *wallet.AddressBookReq) (*wallet.AddressBookRep, error) {
+	if !common.IsHexAddress(in.Address) {
+		return &wallet.AddressBookRep{
+			Code: strconv.Itoa(4000),
+			Msg:  "invalid address",
+		}, nil
+	}
+	removed, err := s.riskEngine.RemoveAddressBookEntry(in.UserUid, common.HexToAddress(in.Address))
+	if err != nil {
+		log.Error("remove address book fail", "userUid", in.UserUid, "address", in.Address, "err", err)
+		return &wallet.AddressBookRep{
+			Code: strconv.Itoa(4000),
+			Msg:  "remove address book fail",
+		}, nil
+	}
+	if !removed {
+		return &wallet.AddressBookRep{
+			Code: strconv.Itoa(4000),
+			Msg:  "address book entry not found",
+		}, nil
+	}
+	return &wallet.AddressBookRep{
+		Code: strconv.Itoa(2000),
+		Msg:  "remove address book success",
+	}, nil
+}
+
+func (s *RpcServer) ListAddressBook(ctx context.Context, in *wallet.ListAddressBookReq) (*wallet.ListAddressBookRep, error) {
+	entryList, err := s.readDB.AddressBook.QueryAddressBook(in.UserUid)
+	if err != nil {
+		log.Error("list address book fail", "userUid", in.UserUid, "err", err)
+		return &wallet.ListAddressBookRep{
+			Code: strconv.Itoa(4000),
+			Msg:  "list address book fail",
+		}, nil
+	}
+	var entries []*wallet.AddressBookEntry
+	for i := range entryList {
+		entries = append(entries, toAddressBookEntry(&entryList[i]))
+	}
+	return &wallet.ListAddressBookRep{
+		Code:    strconv.Itoa(2000),
+		Msg:     "list address book success",
+		Entries: entries,
+	}, nil
+}
+
+func toAddressBookEntry(entry *database.AddressBook) *wallet.AddressBookEntry {
+	return &wallet.AddressBookEntry{
+		UserUid:       entry.UserUid,
+		Address:       entry.Address.String(),
+		Label:         entry.Label,
+		EffectiveTime: entry.EffectiveTime,
+		Timestamp:     entry.Timestamp,
+	}
+}
@@ -2,17 +2,31 @@ package services
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"math/big"
 	"strconv"
+	"strings"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/log"
 
+	"github.com/the-web3/eth-wallet/database"
 	"github.com/the-web3/eth-wallet/proto/wallet"
+	"github.com/the-web3/eth-wallet/risk"
 )
 
 func (s *RpcServer) SubmitWithdrawInfo(ctx context.Context, in *wallet.WithdrawReq) (*wallet.WithdrawRep, error) {
-	log.Info("submit withdraw start....")
+	log.Info("submit withdraw start....", "requestId", in.RequestId)
+	if in.RequestId == "" {
+		log.Error("invalid input request id")
+		return &wallet.WithdrawRep{
+			Code: strconv.Itoa(4000),
+			Msg:  "request id is empty",
+			Hash: common.Hash{}.String(),
+		}, nil
+	}
 	amountBig := new(big.Int)
 	_, ok := amountBig.SetString(in.Amount, 10)
 	if !ok {
@@ -23,8 +37,75 @@ func (s *RpcServer) SubmitWithdrawInfo(ctx context.Context, in *wallet.WithdrawR
 			Hash: common.Hash{}.String(),
 		}, nil
 	}
-	amountBig.SetString(in.Amount, 10)
-	err := s.db.Withdraws.SubmitWithdrawFromBusiness(common.HexToAddress(in.FromAddress), common.HexToAddress(in.ToAddress), common.HexToAddress(in.TokenAddress), amountBig)
+	signDigest, err := s.verifyWithdrawSignature(&risk.WithdrawAuthorization{
+		ConsumerToken: in.ConsumerToken,
+		RequestId:     in.RequestId,
+		FromAddress:   common.HexToAddress(in.FromAddress),
+		ToAddress:     common.HexToAddress(in.ToAddress),
+		TokenAddress:  common.HexToAddress(in.TokenAddress),
+		Amount:        amountBig,
+		Deadline:      in.Deadline,
+	}, in.Signature)
+	if errors.Is(err, risk.ErrWithdrawUnauthorized) {
+		log.Warn("submit withdraw signature rejected", "requestId", in.RequestId, "err", err)
+		return &wallet.WithdrawRep{
+			Code: strconv.Itoa(4004),
+			Msg:  err.Error(),
+			Hash: common.Hash{}.String(),
+		}, nil
+	}
+	if err != nil {
+		log.Error("verify withdraw signature fail", "err", err)
+		return &wallet.WithdrawRep{
+			Code: strconv.Itoa(4000),
+			Msg:  "submit withdraw fail",
+			Hash: common.Hash{}.String(),
+		}, nil
+	}
+	if s.AddressBook.Enforce {
+		err := s.riskEngine.CheckWithdrawDestination(in.UserUid, common.HexToAddress(in.ToAddress))
+		if errors.Is(err, risk.ErrDestinationNotAllowed) {
+			log.Warn("submit withdraw destination not allowed", "requestId", in.RequestId, "err", err)
+			return &wallet.WithdrawRep{
+				Code: strconv.Itoa(4003),
+				Msg:  err.Error(),
+				Hash: common.Hash{}.String(),
+			}, nil
+		}
+		if err != nil {
+			log.Error("check withdraw destination fail", "err", err)
+			return &wallet.WithdrawRep{
+				Code: strconv.Itoa(4000),
+				Msg:  "submit withdraw fail",
+				Hash: common.Hash{}.String(),
+			}, nil
+		}
+	}
+	err = s.riskEngine.CheckSubmitLimits(in.ConsumerToken, in.RequestId, in.UserUid, common.HexToAddress(in.TokenAddress), amountBig)
+	if errors.Is(err, risk.ErrWithdrawLimitExceeded) {
+		log.Warn("submit withdraw exceeds limit", "requestId", in.RequestId, "err", err)
+		return &wallet.WithdrawRep{
+			Code: strconv.Itoa(4002),
+			Msg:  err.Error(),
+			Hash: common.Hash{}.String(),
+		}, nil
+	}
+	if err != nil {
+		log.Error("check withdraw limits fail", "err", err)
+		return &wallet.WithdrawRep{
+			Code: strconv.Itoa(4000),
+			Msg:  "submit withdraw fail",
+			Hash: common.Hash{}.String(),
+		}, nil
+	}
+	withdraw, err := s.db.Withdraws.SubmitWithdrawFromBusiness(in.ConsumerToken, in.RequestId, in.UserUid, common.HexToAddress(in.FromAddress), common.HexToAddress(in.ToAddress), common.HexToAddress(in.TokenAddress), amountBig, signDigest)
+	if errors.Is(err, database.ErrWithdrawRequestConflict) {
+		return &wallet.WithdrawRep{
+			Code: strconv.Itoa(4001),
+			Msg:  "request id already submitted with different params",
+			Hash: common.Hash{}.String(),
+		}, nil
+	}
 	if err != nil {
 		log.Error("submit withdraw fail", "err", err)
 		return &wallet.WithdrawRep{
@@ -36,30 +117,98 @@ func (s *RpcServer) SubmitWithdrawInfo(ctx context.Context, in *wallet.WithdrawR
 	return &wallet.WithdrawRep{
 		Code: strconv.Itoa(2000),
 		Msg:  "submit withdraw success",
-		Hash: common.Hash{}.String(),
+		Hash: withdraw.Hash.String(),
 	}, nil
 }
 
+// verifyWithdrawSignature 开启签名校验或请求带签名时，校验业务方的 EIP-712 签名并返回签名摘要
+func (s *RpcServer) verifyWithdrawSignature(auth *risk.WithdrawAuthorization, signature string) (string, error) {
+	if signature == "" && !s.WithdrawSignRequired {
+		return "", nil
+	}
+	signatureBytes, err := hexutil.Decode(signature)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", risk.ErrWithdrawUnauthorized, err)
+	}
+	auth.Signature = signatureBytes
+	return s.riskEngine.VerifyWithdrawAuthorization(auth, new(big.Int).SetUint64(uint64(s.ChainId)))
+}
+
 func (s *RpcServer) VerifyAddress(ctx context.Context, in *wallet.RiskVerifyAddressReq) (*wallet.RiskVerifyAddressRep, error) {
+	if !common.IsHexAddress(in.Address) {
+		return &wallet.RiskVerifyAddressRep{
+			Code:   strconv.Itoa(400),
+			Msg:    "invalid address",
+			Verify: false,
+		}, nil
+	}
+	result, err := s.riskEngine.VerifyAddress(common.HexToAddress(in.Address))
+	if err != nil {
+		log.Error("verify address fail", "address", in.Address, "err", err)
+		return &wallet.RiskVerifyAddressRep{
+			Code:   strconv.Itoa(500),
+			Msg:    "verify address fail",
+			Verify: false,
+		}, nil
+	}
 	return &wallet.RiskVerifyAddressRep{
 		Code:   strconv.Itoa(200),
-		Msg:    "success request",
-		Verify: true,
+		Msg:    riskResultMsg(result),
+		Verify: result.Pass(),
 	}, nil
 }
 
 func (s *RpcServer) VerifyWithdrawSign(ctx context.Context, in *wallet.RiskWithdrawVerifyReq) (*wallet.RiskWithdrawVerifyRep, error) {
+	result, found, err := s.riskEngine.VerifyWithdrawByHash(common.HexToHash(in.MsgHash))
+	if err != nil {
+		log.Error("verify withdraw fail", "msgHash", in.MsgHash, "err", err)
+		return &wallet.RiskWithdrawVerifyRep{
+			Code:   strconv.Itoa(500),
+			Msg:    "verify withdraw fail",
+			Verify: false,
+		}, nil
+	}
+	if !found {
+		return &wallet.RiskWithdrawVerifyRep{
+			Code:   strconv.Itoa(404),
+			Msg:    "withdraw not found",
+			Verify: false,
+		}, nil
+	}
 	return &wallet.RiskWithdrawVerifyRep{
 		Code:   strconv.Itoa(200),
-		Msg:    "success request",
-		Verify: true,
+		Msg:    riskResultMsg(result),
+		Verify: result.Pass(),
 	}, nil
 }
 
 func (s *RpcServer) VerifyRiskDOrWNotify(ctx context.Context, in *wallet.RiskDOrWNotifyVerifyReq) (*wallet.RiskDOrWNotifyVerifyRep, error) {
+	result, found, err := s.riskEngine.VerifyNotify(common.HexToHash(in.MsgHash))
+	if err != nil {
+		log.Error("verify deposit or withdraw notify fail", "msgHash", in.MsgHash, "err", err)
+		return &wallet.RiskDOrWNotifyVerifyRep{
+			Code:   strconv.Itoa(500),
+			Msg:    "verify notify fail",
+			Verify: false,
+		}, nil
+	}
+	if !found {
+		return &wallet.RiskDOrWNotifyVerifyRep{
+			Code:   strconv.Itoa(404),
+			Msg:    "deposit or withdraw not found",
+			Verify: false,
+		}, nil
+	}
 	return &wallet.RiskDOrWNotifyVerifyRep{
 		Code:   strconv.Itoa(200),
-		Msg:    "success request",
-		Verify: true,
+		Msg:    riskResultMsg(result),
+		Verify: result.Pass(),
 	}, nil
 }
+
+func riskResultMsg(result *risk.Result) string {
+	if result.Pass() {
+		return "success request"
+	}
+	return strings.Join(result.Triggered, "; ")
+}
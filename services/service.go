@@ -11,8 +11,10 @@ import (
 
 	"github.com/ethereum/go-ethereum/log"
 
+	"github.com/the-web3/eth-wallet/config"
 	"github.com/the-web3/eth-wallet/database"
 	"github.com/the-web3/eth-wallet/proto/wallet"
+	"github.com/the-web3/eth-wallet/risk"
 )
 
 const MaxRecvMessageSize = 1024 * 1024 * 300
@@ -20,11 +22,17 @@ const MaxRecvMessageSize = 1024 * 1024 * 300
 type RpcServerConfig struct {
 	GrpcHostname string
 	GrpcPort     int
+	AddressBook  config.AddressBookConfig
+
+	ChainId              uint
+	WithdrawSignRequired bool
 }
 
 type RpcServer struct {
 	*RpcServerConfig
-	db *database.DB
+	db         *database.DB
+	readDB     *database.DB
+	riskEngine *risk.Engine
 
 	wallet.UnimplementedWalletServiceServer
 	stopped atomic.Bool
@@ -32,6 +40,9 @@ type RpcServer struct {
 
 func (s *RpcServer) Stop(ctx context.Context) error {
 	s.stopped.Store(true)
+	if s.readDB != s.db {
+		return s.readDB.Close()
+	}
 	return nil
 }
 
@@ -39,10 +50,13 @@ func (s *RpcServer) Stopped() bool {
 	return s.stopped.Load()
 }
 
-func NewRpcServer(db *database.DB, config *RpcServerConfig) (*RpcServer, error) {
+// NewRpcServer readDB 用于只读查询，未启用从库时与 db 相同
+func NewRpcServer(db *database.DB, readDB *database.DB, config *RpcServerConfig) (*RpcServer, error) {
 	return &RpcServer{
 		RpcServerConfig: config,
 		db:              db,
+		readDB:          readDB,
+		riskEngine:      risk.NewEngine(db),
 	}, nil
 }
 
@@ -0,0 +1,222 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+
+	"github.com/google/uuid"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/the-web3/eth-wallet/database"
+	"github.com/the-web3/eth-wallet/proto/wallet"
+	"github.com/the-web3/eth-wallet/risk"
+)
+
+// findWithdraw 按 guid 或 consumer_token + request_id 查询提现
+func (s *RpcServer) findWithdraw(view database.WithdrawsView, guid string, consumerToken string, requestId string) (*database.Withdraws, error) {
+	if guid != "" {
+		withdrawGuid, err := uuid.Parse(guid)
+		if err != nil {
+			return nil, nil
+		}
+		return view.QueryWithdrawsByGuid(withdrawGuid)
+	}
+	if requestId == "" {
+		return nil, nil
+	}
+	return view.QueryWithdrawsByRequestId(consumerToken, requestId)
+}
+
+func (s *RpcServer) CancelWithdraw(ctx context.Context, in *wallet.CancelWithdrawReq) (*wallet.CancelWithdrawRep, error) {
+	withdraw, err := s.findWithdraw(s.db.Withdraws, in.Guid, in.ConsumerToken, in.RequestId)
+	if err != nil {
+		log.Error("query withdraw fail", "err", err)
+		return &wallet.CancelWithdrawRep{
+			Code: strconv.Itoa(4000),
+			Msg:  "cancel withdraw fail",
+		}, nil
+	}
+	if withdraw == nil {
+		return &wallet.CancelWithdrawRep{
+			Code: strconv.Itoa(4005),
+			Msg:  "withdraw not found",
+		}, nil
+	}
+	cancelled, err := s.db.Withdraws.CancelPendingWithdraw(withdraw.GUID, in.Reason)
+	if err == nil && cancelled {
+		return &wallet.CancelWithdrawRep{
+			Code:   strconv.Itoa(2000),
+			Msg:    "withdraw cancelled",
+			Status: 9,
+		}, nil
+	}
+	var requested bool
+	if err == nil {
+		requested, err = s.db.Withdraws.RequestWithdrawCancel(withdraw.GUID, in.Reason)
+	}
+	if err != nil {
+		log.Error("cancel withdraw fail", "guid", withdraw.GUID, "err", err)
+		return &wallet.CancelWithdrawRep{
+			Code: strconv.Itoa(4000),
+			Msg:  "cancel withdraw fail",
+		}, nil
+	}
+	if !requested {
+		return &wallet.CancelWithdrawRep{
+			Code:   strconv.Itoa(4006),
+			Msg:    fmt.Sprintf("withdraw in status %d can not be cancelled", withdraw.Status),
+			Status: uint32(withdraw.Status),
+		}, nil
+	}
+	return &wallet.CancelWithdrawRep{
+		Code:   strconv.Itoa(2000),
+		Msg:    "withdraw cancel requested on chain",
+		Status: 10,
+	}, nil
+}
+
+func (s *RpcServer) AmendWithdraw(ctx context.Context, in *wallet.AmendWithdrawReq) (*wallet.AmendWithdrawRep, error) {
+	amountBig, ok := new(big.Int).SetString(in.Amount, 10)
+	if !ok || !common.IsHexAddress(in.ToAddress) {
+		return &wallet.AmendWithdrawRep{
+			Code: strconv.Itoa(4000),
+			Msg:  "invalid to address or amount",
+		}, nil
+	}
+	toAddress := common.HexToAddress(in.ToAddress)
+	withdraw, err := s.findWithdraw(s.db.Withdraws, in.Guid, in.ConsumerToken, in.RequestId)
+	if err != nil {
+		log.Error("query withdraw fail", "err", err)
+		return &wallet.AmendWithdrawRep{
+			Code: strconv.Itoa(4000),
+			Msg:  "amend withdraw fail",
+		}, nil
+	}
+	if withdraw == nil {
+		return &wallet.AmendWithdrawRep{
+			Code: strconv.Itoa(4005),
+			Msg:  "withdraw not found",
+		}, nil
+	}
+
+	signDigest, err := s.verifyWithdrawSignature(&risk.WithdrawAuthorization{
+		ConsumerToken: withdraw.ConsumerToken,
+		RequestId:     withdraw.RequestId,
+		FromAddress:   withdraw.FromAddress,
+		ToAddress:     toAddress,
+		TokenAddress:  withdraw.TokenAddress,
+		Amount:        amountBig,
+		Deadline:      in.Deadline,
+	}, in.Signature)
+	if errors.Is(err, risk.ErrWithdrawUnauthorized) {
+		return &wallet.AmendWithdrawRep{
+			Code: strconv.Itoa(4004),
+			Msg:  err.Error(),
+		}, nil
+	}
+	if err != nil {
+		return s.amendWithdrawFail(withdraw, err), nil
+	}
+	if s.AddressBook.Enforce {
+		err = s.riskEngine.CheckWithdrawDestination(withdraw.UserUid, toAddress)
+		if errors.Is(err, risk.ErrDestinationNotAllowed) {
+			return &wallet.AmendWithdrawRep{
+				Code: strconv.Itoa(4003),
+				Msg:  err.Error(),
+			}, nil
+		}
+		if err != nil {
+			return s.amendWithdrawFail(withdraw, err), nil
+		}
+	}
+	err = s.riskEngine.CheckAmendLimits(withdraw, amountBig)
+	if errors.Is(err, risk.ErrWithdrawLimitExceeded) {
+		return &wallet.AmendWithdrawRep{
+			Code: strconv.Itoa(4002),
+			Msg:  err.Error(),
+		}, nil
+	}
+	if err != nil {
+		return s.amendWithdrawFail(withdraw, err), nil
+	}
+	detail := fmt.Sprintf("to %s amount %s -> to %s amount %s", withdraw.ToAddress.String(), withdraw.Amount.String(), toAddress.String(), amountBig.String())
+	amended, err := s.db.Withdraws.AmendPendingWithdraw(withdraw.GUID, toAddress, amountBig, signDigest, detail)
+	if err != nil {
+		return s.amendWithdrawFail(withdraw, err), nil
+	}
+	if !amended {
+		return &wallet.AmendWithdrawRep{
+			Code: strconv.Itoa(4006),
+			Msg:  fmt.Sprintf("withdraw in status %d can not be amended", withdraw.Status),
+		}, nil
+	}
+	return &wallet.AmendWithdrawRep{
+		Code: strconv.Itoa(2000),
+		Msg:  "amend withdraw success",
+	}, nil
+}
+
+func (s *RpcServer) amendWithdrawFail(withdraw *database.Withdraws, err error) *wallet.AmendWithdrawRep {
+	log.Error("amend withdraw fail", "guid", withdraw.GUID, "err", err)
+	return &wallet.AmendWithdrawRep{
+		Code: strconv.Itoa(4000),
+		Msg:  "amend withdraw fail",
+	}
+}
+
+func (s *RpcServer) GetWithdrawDetail(ctx context.Context, in *wallet.WithdrawDetailReq) (*wallet.WithdrawDetailRep, error) {
+	withdraw, err := s.findWithdraw(s.readDB.Withdraws, in.Guid, in.ConsumerToken, in.RequestId)
+	var eventList []database.WithdrawEvents
+	if err == nil && withdraw != nil {
+		eventList, err = s.readDB.WithdrawEvents.QueryWithdrawEvents(withdraw.GUID)
+	}
+	if err != nil {
+		log.Error("query withdraw detail fail", "err", err)
+		return &wallet.WithdrawDetailRep{
+			Code: strconv.Itoa(4000),
+			Msg:  "query withdraw detail fail",
+		}, nil
+	}
+	if withdraw == nil {
+		return &wallet.WithdrawDetailRep{
+			Code: strconv.Itoa(4005),
+			Msg:  "withdraw not found",
+		}, nil
+	}
+	var events []*wallet.WithdrawEvent
+	for _, event := range eventList {
+		events = append(events, &wallet.WithdrawEvent{
+			Event:      event.Event,
+			FromStatus: uint32(event.FromStatus),
+			ToStatus:   uint32(event.ToStatus),
+			Detail:     event.Detail,
+			Timestamp:  event.Timestamp,
+		})
+	}
+	return &wallet.WithdrawDetailRep{
+		Code: strconv.Itoa(2000),
+		Msg:  "query withdraw detail success",
+		Withdraw: &wallet.WithdrawDetail{
+			Guid:          withdraw.GUID.String(),
+			ConsumerToken: withdraw.ConsumerToken,
+			RequestId:     withdraw.RequestId,
+			UserUid:       withdraw.UserUid,
+			Hash:          withdraw.Hash.String(),
+			FromAddress:   withdraw.FromAddress.String(),
+			ToAddress:     withdraw.ToAddress.String(),
+			TokenAddress:  withdraw.TokenAddress.String(),
+			Amount:        withdraw.Amount.String(),
+			Status:        uint32(withdraw.Status),
+			BatchGuid:     withdraw.BatchGuid,
+			FailReason:    withdraw.FailReason,
+			BlockNumber:   withdraw.BlockNumber.String(),
+			Timestamp:     withdraw.Timestamp,
+		},
+		Events: events,
+	}, nil
+}
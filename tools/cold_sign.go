@@ -0,0 +1,82 @@
+package tools
+
+import (
+	"errors"
+
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/urfave/cli/v2"
+
+	"github.com/the-web3/eth-wallet/flags"
+	"github.com/the-web3/eth-wallet/wallet"
+	"github.com/the-web3/eth-wallet/wallet/coldsign"
+)
+
+// ColdExportTools 导出待冷钱包离线签名的交易文件
+func ColdExportTools(ctx *cli.Context, coldOffline *wallet.ColdOffline) error {
+	file, err := coldOffline.Export()
+	if err != nil {
+		return err
+	}
+	path := ctx.String(flags.ColdTxFileFlag.Name)
+	if err := coldsign.WriteFile(path, file); err != nil {
+		log.Error("write unsigned tx file fail", "path", path, "err", err)
+		return err
+	}
+	log.Info("export cold wallet txs", "count", len(file.Transactions), "path", path)
+	return nil
+}
+
+// ColdSignTools 在离线机器上用冷钱包私钥文件签名交易文件，不连接数据库和节点
+func ColdSignTools(ctx *cli.Context) error {
+	keyFile := ctx.String(flags.ColdKeyFileFlag.Name)
+	if keyFile == "" {
+		return errors.New("cold key file is required to sign txs")
+	}
+	file, err := coldsign.ReadFile(ctx.String(flags.ColdTxFileFlag.Name))
+	if err != nil {
+		log.Error("read unsigned tx file fail", "err", err)
+		return err
+	}
+	privateKey, err := coldsign.LoadKeyFile(keyFile)
+	if err != nil {
+		log.Error("read cold key file fail", "err", err)
+		return err
+	}
+	if err := coldsign.SignFile(file, privateKey); err != nil {
+		log.Error("sign tx file fail", "err", err)
+		return err
+	}
+	path := ctx.String(flags.ColdSignedTxFileFlag.Name)
+	if err := coldsign.WriteFile(path, file); err != nil {
+		log.Error("write signed tx file fail", "path", path, "err", err)
+		return err
+	}
+	log.Info("sign cold wallet txs", "count", len(file.Transactions), "path", path)
+	return nil
+}
+
+// ColdImportTools 校验离线签名后的交易文件并广播
+func ColdImportTools(ctx *cli.Context, coldOffline *wallet.ColdOffline) error {
+	file, err := coldsign.ReadFile(ctx.String(flags.ColdSignedTxFileFlag.Name))
+	if err != nil {
+		log.Error("read signed tx file fail", "err", err)
+		return err
+	}
+	resultList, err := coldOffline.Import(ctx.Context, file)
+	if err != nil {
+		return err
+	}
+	failed := 0
+	for _, result := range resultList {
+		if result.Error != "" {
+			failed++
+			log.Error("import cold wallet tx fail", "id", result.Id, "hash", result.Hash, "err", result.Error)
+			continue
+		}
+		log.Info("import cold wallet tx", "id", result.Id, "hash", result.Hash)
+	}
+	if failed > 0 {
+		return errors.New("some cold wallet txs failed to import")
+	}
+	return nil
+}
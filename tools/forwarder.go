@@ -0,0 +1,73 @@
+package tools
+
+import (
+	"errors"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/google/uuid"
+	"github.com/urfave/cli/v2"
+
+	"github.com/the-web3/eth-wallet/config"
+	"github.com/the-web3/eth-wallet/database"
+	"github.com/the-web3/eth-wallet/flags"
+	"github.com/the-web3/eth-wallet/wallet/ethereum"
+)
+
+// CreateForwarderAddressTools 为每个用户按工厂合约和 salt 计算 CREATE2 充值转发地址，已有转发地址的用户跳过
+func CreateForwarderAddressTools(ctx *cli.Context, db *database.DB, chainConf *config.ChainConfig) error {
+	if !common.IsHexAddress(chainConf.ForwarderFactory) {
+		return errors.New("forwarder factory is required to create forwarder addresses")
+	}
+	initCodeHash := common.FromHex(chainConf.ForwarderInitCodeHash)
+	if len(initCodeHash) != common.HashLength {
+		return errors.New("forwarder init code hash must be 32 bytes")
+	}
+	factory := common.HexToAddress(chainConf.ForwarderFactory)
+
+	var addressList []database.Addresses
+	var balanceList []database.Balances
+	for _, userUid := range ctx.StringSlice(flags.ForwarderUserUidFlag.Name) {
+		exist, err := db.Addresses.QueryForwarderByUserUid(userUid)
+		if err != nil {
+			log.Error("query forwarder address fail", "userUid", userUid, "err", err)
+			return err
+		}
+		if exist != nil {
+			log.Info("forwarder address exists", "userUid", userUid, "address", exist.Address)
+			continue
+		}
+		salt := ethereum.ForwarderSalt(userUid)
+		address := ethereum.ForwarderAddress(factory, salt, common.BytesToHash(initCodeHash))
+		addressList = append(addressList, database.Addresses{
+			GUID:          uuid.New(),
+			UserUid:       userUid,
+			Address:       address,
+			AddressType:   0,
+			ForwarderSalt: salt.String(),
+			Timestamp:     uint64(time.Now().Unix()),
+		})
+		balanceList = append(balanceList, database.Balances{
+			GUID:         uuid.New(),
+			Address:      address,
+			TokenAddress: common.Address{},
+			AddressType:  0,
+			Balance:      big.NewInt(0),
+			LockBalance:  big.NewInt(0),
+			Timestamp:    uint64(time.Now().Unix()),
+		})
+		log.Info("create forwarder address", "userUid", userUid, "address", address, "salt", salt)
+	}
+	if len(addressList) == 0 {
+		return nil
+	}
+	return db.Transaction(func(tx *database.DB) error {
+		if err := tx.Addresses.StoreAddressess(addressList, uint64(len(addressList))); err != nil {
+			log.Error("store address error", "err", err)
+			return err
+		}
+		return tx.Balances.StoreBalances(balanceList, uint64(len(balanceList)))
+	})
+}
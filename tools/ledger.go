@@ -0,0 +1,36 @@
+package tools
+
+import (
+	"errors"
+
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/urfave/cli/v2"
+
+	"github.com/the-web3/eth-wallet/database"
+)
+
+// LedgerCheckTools 校验每个 journal 借贷平衡，且 balances 与分录推导的余额一致
+func LedgerCheckTools(ctx *cli.Context, db *database.DB) error {
+	journalList, err := db.LedgerEntries.QueryUnbalancedJournals()
+	if err != nil {
+		log.Error("query unbalanced journals fail", "err", err)
+		return err
+	}
+	for _, journal := range journalList {
+		log.Error("unbalanced journal", "journalGuid", journal)
+	}
+	driftList, err := db.LedgerEntries.QueryBalanceDrift()
+	if err != nil {
+		log.Error("query balance drift fail", "err", err)
+		return err
+	}
+	for _, drift := range driftList {
+		log.Error("balance differs from ledger", "address", drift.Address, "tokenAddress", drift.TokenAddress,
+			"balance", drift.Balance, "ledgerBalance", drift.LedgerBalance, "lockBalance", drift.LockBalance, "ledgerLockBalance", drift.LedgerLockBalance)
+	}
+	if len(journalList) > 0 || len(driftList) > 0 {
+		return errors.New("ledger check failed")
+	}
+	log.Info("ledger check passed")
+	return nil
+}
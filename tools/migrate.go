@@ -0,0 +1,65 @@
+package tools
+
+import (
+	"errors"
+	"math"
+	"strconv"
+
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/urfave/cli/v2"
+
+	"github.com/the-web3/eth-wallet/database"
+)
+
+func MigrateUpTools(ctx *cli.Context, db *database.DB, migrationsFolder string) error {
+	return db.MigrateTo(migrationsFolder, math.MaxUint64)
+}
+
+// MigrateDownTools 回滚最近的迁移，参数为回滚个数，默认 1 个
+func MigrateDownTools(ctx *cli.Context, db *database.DB, migrationsFolder string) error {
+	steps := 1
+	if ctx.Args().Present() {
+		var err error
+		steps, err = strconv.Atoi(ctx.Args().First())
+		if err != nil || steps <= 0 {
+			return errors.New("steps must be a positive integer")
+		}
+	}
+	return db.MigrateDown(migrationsFolder, steps)
+}
+
+// MigrateToTools 迁移到指定版本，高于当前版本时执行迁移，低于当前版本时回滚
+func MigrateToTools(ctx *cli.Context, db *database.DB, migrationsFolder string) error {
+	version, err := strconv.ParseUint(ctx.Args().First(), 10, 64)
+	if err != nil {
+		return errors.New("target version is required")
+	}
+	return db.MigrateTo(migrationsFolder, version)
+}
+
+// MigrateStatusTools 输出每个迁移的执行状态，已执行迁移的文件被修改时返回错误
+func MigrateStatusTools(ctx *cli.Context, db *database.DB, migrationsFolder string) error {
+	statusList, err := db.QueryMigrationStatus(migrationsFolder)
+	if err != nil {
+		log.Error("query migration status fail", "err", err)
+		return err
+	}
+	var modified bool
+	for _, status := range statusList {
+		switch {
+		case status.Missing:
+			log.Warn("migration applied but file not found", "version", status.Version, "name", status.Name, "appliedAt", status.AppliedAt)
+		case status.ChecksumMismatch:
+			modified = true
+			log.Error("migration modified after applied", "version", status.Version, "name", status.Name, "appliedAt", status.AppliedAt)
+		case status.Applied:
+			log.Info("migration applied", "version", status.Version, "name", status.Name, "appliedAt", status.AppliedAt)
+		default:
+			log.Info("migration pending", "version", status.Version, "name", status.Name)
+		}
+	}
+	if modified {
+		return database.ErrMigrationChecksum
+	}
+	return nil
+}
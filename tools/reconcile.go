@@ -0,0 +1,25 @@
+package tools
+
+import (
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/urfave/cli/v2"
+
+	"github.com/the-web3/eth-wallet/wallet"
+)
+
+// ReconcileTools 立即执行一次链上余额对账，存在超过容差的差异时返回错误
+func ReconcileTools(ctx *cli.Context, reconciler *wallet.Reconciler) error {
+	reconciliation, err := reconciler.Reconcile()
+	if err != nil {
+		return err
+	}
+	if reconciliation == nil {
+		return nil
+	}
+	log.Info("reconciliation report", "guid", reconciliation.GUID, "blockNumber", reconciliation.BlockNumber,
+		"checked", reconciliation.Checked, "mismatched", reconciliation.Mismatched, "alerted", reconciliation.Alerted)
+	if reconciliation.Alerted > 0 {
+		return wallet.ErrReconcileAlert
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package tools
+
+import (
+	"errors"
+
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/urfave/cli/v2"
+
+	"github.com/the-web3/eth-wallet/database"
+	"github.com/the-web3/eth-wallet/wallet/kms"
+)
+
+const rekeyBatchSize = 500
+
+// RekeyAddressesTools 用当前主密钥重新包装所有地址的数据密钥，明文私钥和未绑定地址的历史密文会重新加密并绑定地址
+func RekeyAddressesTools(ctx *cli.Context, db *database.DB, keyManager kms.KMS) error {
+	if keyManager == nil {
+		return errors.New("kms master key is required to rekey private keys")
+	}
+	total := 0
+	for {
+		addressList, err := db.Addresses.QueryAddressesToRekey(keyManager.KeyId(), kms.AddressBoundPrefix, rekeyBatchSize)
+		if err != nil {
+			log.Error("query addresses to rekey fail", "err", err)
+			return err
+		}
+		if len(addressList) == 0 {
+			break
+		}
+		err = db.Transaction(func(tx *database.DB) error {
+			for _, address := range addressList {
+				privateKey, dataKey, keyId := address.PrivateKey, "", ""
+				switch {
+				case address.DataKey == "":
+					privateKey, dataKey, keyId, err = kms.SealPrivateKey(keyManager, address.PrivateKey, address.Address)
+				case !kms.IsAddressBound(address.PrivateKey):
+					privateKey, err = kms.OpenPrivateKey(keyManager, address.PrivateKey, address.DataKey, address.KeyId, address.Address)
+					if err == nil {
+						privateKey, dataKey, keyId, err = kms.SealPrivateKey(keyManager, privateKey, address.Address)
+					}
+				default:
+					dataKey, keyId, err = kms.RewrapKey(keyManager, address.DataKey, address.KeyId)
+				}
+				if err != nil {
+					log.Error("rekey address fail", "address", address.Address, "keyId", address.KeyId, "err", err)
+					return err
+				}
+				if err := tx.Addresses.UpdateAddressPrivateKey(address.GUID, privateKey, dataKey, keyId); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		total += len(addressList)
+		log.Info("rekey addresses", "count", len(addressList), "total", total)
+	}
+	log.Info("rekey addresses finished", "total", total, "keyId", keyManager.KeyId())
+	return nil
+}
@@ -0,0 +1,31 @@
+package tools
+
+import (
+	"encoding/json"
+	"os"
+
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/urfave/cli/v2"
+
+	"github.com/the-web3/eth-wallet/flags"
+	"github.com/the-web3/eth-wallet/wallet"
+)
+
+// ProofOfReservesTools 生成储备证明并把各币种的根、负债总额和链上持仓发布到文件
+func ProofOfReservesTools(ctx *cli.Context, reporter *wallet.ReserveReporter) error {
+	reportList, err := reporter.Generate()
+	if err != nil {
+		return err
+	}
+	content, err := json.MarshalIndent(reportList, "", "  ")
+	if err != nil {
+		return err
+	}
+	path := ctx.String(flags.ReservesFileFlag.Name)
+	if err := os.WriteFile(path, content, 0o644); err != nil {
+		log.Error("write reserves file fail", "path", path, "err", err)
+		return err
+	}
+	log.Info("publish proof of reserves", "tokens", len(reportList), "path", path)
+	return nil
+}
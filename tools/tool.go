@@ -1,7 +1,10 @@
 package tools
 
 import (
+	"encoding/hex"
+	"errors"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/google/uuid"
 	"github.com/urfave/cli/v2"
@@ -9,17 +12,69 @@ import (
 
 	"github.com/the-web3/eth-wallet/database"
 	"github.com/the-web3/eth-wallet/wallet/ethereum"
+	"github.com/the-web3/eth-wallet/wallet/hdwallet"
+	"github.com/the-web3/eth-wallet/wallet/kms"
 )
 
-func CreateAddressTools(ctx *cli.Context, db *database.DB) error {
+// CreateAddressTools 配置了助记词时按 HD 路径派生地址只保存派生序号，否则生成随机私钥并加密保存
+// 使用助记词时冷钱包取配置的地址，不能与热钱包从同一助记词派生
+func CreateAddressTools(ctx *cli.Context, db *database.DB, keyManager kms.KMS, hd *hdwallet.HDWallet, coldWalletAddress string) error {
+	if hd == nil && keyManager == nil {
+		return errors.New("hd mnemonic or kms master key is required to create addresses")
+	}
+	var startIndex uint32
+	var coldWallet *common.Address
+	if hd != nil {
+		if !common.IsHexAddress(coldWalletAddress) {
+			return errors.New("hd cold wallet address is required when deriving addresses from a mnemonic")
+		}
+		coldAddress := common.HexToAddress(coldWalletAddress)
+		existing, err := db.Addresses.QueryAddressesByToAddress(&coldAddress)
+		if err != nil {
+			log.Error("query cold wallet error", "err", err)
+			return err
+		}
+		if existing == nil {
+			coldWallet = &coldAddress
+		}
+		nextIndex, err := db.Addresses.NextDerivationIndex()
+		if err != nil {
+			log.Error("query next derivation index error", "err", err)
+			return err
+		}
+		startIndex = nextIndex
+	}
 	var addressList []database.Addresses
 	var balanceList []database.Balances
 	for index := 0; index < 100; index++ {
-		addressStruct, err := ethereum.CreateAddressByKeyPairs()
+		var addressStruct *ethereum.EthAddress
+		var derivationIndex *uint32
+		var sealedKey, dataKey, keyId string
+		var err error
+		if hd != nil && index == 2 {
+			// 冷钱包已保存时不重复写入
+			if coldWallet == nil {
+				continue
+			}
+			addressStruct = &ethereum.EthAddress{Address: coldWallet.String()}
+		} else if hd != nil {
+			childIndex := startIndex + uint32(index)
+			addressStruct, err = deriveAddress(hd, childIndex)
+			derivationIndex = &childIndex
+		} else {
+			addressStruct, err = ethereum.CreateAddressByKeyPairs()
+		}
 		if err != nil {
 			log.Error("create address error", err)
 			return err
 		}
+		if addressStruct.PrivateKey != "" {
+			sealedKey, dataKey, keyId, err = kms.SealPrivateKey(keyManager, addressStruct.PrivateKey, common.HexToAddress(addressStruct.Address))
+			if err != nil {
+				log.Error("encrypt private key error", "err", err)
+				return err
+			}
+		}
 		var AddressType uint8
 		var UserUid string
 		if index == 1 {
@@ -33,13 +88,16 @@ func CreateAddressTools(ctx *cli.Context, db *database.DB) error {
 			AddressType = 0
 		}
 		addressItem := database.Addresses{
-			GUID:        uuid.New(),
-			UserUid:     UserUid,
-			Address:     common.Address(common.FromHex(addressStruct.Address)),
-			AddressType: AddressType,
-			PrivateKey:  addressStruct.PrivateKey,
-			PublicKey:   addressStruct.PublicKey,
-			Timestamp:   uint64(index + 10000),
+			GUID:            uuid.New(),
+			UserUid:         UserUid,
+			Address:         common.Address(common.FromHex(addressStruct.Address)),
+			AddressType:     AddressType,
+			PrivateKey:      sealedKey,
+			PublicKey:       addressStruct.PublicKey,
+			DataKey:         dataKey,
+			KeyId:           keyId,
+			DerivationIndex: derivationIndex,
+			Timestamp:       uint64(index + 10000),
 		}
 		addressList = append(addressList, addressItem)
 
@@ -66,3 +124,14 @@ func CreateAddressTools(ctx *cli.Context, db *database.DB) error {
 	}
 	return nil
 }
+
+func deriveAddress(hd *hdwallet.HDWallet, index uint32) (*ethereum.EthAddress, error) {
+	privateKey, err := hd.DerivePrivateKey(index)
+	if err != nil {
+		return nil, err
+	}
+	return &ethereum.EthAddress{
+		PublicKey: hex.EncodeToString(crypto.FromECDSAPub(&privateKey.PublicKey)),
+		Address:   crypto.PubkeyToAddress(privateKey.PublicKey).String(),
+	}, nil
+}
@@ -0,0 +1,52 @@
+package tools
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/urfave/cli/v2"
+
+	"github.com/the-web3/eth-wallet/database"
+	"github.com/the-web3/eth-wallet/wallet/hdwallet"
+)
+
+const verifyBatchSize = 1000
+
+// VerifyHDAddressesTools 用助记词重新派生每个 HD 地址，与数据库中保存的地址逐一比对
+func VerifyHDAddressesTools(ctx *cli.Context, db *database.DB, hd *hdwallet.HDWallet) error {
+	if hd == nil {
+		return errors.New("hd mnemonic is required to verify addresses")
+	}
+	total, mismatch := 0, 0
+	afterIndex := int64(-1)
+	for {
+		addressList, err := db.Addresses.QueryDerivedAddresses(afterIndex, verifyBatchSize)
+		if err != nil {
+			log.Error("query derived addresses fail", "err", err)
+			return err
+		}
+		if len(addressList) == 0 {
+			break
+		}
+		for _, address := range addressList {
+			index := *address.DerivationIndex
+			derived, err := hd.DeriveAddress(index)
+			if err != nil {
+				log.Error("derive address fail", "index", index, "err", err)
+				return err
+			}
+			if derived != address.Address {
+				mismatch++
+				log.Error("hd address mismatch", "index", index, "stored", address.Address, "derived", derived)
+			}
+			afterIndex = int64(index)
+		}
+		total += len(addressList)
+	}
+	log.Info("verify hd addresses finished", "total", total, "mismatch", mismatch)
+	if mismatch > 0 {
+		return fmt.Errorf("%d of %d hd addresses do not match the seed", mismatch, total)
+	}
+	return nil
+}
@@ -0,0 +1,357 @@
+package wallet
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/the-web3/eth-wallet/config"
+	"github.com/the-web3/eth-wallet/database"
+	ethwallet "github.com/the-web3/eth-wallet/wallet/ethereum"
+	"github.com/the-web3/eth-wallet/wallet/node"
+	"github.com/the-web3/eth-wallet/wallet/signer"
+)
+
+// 需要真实 postgres，设置 ETH_WALLET_TEST_DB_HOST 等环境变量后运行，否则跳过
+func newStressDB(t *testing.T) *database.DB {
+	host := os.Getenv("ETH_WALLET_TEST_DB_HOST")
+	if host == "" {
+		t.Skip("ETH_WALLET_TEST_DB_HOST not set")
+	}
+	port, _ := strconv.Atoi(os.Getenv("ETH_WALLET_TEST_DB_PORT"))
+	db, err := database.NewDB(context.Background(), config.DBConfig{
+		Host:     host,
+		Port:     port,
+		Name:     os.Getenv("ETH_WALLET_TEST_DB_NAME"),
+		User:     os.Getenv("ETH_WALLET_TEST_DB_USER"),
+		Password: os.Getenv("ETH_WALLET_TEST_DB_PASSWORD"),
+	})
+	require.NoError(t, err)
+	require.NoError(t, db.ExecuteSQLMigration("../migrations"))
+	t.Cleanup(func() { _ = db.Close() })
+	return db
+}
+
+func newStressKey(t *testing.T) (*ecdsa.PrivateKey, common.Address) {
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	return key, crypto.PubkeyToAddress(key.PublicKey)
+}
+
+// stressChain 内存中的假链：广播的交易进入待打包列表，每次出块打包全部待打包交易和一笔外部充值，只记录 token 余额
+type stressChain struct {
+	node.EthClient
+
+	mu        sync.Mutex
+	chainId   *big.Int
+	token     common.Address
+	depositor *ecdsa.PrivateKey
+	head      *types.Header
+	nonces    map[common.Address]uint64
+	pending   []*types.Transaction
+	txs       map[common.Hash]*types.Transaction
+	receipts  map[common.Hash]*types.Receipt
+	blocks    map[uint64]*node.RpcBlock
+	balances  map[common.Address]*big.Int
+	failSend  bool
+}
+
+func newStressChain(t *testing.T, chainId *big.Int, token common.Address, head *types.Header) *stressChain {
+	depositor, _ := newStressKey(t)
+	return &stressChain{
+		chainId:   chainId,
+		token:     token,
+		depositor: depositor,
+		head:      head,
+		nonces:    make(map[common.Address]uint64),
+		txs:       make(map[common.Hash]*types.Transaction),
+		receipts:  make(map[common.Hash]*types.Receipt),
+		blocks:    make(map[uint64]*node.RpcBlock),
+		balances:  make(map[common.Address]*big.Int),
+	}
+}
+
+func (c *stressChain) SuggestGasPrice() (*big.Int, error) {
+	return big.NewInt(1), nil
+}
+
+func (c *stressChain) CallContract(ethereum.CallMsg) ([]byte, error) {
+	return nil, nil
+}
+
+// 交易广播后立即进入待打包列表，latest 与 pending nonce 相同
+func (c *stressChain) TxCountByAddress(address common.Address) (hexutil.Uint64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return hexutil.Uint64(c.nonces[address]), nil
+}
+
+func (c *stressChain) PendingTxCountByAddress(address common.Address) (hexutil.Uint64, error) {
+	return c.TxCountByAddress(address)
+}
+
+func (c *stressChain) SendRawTransaction(rawTx string) error {
+	tx, from, err := decodeSignedTx(rawTx)
+	if err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.failSend {
+		return errors.New("broadcast unavailable")
+	}
+	if tx.Nonce() != c.nonces[from] {
+		return fmt.Errorf("invalid nonce %d for %s, expect %d", tx.Nonce(), from, c.nonces[from])
+	}
+	c.nonces[from]++
+	c.pending = append(c.pending, tx)
+	c.txs[tx.Hash()] = tx
+	return nil
+}
+
+func (c *stressChain) TxByHash(hash common.Hash) (*types.Transaction, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if tx, ok := c.txs[hash]; ok {
+		return tx, nil
+	}
+	return nil, ethereum.NotFound
+}
+
+func (c *stressChain) TxReceiptByHash(hash common.Hash) (*types.Receipt, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if receipt, ok := c.receipts[hash]; ok {
+		return receipt, nil
+	}
+	return nil, ethereum.NotFound
+}
+
+func (c *stressChain) BlockByNumber(number *big.Int) (*node.RpcBlock, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if block, ok := c.blocks[number.Uint64()]; ok {
+		return block, nil
+	}
+	return nil, ethereum.NotFound
+}
+
+func (c *stressChain) balanceOf(address common.Address) *big.Int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if balance, ok := c.balances[address]; ok {
+		return new(big.Int).Set(balance)
+	}
+	return big.NewInt(0)
+}
+
+// mine 出一个块，包含一笔外部地址向 to 的 token 充值和全部待打包交易，余额不足的转账回执失败
+func (c *stressChain) mine(to common.Address, amount *big.Int) (*types.Header, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	depositorAddress := crypto.PubkeyToAddress(c.depositor.PublicKey)
+	depositTx, err := types.SignTx(types.NewTx(&types.DynamicFeeTx{
+		ChainID:   c.chainId,
+		Nonce:     c.nonces[depositorAddress],
+		GasTipCap: big.NewInt(1),
+		GasFeeCap: big.NewInt(1),
+		Gas:       TokenGasLimit,
+		To:        &c.token,
+		Value:     big.NewInt(0),
+		Data:      ethwallet.BuildErc20Data(to, amount),
+	}), types.LatestSignerForChainID(c.chainId), c.depositor)
+	if err != nil {
+		return nil, err
+	}
+	c.nonces[depositorAddress]++
+	c.txs[depositTx.Hash()] = depositTx
+
+	header := &types.Header{
+		ParentHash: c.head.Hash(),
+		Number:     new(big.Int).Add(c.head.Number, big.NewInt(1)),
+		Time:       uint64(time.Now().Unix()),
+	}
+	block := &node.RpcBlock{Hash: header.Hash(), BaseFee: "1"}
+	for i, tx := range append([]*types.Transaction{depositTx}, c.pending...) {
+		from, err := types.Sender(types.LatestSignerForChainID(c.chainId), tx)
+		if err != nil {
+			return nil, err
+		}
+		status := types.ReceiptStatusFailed
+		if *tx.To() == c.token && len(tx.Data()) == 68 {
+			recipient := common.BytesToAddress(tx.Data()[4:36])
+			value := new(big.Int).SetBytes(tx.Data()[36:68])
+			balance, ok := c.balances[from]
+			if !ok {
+				balance = big.NewInt(0)
+			}
+			if from == depositorAddress || balance.Cmp(value) >= 0 {
+				status = types.ReceiptStatusSuccessful
+				if from != depositorAddress {
+					c.balances[from] = new(big.Int).Sub(balance, value)
+				}
+				if _, ok := c.balances[recipient]; !ok {
+					c.balances[recipient] = big.NewInt(0)
+				}
+				c.balances[recipient].Add(c.balances[recipient], value)
+			}
+		}
+		c.receipts[tx.Hash()] = &types.Receipt{
+			TxHash:            tx.Hash(),
+			Status:            status,
+			BlockHash:         header.Hash(),
+			BlockNumber:       header.Number,
+			GasUsed:           tx.Gas(),
+			EffectiveGasPrice: big.NewInt(1),
+			TransactionIndex:  uint(i),
+		}
+		block.Transactions = append(block.Transactions, node.TransactionList{To: strings.ToLower(tx.To().String()), Hash: tx.Hash().String()})
+	}
+	c.blocks[header.Number.Uint64()] = block
+	c.pending = nil
+	c.head = header
+	return header, nil
+}
+
+func (c *stressChain) pendingCount() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.pending)
+}
+
+// TestConcurrentBalanceUpdates 扫块充值、提现、归集三个 worker 对假链并发运行，结束后余额不丢失、不为负，且与账本和链上余额一致
+func TestConcurrentBalanceUpdates(t *testing.T) {
+	db := newStressDB(t)
+	chainId := big.NewInt(1337)
+	// 每次使用新的币种和地址，与库中已有数据隔离
+	_, token := newStressKey(t)
+	_, external := newStressKey(t)
+	now := uint64(time.Now().Unix())
+	var addressList []database.Addresses
+	users := make([]common.Address, 5)
+	for i := range users {
+		key, address := newStressKey(t)
+		users[i] = address
+		addressList = append(addressList, database.Addresses{GUID: uuid.New(), Address: address, AddressType: 0, PrivateKey: hex.EncodeToString(crypto.FromECDSA(key)), Timestamp: now})
+	}
+	hotKey, hot := newStressKey(t)
+	addressList = append(addressList, database.Addresses{GUID: uuid.New(), Address: hot, AddressType: 1, PrivateKey: hex.EncodeToString(crypto.FromECDSA(hotKey)), Timestamp: now})
+	require.NoError(t, db.Addresses.StoreAddressess(addressList, uint64(len(addressList))))
+	require.NoError(t, db.Tokens.StoreTokens([]database.Tokens{{GUID: uuid.New(), TokenAddress: token, Uint: 18, TokenName: "STRESS", CollectAmount: big.NewInt(1), Timestamp: now}}, 1))
+
+	// 从库中最新区块之后出块
+	head := &types.Header{Number: big.NewInt(100), Extra: uuid.New().NodeID()}
+	latest, err := db.Blocks.LatestBlocks()
+	require.NoError(t, err)
+	if latest != nil {
+		head.Number = new(big.Int).Set(latest.Number)
+	}
+	chain := newStressChain(t, chainId, token, head)
+	cfg := &config.Config{
+		Chain:     config.ChainConfig{ChainID: uint(chainId.Uint64())},
+		HotWallet: config.HotWalletConfig{Strategy: HotWalletStrategyTokenAssign, TokenAssignments: map[common.Address]common.Address{token: hot}},
+	}
+	shutdown := func(cause error) { t.Error(cause) }
+	txSigner := signer.NewLocalSigner(db, nil, nil)
+	deposit, err := NewDeposit(cfg, db, chain, shutdown)
+	require.NoError(t, err)
+	withdraw, err := NewWithdraw(cfg, db, chain, txSigner, shutdown)
+	require.NoError(t, err)
+	collection, err := NewCollectionCold(cfg, db, chain, txSigner, shutdown)
+	require.NoError(t, err)
+
+	scan := func(to common.Address) error {
+		header, err := chain.mine(to, big.NewInt(10))
+		if err != nil {
+			return err
+		}
+		deposit.headers = []types.Header{*header}
+		return deposit.processBatch(deposit.headers)
+	}
+
+	const rounds = 100
+	var wg sync.WaitGroup
+	wg.Add(3)
+	go func() { // 扫块：每个块一笔充值，同时确认已广播的提现和归集
+		defer wg.Done()
+		for i := 0; i < rounds; i++ {
+			if err := scan(users[i%len(users)]); err != nil {
+				t.Error(err)
+				return
+			}
+		}
+	}()
+	go func() { // 提现：从热钱包转出
+		defer wg.Done()
+		for i := 0; i < rounds; i++ {
+			if err := db.Withdraws.StoreWithdraws([]database.Withdraws{{
+				GUID:             uuid.New(),
+				BlockNumber:      big.NewInt(0),
+				ToAddress:        external,
+				TokenAddress:     token,
+				Fee:              big.NewInt(0),
+				Amount:           big.NewInt(3),
+				TransactionIndex: big.NewInt(0),
+				Timestamp:        uint64(time.Now().Unix()),
+			}}, 1); err != nil {
+				t.Error(err)
+				return
+			}
+			if err := withdraw.processWithdraws(); err != nil {
+				t.Error(err)
+				return
+			}
+		}
+	}()
+	go func() { // 归集：用户地址转入热钱包
+		defer wg.Done()
+		for i := 0; i < rounds; i++ {
+			if err := collection.Collection(); err != nil {
+				t.Error(err)
+				return
+			}
+		}
+	}()
+	wg.Wait()
+	require.False(t, t.Failed())
+
+	// 打包并确认剩余的已广播交易，归集到账后再出款一轮，确保提现确实发生
+	for chain.pendingCount() > 0 {
+		require.NoError(t, scan(users[0]))
+	}
+	require.NoError(t, withdraw.processWithdraws())
+	for chain.pendingCount() > 0 {
+		require.NoError(t, scan(users[0]))
+	}
+
+	for _, address := range append(users, hot) {
+		balance, err := db.Balances.QueryWalletBalanceByTokenAndAddress(address, token)
+		require.NoError(t, err)
+		require.NotNil(t, balance)
+		require.True(t, balance.Balance.Sign() >= 0)
+		require.Equal(t, 0, balance.LockBalance.Sign(), address.String())
+		ledgerBalance, err := db.LedgerEntries.QueryLedgerBalance(address, token, database.LedgerBucketAvailable)
+		require.NoError(t, err)
+		require.Equal(t, ledgerBalance.String(), balance.Balance.String())
+		require.Equal(t, chain.balanceOf(address).String(), balance.Balance.String(), address.String())
+	}
+	require.True(t, chain.balanceOf(external).Sign() > 0)
+}
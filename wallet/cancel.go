@@ -0,0 +1,97 @@
+package wallet
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/the-web3/eth-wallet/database"
+)
+
+// cancelWithdraws 处理请求链上取消的提现：发送同 nonce 的零金额自转交易，原交易的 nonce 被占用后提现取消完成
+func (w *Withdraw) cancelWithdraws() error {
+	withdrawList, err := w.db.Withdraws.QueryCancellingWithdraws()
+	if err != nil {
+		log.Error("query cancelling withdraws fail", "err", err)
+		return err
+	}
+	for _, withdraw := range withdrawList {
+		originTx, from, err := decodeSignedTx(withdraw.TxSignHex)
+		if err != nil {
+			log.Error("decode withdraw tx fail", "guid", withdraw.GUID, "err", err)
+			continue
+		}
+		// 原交易已上链时由区块同步更新提现状态，取消失败
+		mined, err := w.txMined(withdraw.Hash)
+		if err != nil || mined {
+			continue
+		}
+		latestNonce, err := w.client.TxCountByAddress(from)
+		if err != nil {
+			log.Warn("query nonce by address fail", "address", from, "err", err)
+			continue
+		}
+		if uint64(latestNonce) > originTx.Nonce() {
+			mined, err := w.txMined(withdraw.Hash)
+			if err != nil || mined {
+				continue
+			}
+			log.Info("withdraw cancelled on chain", "guid", withdraw.GUID, "hash", withdraw.Hash)
+			if err := w.persistReplaced(func(tx *database.DB) error {
+				if err := tx.Withdraws.MarkWithdrawCancelled(withdraw.GUID); err != nil {
+					return err
+				}
+				return tx.LedgerEntries.SettleLocks(withdraw.Hash, database.LedgerReasonWithdrawLock, database.LedgerReasonWithdrawRelease, nil, nil)
+			}); err != nil && !errors.Is(err, database.ErrWithdrawStatusChanged) {
+				return err
+			}
+			continue
+		}
+
+		cancelTxHex := withdraw.CancelTxHex
+		if cancelTxHex == "" {
+			rawTx, txHash, err := w.signer.SignTx(from, buildCancelTx(originTx, from), originTx.ChainId())
+			if err != nil {
+				log.Error("sign cancel tx fail", "guid", withdraw.GUID, "err", err)
+				continue
+			}
+			if err := w.persistReplaced(func(tx *database.DB) error {
+				if err := tx.Withdraws.MarkWithdrawCancelSigned(withdraw.GUID, rawTx); err != nil {
+					return err
+				}
+				return tx.WithdrawEvents.StoreWithdrawEvents([]database.WithdrawEvents{
+					database.NewWithdrawEvent(withdraw.GUID, database.WithdrawEventCancelSigned, 10, 10, txHash),
+				})
+			}); err != nil {
+				return err
+			}
+			cancelTxHex = rawTx
+		}
+		if err := w.client.SendRawTransaction(cancelTxHex); err != nil {
+			log.Warn("send cancel tx fail", "guid", withdraw.GUID, "err", err)
+		}
+	}
+	return nil
+}
+
+// buildCancelTx 构造同 nonce 的零金额自转交易，手续费比原交易提高 10% 以上才能替换内存池中的原交易
+func buildCancelTx(originTx *types.Transaction, from common.Address) *types.DynamicFeeTx {
+	return &types.DynamicFeeTx{
+		ChainID:   originTx.ChainId(),
+		Nonce:     originTx.Nonce(),
+		GasTipCap: bumpFee(originTx.GasTipCap()),
+		GasFeeCap: bumpFee(originTx.GasFeeCap()),
+		Gas:       EthGasLimit,
+		To:        &from,
+		Value:     big.NewInt(0),
+	}
+}
+
+func bumpFee(fee *big.Int) *big.Int {
+	bumped := new(big.Int).Mul(fee, big.NewInt(11))
+	bumped.Div(bumped, big.NewInt(10))
+	return bumped.Add(bumped, big.NewInt(1))
+}
@@ -0,0 +1,153 @@
+package wallet
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"github.com/google/uuid"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/the-web3/eth-wallet/database"
+	"github.com/the-web3/eth-wallet/wallet/coldsign"
+	"github.com/the-web3/eth-wallet/wallet/node"
+)
+
+var ErrColdTxNotExported = errors.New("rebalance is not waiting for offline signature")
+
+// ColdImportResult 导入一笔离线签名交易的结果，Error 不为空时该笔交易未广播
+type ColdImportResult struct {
+	Id    string `json:"id"`
+	Hash  string `json:"hash"`
+	Error string `json:"error,omitempty"`
+}
+
+// ColdOffline 冷钱包离线签名：导出待签名的冷转热交易，导入离线签名后的交易并广播
+type ColdOffline struct {
+	db      *database.DB
+	client  node.EthClient
+	chainId uint
+}
+
+func NewColdOffline(db *database.DB, client node.EthClient, chainId uint) *ColdOffline {
+	return &ColdOffline{
+		db:      db,
+		client:  client,
+		chainId: chainId,
+	}
+}
+
+// Export 导出待冷钱包签名的交易：已导出未导入的交易原样重新导出，审批通过的冷转热申请按冷钱包 nonce 依次生成新交易
+func (c *ColdOffline) Export() (*coldsign.TxFile, error) {
+	file := &coldsign.TxFile{Version: coldsign.FileVersion}
+	nextNonce := make(map[common.Address]uint64)
+
+	exportedList, err := c.db.Rebalances.QueryRebalancesByStatus(6)
+	if err != nil {
+		log.Error("query exported rebalances fail", "err", err)
+		return nil, err
+	}
+	for _, rebalance := range exportedList {
+		var unsignedTx coldsign.UnsignedTx
+		if err := json.Unmarshal([]byte(rebalance.UnsignedTx), &unsignedTx); err != nil {
+			log.Error("decode exported unsigned tx fail", "guid", rebalance.GUID, "err", err)
+			return nil, err
+		}
+		if nonce := uint64(unsignedTx.Nonce) + 1; nonce > nextNonce[unsignedTx.From] {
+			nextNonce[unsignedTx.From] = nonce
+		}
+		file.Transactions = append(file.Transactions, unsignedTx)
+	}
+
+	approvedList, err := c.db.Rebalances.QueryRebalancesByStatus(1)
+	if err != nil {
+		log.Error("query approved rebalances fail", "err", err)
+		return nil, err
+	}
+	for _, rebalance := range approvedList {
+		if rebalance.Direction != database.RebalanceColdToHot {
+			continue
+		}
+		pendingNonce, err := c.client.PendingTxCountByAddress(rebalance.FromAddress)
+		if err != nil {
+			log.Error("query nonce by address fail", "address", rebalance.FromAddress, "err", err)
+			return nil, err
+		}
+		// 已导出未广播的交易占用的 nonce 不会体现在链上，取两者较大值避免冲突
+		nonce := uint64(pendingNonce)
+		if nonce < nextNonce[rebalance.FromAddress] {
+			nonce = nextNonce[rebalance.FromAddress]
+		}
+		dFeeTx := buildRebalanceTx(&rebalance, c.chainId, nonce)
+		unsignedTx := coldsign.NewUnsignedTx(rebalance.GUID.String(), rebalance.FromAddress, dFeeTx)
+		content, err := json.Marshal(unsignedTx)
+		if err != nil {
+			return nil, err
+		}
+		if err := c.db.Rebalances.MarkRebalanceExported(rebalance.GUID, string(content)); err != nil {
+			log.Error("mark rebalance exported fail", "guid", rebalance.GUID, "err", err)
+			return nil, err
+		}
+		nextNonce[rebalance.FromAddress] = nonce + 1
+		file.Transactions = append(file.Transactions, unsignedTx)
+		log.Info("export cold wallet tx", "guid", rebalance.GUID, "from", rebalance.FromAddress, "nonce", nonce, "amount", rebalance.Amount)
+	}
+	return file, nil
+}
+
+// Import 校验离线签名交易与导出时的交易完全一致后持久化并广播，单笔校验失败不影响其他交易
+func (c *ColdOffline) Import(ctx context.Context, file *coldsign.TxFile) ([]ColdImportResult, error) {
+	var resultList []ColdImportResult
+	for _, signedTx := range file.Transactions {
+		result := ColdImportResult{Id: signedTx.Id}
+		rebalance, tx, err := c.verifyImport(signedTx)
+		if err != nil {
+			log.Warn("reject offline signed tx", "id", signedTx.Id, "err", err)
+			result.Error = err.Error()
+			resultList = append(resultList, result)
+			continue
+		}
+		if err := storeRebalanceSent(ctx, c.db, *rebalance, false, tx.Hash(), signedTx.SignedTx); err != nil {
+			return resultList, err
+		}
+		result.Hash = tx.Hash().String()
+		// 已持久化，广播失败由重新广播任务继续发送，nonce 被占用时调拨回到已审批状态重新导出签名
+		if err := c.client.SendRawTransaction(signedTx.SignedTx); err != nil {
+			log.Error("send offline signed tx fail", "id", signedTx.Id, "err", err)
+			result.Error = err.Error()
+		}
+		log.Info("import offline signed tx", "id", signedTx.Id, "hash", result.Hash)
+		resultList = append(resultList, result)
+	}
+	return resultList, nil
+}
+
+// verifyImport 校验导入的交易对应一条已导出的调拨记录，并按导出时保存的交易校验签名交易，文件中的交易字段不被信任
+func (c *ColdOffline) verifyImport(signedTx coldsign.UnsignedTx) (*database.Rebalances, *types.Transaction, error) {
+	if signedTx.SignedTx == "" {
+		return nil, nil, errors.New("tx is not signed")
+	}
+	guid, err := uuid.Parse(signedTx.Id)
+	if err != nil {
+		return nil, nil, err
+	}
+	rebalance, err := c.db.Rebalances.QueryRebalanceByGuid(guid)
+	if err != nil {
+		return nil, nil, err
+	}
+	if rebalance == nil || rebalance.Status != 6 {
+		return nil, nil, ErrColdTxNotExported
+	}
+	var expected coldsign.UnsignedTx
+	if err := json.Unmarshal([]byte(rebalance.UnsignedTx), &expected); err != nil {
+		return nil, nil, err
+	}
+	tx, err := coldsign.VerifySignedTx(expected, signedTx.SignedTx)
+	if err != nil {
+		return nil, nil, err
+	}
+	return rebalance, tx, nil
+}
@@ -0,0 +1,163 @@
+package coldsign
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/the-web3/eth-wallet/wallet/ethereum"
+)
+
+const FileVersion = 1
+
+var ErrSignedTxMismatch = errors.New("signed tx does not match unsigned request")
+
+// UnsignedTx 一笔待冷钱包离线签名的交易，Id 为对应调拨记录的 guid，签名后 SignedTx 为 eth_sendRawTransaction 格式
+type UnsignedTx struct {
+	Id        string          `json:"id"`
+	ChainId   *hexutil.Big    `json:"chainId"`
+	From      common.Address  `json:"from"`
+	Nonce     hexutil.Uint64  `json:"nonce"`
+	GasTipCap *hexutil.Big    `json:"maxPriorityFeePerGas"`
+	GasFeeCap *hexutil.Big    `json:"maxFeePerGas"`
+	Gas       hexutil.Uint64  `json:"gas"`
+	To        *common.Address `json:"to"`
+	Value     *hexutil.Big    `json:"value"`
+	Data      hexutil.Bytes   `json:"data"`
+	SignedTx  string          `json:"signedTx,omitempty"`
+}
+
+// TxFile 在联网机器和离线签名机器之间传递的交易文件
+type TxFile struct {
+	Version      int          `json:"version"`
+	Transactions []UnsignedTx `json:"transactions"`
+}
+
+func NewUnsignedTx(id string, from common.Address, txData *types.DynamicFeeTx) UnsignedTx {
+	return UnsignedTx{
+		Id:        id,
+		ChainId:   (*hexutil.Big)(txData.ChainID),
+		From:      from,
+		Nonce:     hexutil.Uint64(txData.Nonce),
+		GasTipCap: (*hexutil.Big)(txData.GasTipCap),
+		GasFeeCap: (*hexutil.Big)(txData.GasFeeCap),
+		Gas:       hexutil.Uint64(txData.Gas),
+		To:        txData.To,
+		Value:     (*hexutil.Big)(txData.Value),
+		Data:      txData.Data,
+	}
+}
+
+func (u *UnsignedTx) DynamicFeeTx() *types.DynamicFeeTx {
+	return &types.DynamicFeeTx{
+		ChainID:   u.ChainId.ToInt(),
+		Nonce:     uint64(u.Nonce),
+		GasTipCap: u.GasTipCap.ToInt(),
+		GasFeeCap: u.GasFeeCap.ToInt(),
+		Gas:       uint64(u.Gas),
+		To:        u.To,
+		Value:     u.Value.ToInt(),
+		Data:      u.Data,
+	}
+}
+
+func ReadFile(path string) (*TxFile, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return DecodeFile(content)
+}
+
+func DecodeFile(content []byte) (*TxFile, error) {
+	var file TxFile
+	if err := json.Unmarshal(content, &file); err != nil {
+		return nil, err
+	}
+	if file.Version != FileVersion {
+		return nil, fmt.Errorf("unsupported tx file version %d", file.Version)
+	}
+	for i, tx := range file.Transactions {
+		if tx.ChainId == nil || tx.GasTipCap == nil || tx.GasFeeCap == nil || tx.Value == nil || tx.To == nil {
+			return nil, fmt.Errorf("tx %d (%s) misses required fields", i, tx.Id)
+		}
+	}
+	return &file, nil
+}
+
+func WriteFile(path string, file *TxFile) error {
+	content, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, content, 0600)
+}
+
+// LoadKeyFile 读取十六进制私钥文件
+func LoadKeyFile(path string) (string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimPrefix(strings.TrimSpace(string(content)), "0x"), nil
+}
+
+// SignFile 用私钥离线签名文件中的全部交易，私钥地址必须与交易的 from 一致
+func SignFile(file *TxFile, privateKey string) error {
+	key, err := crypto.HexToECDSA(privateKey)
+	if err != nil {
+		return err
+	}
+	address := crypto.PubkeyToAddress(key.PublicKey)
+	for i := range file.Transactions {
+		tx := &file.Transactions[i]
+		if tx.From != address {
+			return fmt.Errorf("tx %s is from %s but the key is for %s", tx.Id, tx.From, address)
+		}
+		rawTx, _, err := ethereum.OfflineSignTx(tx.DynamicFeeTx(), privateKey, tx.ChainId.ToInt())
+		if err != nil {
+			return err
+		}
+		tx.SignedTx = rawTx
+	}
+	return nil
+}
+
+// VerifySignedTx 校验签名交易的发送方和全部交易字段都与原始请求一致，返回解码后的交易
+func VerifySignedTx(expected UnsignedTx, signedTx string) (*types.Transaction, error) {
+	rawTx, err := hexutil.Decode(signedTx)
+	if err != nil {
+		return nil, err
+	}
+	tx := new(types.Transaction)
+	if err := tx.UnmarshalBinary(rawTx); err != nil {
+		return nil, err
+	}
+	sender, err := types.Sender(types.LatestSignerForChainID(expected.ChainId.ToInt()), tx)
+	if err != nil {
+		return nil, err
+	}
+	if sender != expected.From {
+		return nil, fmt.Errorf("%w: signed by %s, expect %s", ErrSignedTxMismatch, sender, expected.From)
+	}
+	if tx.Type() != types.DynamicFeeTxType ||
+		tx.ChainId().Cmp(expected.ChainId.ToInt()) != 0 ||
+		tx.Nonce() != uint64(expected.Nonce) ||
+		tx.GasTipCap().Cmp(expected.GasTipCap.ToInt()) != 0 ||
+		tx.GasFeeCap().Cmp(expected.GasFeeCap.ToInt()) != 0 ||
+		tx.Gas() != uint64(expected.Gas) ||
+		tx.To() == nil || *tx.To() != *expected.To ||
+		tx.Value().Cmp(expected.Value.ToInt()) != 0 ||
+		!bytes.Equal(tx.Data(), expected.Data) {
+		return nil, fmt.Errorf("%w: tx %s", ErrSignedTxMismatch, expected.Id)
+	}
+	return tx, nil
+}
@@ -0,0 +1,49 @@
+package coldsign
+
+import (
+	"encoding/hex"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestSignAndVerifyFile(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	privateKey := hex.EncodeToString(crypto.FromECDSA(key))
+	from := crypto.PubkeyToAddress(key.PublicKey)
+	to := common.HexToAddress("0x1111111111111111111111111111111111111111")
+
+	unsignedTx := NewUnsignedTx("guid-1", from, &types.DynamicFeeTx{
+		ChainID:   big.NewInt(17000),
+		Nonce:     7,
+		GasTipCap: big.NewInt(2_600_000_000),
+		GasFeeCap: big.NewInt(2_900_000_000),
+		Gas:       21000,
+		To:        &to,
+		Value:     big.NewInt(1e18),
+	})
+	file := &TxFile{Version: FileVersion, Transactions: []UnsignedTx{unsignedTx}}
+	require.NoError(t, SignFile(file, privateKey))
+
+	tx, err := VerifySignedTx(unsignedTx, file.Transactions[0].SignedTx)
+	require.NoError(t, err)
+	require.Equal(t, uint64(7), tx.Nonce())
+
+	// 导出记录与签名交易的金额不一致
+	tampered := unsignedTx
+	tampered.Value = (*hexutil.Big)(big.NewInt(2e18))
+	_, err = VerifySignedTx(tampered, file.Transactions[0].SignedTx)
+	require.ErrorIs(t, err, ErrSignedTxMismatch)
+
+	// 其他私钥无法签名该冷钱包的交易
+	otherKey, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	require.Error(t, SignFile(file, hex.EncodeToString(crypto.FromECDSA(otherKey))))
+}
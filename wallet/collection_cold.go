@@ -19,30 +19,36 @@ import (
 	"github.com/the-web3/eth-wallet/wallet/ethereum"
 	"github.com/the-web3/eth-wallet/wallet/node"
 	"github.com/the-web3/eth-wallet/wallet/retry"
+	"github.com/the-web3/eth-wallet/wallet/signer"
 )
 
 var (
 	CollectionFunding = big.NewInt(10000000000000000)
-	ColdFunding       = big.NewInt(2000000000000000000)
 )
 
 type CollectionCold struct {
-	db             *database.DB
-	chainConf      *config.ChainConfig
-	client         node.EthClient
-	resourceCtx    context.Context
-	resourceCancel context.CancelFunc
-	tasks          tasks.Group
+	db              *database.DB
+	chainConf       *config.ChainConfig
+	client          node.EthClient
+	signer          signer.Signer
+	hotWallets      *HotWalletSelector
+	coldSignOffline bool
+	resourceCtx     context.Context
+	resourceCancel  context.CancelFunc
+	tasks           tasks.Group
 }
 
-func NewCollectionCold(cfg *config.Config, db *database.DB, client node.EthClient, shutdown context.CancelCauseFunc) (*CollectionCold, error) {
+func NewCollectionCold(cfg *config.Config, db *database.DB, client node.EthClient, txSigner signer.Signer, shutdown context.CancelCauseFunc) (*CollectionCold, error) {
 	resCtx, resCancel := context.WithCancel(context.Background())
 	return &CollectionCold{
-		db:             db,
-		chainConf:      &cfg.Chain,
-		client:         client,
-		resourceCtx:    resCtx,
-		resourceCancel: resCancel,
+		db:              db,
+		chainConf:       &cfg.Chain,
+		client:          client,
+		signer:          txSigner,
+		hotWallets:      NewHotWalletSelector(cfg, db, client),
+		coldSignOffline: cfg.ColdSignOffline,
+		resourceCtx:     resCtx,
+		resourceCancel:  resCancel,
 		tasks: tasks.Group{HandleCrit: func(err error) {
 			shutdown(fmt.Errorf("critical error in deposit: %w", err))
 		}},
@@ -53,7 +59,7 @@ func (cc *CollectionCold) Close() error {
 	var result error
 	cc.resourceCancel()
 	if err := cc.tasks.Wait(); err != nil {
-		result = errors.Join(result, fmt.Errorf("failed to await deposit %w"), err)
+		result = errors.Join(result, fmt.Errorf("failed to await deposit: %w", err))
 	}
 	return nil
 }
@@ -72,13 +78,14 @@ func (cc *CollectionCold) Start() error {
 		return nil
 	})
 
+	tickerRebalanceWorker := time.NewTicker(time.Second * 5)
 	cc.tasks.Go(func() error {
-		for range tickerCollectionColdWorker.C {
-			//err := cc.ToCold()
-			//if err != nil {
-			//	log.Error("to cold fail", "err", err)
-			//	return err
-			//}
+		for range tickerRebalanceWorker.C {
+			err := cc.Rebalance()
+			if err != nil {
+				log.Error("rebalance fail", "err", err)
+				return err
+			}
 		}
 		return nil
 	})
@@ -86,140 +93,103 @@ func (cc *CollectionCold) Start() error {
 	return nil
 }
 
-func (cc *CollectionCold) ToCold() error {
-	hotWalletBalancesList, err := cc.db.Balances.QueryHotWalletBalances(ColdFunding)
+// Collection 归集
+func (cc *CollectionCold) Collection() error {
+	policies, defaultPolicy, err := cc.db.CollectionPolicies.QueryEnableCollectionPolicies()
 	if err != nil {
-		log.Error("to cold query hot wallet info fail", "err", err)
+		log.Error("query collection policies fail", "err", err)
+		return err
+	}
+	gasPrice, err := cc.client.SuggestGasPrice()
+	if err != nil {
+		log.Error("query gas price fail", "err", err)
+		return nil
+	}
+	// 各币种阈值不同，先取出所有有余额的用户地址再按策略过滤
+	unCollectionList, err := cc.db.Balances.UnCollectionList(big.NewInt(1))
+	if err != nil {
+		log.Error("query uncollection fail", "err", err)
 		return err
 	}
-	var txList []database.Transactions
-	balanceForStore := make([]database.Balances, len(hotWalletBalancesList))
-	for _, value := range hotWalletBalancesList {
-		index := 0
-		coldWalletInfo, err := cc.db.Addresses.QueryColdWalletInfo()
-		if err != nil {
-			log.Error("query cold wallet info err", "err", err)
-			return err
-		}
 
-		// nonce
-		nonce, err := cc.client.TxCountByAddress(value.Address)
-		if err != nil {
-			log.Error("query nonce by address fail", "err", err)
-			return err
+	stationNonce := make(map[common.Address]uint64)
+	rules := make(map[common.Address]*collectionRule)
+	forwarders := make(map[common.Address][]forwarderBalance)
+	var forwarderTokens []common.Address
+	now := time.Now()
+	for _, uncollect := range unCollectionList {
+		rule, ok := rules[uncollect.TokenAddress]
+		if !ok {
+			rule, err = cc.collectionRuleFor(uncollect.TokenAddress, policies, defaultPolicy)
+			if err != nil {
+				log.Error("query collection rule fail", "tokenAddress", uncollect.TokenAddress, "err", err)
+				return err
+			}
+			rules[uncollect.TokenAddress] = rule
+		}
+		if rule.batchFull() || uncollect.Balance.Cmp(rule.threshold) < 0 {
+			continue
 		}
 
-		hotAccount, err := cc.db.Addresses.QueryAddressesByToAddress(&value.Address)
+		isToken := uncollect.TokenAddress.Hex() != "0x0000000000000000000000000000000000000000"
+		// CREATE2 转发地址没有私钥也不需要 gas，由热钱包通过工厂合约批量归集
+		salt, isForwarder, err := cc.forwarderSalt(uncollect.Address)
 		if err != nil {
-			log.Error("query account info by address fail", "err", err)
+			log.Error("query forwarder salt fail", "address", uncollect.Address, "err", err)
 			return err
 		}
-
-		var buildData []byte
-		var gasLimit uint64
-		var toAddress *common.Address
-		var amount *big.Int
-		if value.TokenAddress.Hex() != "0x00" {
-			buildData = ethereum.BuildErc20Data(coldWalletInfo.Address, value.Balance)
-			toAddress = &value.TokenAddress
-			gasLimit = TokenGasLimit
-			amount = big.NewInt(0)
-		} else {
-			toAddress = &coldWalletInfo.Address
-			gasLimit = EthGasLimit
-			amount = value.Balance
-		}
-		dFeeTx := &types.DynamicFeeTx{
-			ChainID:   big.NewInt(int64(cc.chainConf.ChainID)),
-			Nonce:     uint64(nonce),
-			GasTipCap: maxPriorityFeePerGas,
-			GasFeeCap: maxFeePerGas,
-			Gas:       gasLimit,
-			To:        toAddress,
-			Value:     amount,
-			Data:      buildData,
+		if isForwarder {
+			fee := new(big.Int).Mul(new(big.Int).SetUint64(ForwarderSweepGasPerForwarder), gasPrice)
+			if reason := checkCollectionPolicy(rule.policy, isToken, uncollect.Balance, fee, gasPrice, now); reason != "" {
+				log.Debug("skip forwarder sweep by policy", "address", uncollect.Address, "tokenAddress", uncollect.TokenAddress, "reason", reason)
+				continue
+			}
+			if _, ok := forwarders[uncollect.TokenAddress]; !ok {
+				forwarderTokens = append(forwarderTokens, uncollect.TokenAddress)
+			}
+			forwarders[uncollect.TokenAddress] = append(forwarders[uncollect.TokenAddress], forwarderBalance{balance: uncollect, salt: salt})
+			rule.collected++
+			continue
 		}
-		rawTx, txHash, err := ethereum.OfflineSignTx(dFeeTx, hotAccount.PrivateKey, big.NewInt(int64(cc.chainConf.ChainID)))
-		if err != nil {
-			log.Error("offline transaction fail", "err", err)
-			return err
+		// ETH 归集按链上余额扣除精确手续费转出，不留零头
+		collectAmount := uncollect.Balance
+		fee := new(big.Int).Mul(new(big.Int).SetUint64(TokenGasLimit), gasPrice)
+		if !isToken {
+			balance, err := cc.client.BalanceAt(uncollect.Address)
+			if err != nil {
+				log.Error("query native balance fail", "address", uncollect.Address, "err", err)
+				continue
+			}
+			fee = new(big.Int).Mul(new(big.Int).SetUint64(EthGasLimit), gasPrice)
+			collectAmount = new(big.Int).Sub(balance, fee)
 		}
-
-		//  sendRawTx
-		log.Info("Offline sign tx success", "rawTx", rawTx)
-		err = cc.client.SendRawTransaction(rawTx)
-		if err != nil {
-			log.Error("send raw transaction fail", "err", err)
-			return err
+		if reason := checkCollectionPolicy(rule.policy, isToken, collectAmount, fee, gasPrice, now); reason != "" {
+			log.Debug("skip collection by policy", "address", uncollect.Address, "tokenAddress", uncollect.TokenAddress, "reason", reason)
+			continue
 		}
 
-		guid, _ := uuid.NewUUID()
-		coldTx := database.Transactions{
-			GUID:             guid,
-			BlockHash:        common.Hash{},
-			BlockNumber:      nil,
-			Hash:             common.HexToHash(txHash),
-			FromAddress:      value.Address,
-			ToAddress:        coldWalletInfo.Address,
-			TokenAddress:     value.TokenAddress,
-			Fee:              big.NewInt(0),
-			Amount:           value.Balance,
-			Status:           0,
-			TxType:           2,
-			TransactionIndex: nil,
-			Timestamp:        uint64(time.Time{}.Unix()),
-		}
-		txList = append(txList, coldTx)
-		balanceForStore[index].LockBalance = new(big.Int).Sub(balanceForStore[index].Balance, ColdFunding)
-		balanceForStore[index].Address = value.Address
-		balanceForStore[index].TokenAddress = value.TokenAddress
-	}
-	retryStrategy := &retry.ExponentialStrategy{Min: 1000, Max: 20_000, MaxJitter: 250}
-	if _, err := retry.Do[interface{}](cc.resourceCtx, 10, retryStrategy, func() (interface{}, error) {
-		if err := cc.db.Transaction(func(tx *database.DB) error {
-			if len(hotWalletBalancesList) > 0 {
-				if err := tx.Balances.UpdateBalances(balanceForStore, false); err != nil {
-					return err
-				}
+		// 用户地址 ETH 不足以支付 token 归集手续费时先由 gas 站补充，补充交易上链后再归集
+		var funding *database.GasFundings
+		if isToken && cc.gasStationEnable() {
+			pending, ready, err := cc.prepareCollectionGas(uncollect, gasPrice, stationNonce)
+			if err != nil {
+				return err
 			}
-			if len(txList) > 0 {
-				if err := tx.Transactions.StoreTransactions(txList, uint64(len(txList))); err != nil {
-					return err
-				}
+			if !ready {
+				continue
 			}
-			return nil
-		}); err != nil {
-			log.Error("unable to persist batch", "err", err)
-			return nil, err
+			funding = pending
 		}
-		return nil, nil
-	}); err != nil {
-		return err
-	}
-	return nil
-}
-
-// Collection 归集
-func (cc *CollectionCold) Collection() error {
-	unCollectionList, err := cc.db.Balances.UnCollectionList(CollectionFunding)
-	if err != nil {
-		log.Error("query uncollection fail", "err", err)
-		return err
-	}
-
-	hotWalletInfo, err := cc.db.Addresses.QueryHotWalletInfo()
-	if err != nil {
-		log.Error("query hot wallet info fail", "err", err)
-		return err
-	}
-
-	var txList []database.Transactions
-	for _, uncollect := range unCollectionList {
-		accountInfo, err := cc.db.Addresses.QueryAddressesByToAddress(&uncollect.Address)
+		// 每笔归集按策略选择目标热钱包
+		hotWalletInfo, err := cc.hotWallets.SelectForCollection(uncollect.TokenAddress)
 		if err != nil {
-			log.Error("query account info fail", "err", err)
+			log.Error("select hot wallet fail", "err", err)
 			return err
 		}
+		if hotWalletInfo == nil {
+			log.Warn("no hot wallet for collection", "tokenAddress", uncollect.TokenAddress)
+			continue
+		}
 
 		// nonce
 		nonce, err := cc.client.TxCountByAddress(uncollect.Address)
@@ -228,45 +198,35 @@ func (cc *CollectionCold) Collection() error {
 			return err
 		}
 
-		var buildData []byte
-		var gasLimit uint64
-		var toAddress *common.Address
-		var amount *big.Int
-		if uncollect.TokenAddress.Hex() != "0x0000000000000000000000000000000000000000" {
-			buildData = ethereum.BuildErc20Data(hotWalletInfo.Address, uncollect.Balance)
-			toAddress = &uncollect.TokenAddress
-			gasLimit = TokenGasLimit
-			amount = big.NewInt(0)
-		} else {
-			fee := big.NewInt(1000000000000000)
-			colllectAmount := new(big.Int).Sub(uncollect.Balance, fee)
-			toAddress = &hotWalletInfo.Address
-			gasLimit = EthGasLimit
-			amount = colllectAmount
-		}
+		// 小费等于手续费上限时实际 gas 价格就是 gasPrice，手续费与预扣金额或 gas 站补充金额完全一致
 		dFeeTx := &types.DynamicFeeTx{
 			ChainID:   big.NewInt(int64(cc.chainConf.ChainID)),
 			Nonce:     uint64(nonce),
-			GasTipCap: maxPriorityFeePerGas,
-			GasFeeCap: maxFeePerGas,
-			Gas:       gasLimit,
-			To:        toAddress,
-			Value:     amount,
-			Data:      buildData,
+			GasTipCap: gasPrice,
+			GasFeeCap: gasPrice,
+			Gas:       TokenGasLimit,
+			To:        &uncollect.TokenAddress,
+			Value:     big.NewInt(0),
+			Data:      ethereum.BuildErc20Data(hotWalletInfo.Address, uncollect.Balance),
+		}
+		if !isToken {
+			dFeeTx.Gas = EthGasLimit
+			dFeeTx.To = &hotWalletInfo.Address
+			dFeeTx.Value = collectAmount
+			dFeeTx.Data = nil
 		}
-		rawTx, txHash, err := ethereum.OfflineSignTx(dFeeTx, accountInfo.PrivateKey, big.NewInt(int64(cc.chainConf.ChainID)))
+		// 模拟回滚的交易不广播，避免白白消耗手续费
+		if err := simulateTx(cc.client, uncollect.Address, dFeeTx); err != nil {
+			log.Warn("skip collection tx that fails simulation", "from", uncollect.Address, "tokenAddress", uncollect.TokenAddress, "err", err)
+			continue
+		}
+		rawTx, txHash, err := cc.signer.SignTx(uncollect.Address, dFeeTx, big.NewInt(int64(cc.chainConf.ChainID)))
 		if err != nil {
 			log.Error("offline transaction fail", "err", err)
 			return err
 		}
-		//  sendRawTx
-		log.Info("Offline sign tx success", "rawTx", rawTx, "fromAddress", accountInfo.Address, "balance", uncollect.Balance, "amount", amount)
+		log.Info("Offline sign tx success", "rawTx", rawTx, "fromAddress", uncollect.Address, "balance", uncollect.Balance, "amount", collectAmount)
 
-		err = cc.client.SendRawTransaction(rawTx)
-		if err != nil {
-			log.Error("send raw transaction fail", "err", err)
-			return err
-		}
 		guid, _ := uuid.NewUUID()
 		collection := database.Transactions{
 			GUID:             guid,
@@ -277,35 +237,51 @@ func (cc *CollectionCold) Collection() error {
 			ToAddress:        hotWalletInfo.Address,
 			TokenAddress:     uncollect.TokenAddress,
 			Fee:              big.NewInt(1),
-			Amount:           uncollect.Balance,
+			Amount:           collectAmount,
 			Status:           0,
 			TxType:           2,
 			TransactionIndex: big.NewInt(time.Now().Unix()),
+			TxSignHex:        rawTx,
 			Timestamp:        uint64(time.Now().Unix()),
 		}
-		txList = append(txList, collection)
+		// 先持久化归集交易并锁定用户余额再广播，否则交易可能在落库前被扫块处理，锁定的余额永远不会结算
+		if err := cc.storeCollection(uncollect, collection, funding); err != nil {
+			return err
+		}
+		rule.collected++
+
+		// 已持久化，广播失败由重新广播任务继续发送，nonce 被占用时释放锁定余额
+		err = cc.client.SendRawTransaction(rawTx)
+		if err != nil {
+			log.Error("send raw transaction fail", "address", uncollect.Address, "err", err)
+			continue
+		}
 	}
+	return cc.sweepForwarders(forwarderTokens, forwarders)
+}
+
+// storeCollection 锁定被归集地址的余额并记录归集交易，有 gas 补充时关联补充记录
+func (cc *CollectionCold) storeCollection(uncollect database.Balances, collection database.Transactions, funding *database.GasFundings) error {
+	lockJournal := database.NewJournal(database.LedgerReasonCollectionLock, collection.Hash, uncollect.TokenAddress).
+		Transfer(database.AvailableAccount(uncollect.Address, 0), database.LockedAccount(uncollect.Address, 0), uncollect.Balance)
 	retryStrategy := &retry.ExponentialStrategy{Min: 1000, Max: 20_000, MaxJitter: 250}
-	if _, err := retry.Do[interface{}](cc.resourceCtx, 10, retryStrategy, func() (interface{}, error) {
+	_, err := retry.Do[interface{}](cc.resourceCtx, 10, retryStrategy, func() (interface{}, error) {
 		if err := cc.db.Transaction(func(tx *database.DB) error {
-			if len(unCollectionList) > 0 {
-				if err := tx.Balances.UpdateBalances(unCollectionList, true); err != nil {
-					return err
-				}
+			if err := tx.LedgerEntries.PostJournal(lockJournal); err != nil {
+				return err
 			}
-
-			if err := tx.Transactions.StoreTransactions(txList, uint64(len(txList))); err != nil {
+			if err := tx.Transactions.StoreTransactions([]database.Transactions{collection}, 1); err != nil {
 				return err
 			}
-
+			if funding != nil {
+				return tx.GasFundings.MarkGasFundingCollected(funding.GUID, collection.Hash)
+			}
 			return nil
 		}); err != nil {
-			log.Error("unable to persist batch", "err", err)
+			log.Error("unable to persist collection", "err", err)
 			return nil, err
 		}
 		return nil, nil
-	}); err != nil {
-		return err
-	}
-	return nil
+	})
+	return err
 }
@@ -0,0 +1,94 @@
+package wallet
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/the-web3/eth-wallet/database"
+)
+
+// collectionRule 一轮归集中某个币种生效的归集策略
+type collectionRule struct {
+	threshold *big.Int
+	policy    *database.CollectionPolicies
+	collected uint64
+}
+
+// batchFull 本轮归集地址数是否已达到策略的批次上限
+func (r *collectionRule) batchFull() bool {
+	return r.policy != nil && r.policy.BatchSize > 0 && r.collected >= r.policy.BatchSize
+}
+
+// collectionRuleFor 按 币种策略 > 默认策略 选择归集策略，策略没有配置阈值时依次使用 tokens.collect_amount 和 CollectionFunding
+func (cc *CollectionCold) collectionRuleFor(tokenAddress common.Address, policies map[common.Address]database.CollectionPolicies, defaultPolicy *database.CollectionPolicies) (*collectionRule, error) {
+	rule := &collectionRule{policy: defaultPolicy}
+	if policy, ok := policies[tokenAddress]; ok {
+		if err := validateCollectionPolicy(tokenAddress, &policy); err != nil {
+			return nil, err
+		}
+		rule.policy = &policy
+	}
+	if rule.policy != nil && rule.policy.Threshold != nil && rule.policy.Threshold.Sign() > 0 {
+		rule.threshold = rule.policy.Threshold
+		return rule, nil
+	}
+	token, err := cc.db.Tokens.TokensInfoByAddress(strings.ToLower(tokenAddress.String()))
+	if err != nil {
+		return nil, err
+	}
+	if token != nil && token.CollectAmount != nil && token.CollectAmount.Sign() > 0 {
+		rule.threshold = token.CollectAmount
+	} else {
+		rule.threshold = CollectionFunding
+	}
+	return rule, nil
+}
+
+// validateCollectionPolicy 代币金额与 ETH 手续费无法比较，代币策略设置了价值手续费比时拒绝使用，而不是忽略该限制
+// 代币的最小归集金额由 threshold 配置
+func validateCollectionPolicy(tokenAddress common.Address, policy *database.CollectionPolicies) error {
+	if tokenAddress != (common.Address{}) && policy.MinValueFeeRatio > 0 {
+		return fmt.Errorf("collection policy for token %s sets min_value_fee_ratio, use threshold instead", tokenAddress)
+	}
+	return nil
+}
+
+// inCollectionWindow 判断当前 UTC 小时是否在 [start, end) 内，end 小于 start 时跨越零点，两者相等表示全天
+func inCollectionWindow(start, end uint8, now time.Time) bool {
+	if start == end {
+		return true
+	}
+	hour := uint8(now.UTC().Hour())
+	if start < end {
+		return hour >= start && hour < end
+	}
+	return hour >= start || hour < end
+}
+
+// checkCollectionPolicy 校验归集是否划算，返回不归集的原因，空字符串表示可以归集
+// value 与 fee 只有在 ETH 归集时才同一单位，因此价值手续费比只对 ETH 生效，默认策略的价值手续费比不作用于代币
+func checkCollectionPolicy(policy *database.CollectionPolicies, isToken bool, value, fee, gasPrice *big.Int, now time.Time) string {
+	if value.Sign() <= 0 {
+		return "balance can not cover collection fee"
+	}
+	if policy == nil {
+		return ""
+	}
+	if !inCollectionWindow(policy.WindowStart, policy.WindowEnd, now) {
+		return fmt.Sprintf("out of collection window %d-%d", policy.WindowStart, policy.WindowEnd)
+	}
+	if policy.MaxGasPrice != nil && policy.MaxGasPrice.Sign() > 0 && gasPrice.Cmp(policy.MaxGasPrice) > 0 {
+		return fmt.Sprintf("gas price %s above max %s", gasPrice, policy.MaxGasPrice)
+	}
+	if !isToken && policy.MinValueFeeRatio > 0 {
+		minValue := new(big.Int).Mul(fee, new(big.Int).SetUint64(policy.MinValueFeeRatio))
+		if value.Cmp(minValue) < 0 {
+			return fmt.Sprintf("value %s below %d times fee %s", value, policy.MinValueFeeRatio, fee)
+		}
+	}
+	return ""
+}
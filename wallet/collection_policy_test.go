@@ -0,0 +1,62 @@
+package wallet
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/the-web3/eth-wallet/database"
+)
+
+func TestInCollectionWindow(t *testing.T) {
+	at := func(hour int) time.Time {
+		return time.Date(2024, 7, 20, hour, 30, 0, 0, time.UTC)
+	}
+	require.True(t, inCollectionWindow(0, 0, at(13)))
+	require.True(t, inCollectionWindow(2, 6, at(2)))
+	require.False(t, inCollectionWindow(2, 6, at(6)))
+	// 跨越零点
+	require.True(t, inCollectionWindow(22, 4, at(23)))
+	require.True(t, inCollectionWindow(22, 4, at(3)))
+	require.False(t, inCollectionWindow(22, 4, at(12)))
+}
+
+func TestCheckCollectionPolicy(t *testing.T) {
+	now := time.Date(2024, 7, 20, 3, 0, 0, 0, time.UTC)
+	gasPrice := big.NewInt(10)
+	fee := big.NewInt(210000)
+
+	require.Empty(t, checkCollectionPolicy(nil, false, big.NewInt(1), fee, gasPrice, now))
+	require.NotEmpty(t, checkCollectionPolicy(nil, false, big.NewInt(-1), fee, gasPrice, now))
+
+	policy := &database.CollectionPolicies{WindowStart: 1, WindowEnd: 5, MaxGasPrice: big.NewInt(10), MinValueFeeRatio: 10}
+	require.Empty(t, checkCollectionPolicy(policy, false, big.NewInt(2100000), fee, gasPrice, now))
+	require.NotEmpty(t, checkCollectionPolicy(policy, false, big.NewInt(2099999), fee, gasPrice, now))
+	// 价值手续费比不作用于 token
+	require.Empty(t, checkCollectionPolicy(policy, true, big.NewInt(1), fee, gasPrice, now))
+	require.NotEmpty(t, checkCollectionPolicy(policy, false, big.NewInt(2100000), fee, big.NewInt(11), now))
+	require.NotEmpty(t, checkCollectionPolicy(policy, false, big.NewInt(2100000), fee, gasPrice, now.Add(3*time.Hour)))
+}
+
+func TestCollectionRuleRejectsTokenFeeRatio(t *testing.T) {
+	cc := &CollectionCold{}
+	token := common.HexToAddress("0x0000000000000000000000000000000000000001")
+	policy := database.CollectionPolicies{Threshold: big.NewInt(100), MinValueFeeRatio: 10}
+	policies := map[common.Address]database.CollectionPolicies{token: policy, {}: policy}
+
+	_, err := cc.collectionRuleFor(token, policies, nil)
+	require.Error(t, err)
+
+	rule, err := cc.collectionRuleFor(common.Address{}, policies, nil)
+	require.NoError(t, err)
+	require.Equal(t, uint64(10), rule.policy.MinValueFeeRatio)
+
+	// 默认策略的价值手续费比只作用于 ETH，不影响代币
+	rule, err = cc.collectionRuleFor(token, nil, &policy)
+	require.NoError(t, err)
+	require.Empty(t, checkCollectionPolicy(rule.policy, true, big.NewInt(1), big.NewInt(21000), big.NewInt(1), time.Now()))
+}
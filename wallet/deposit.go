@@ -32,6 +32,9 @@ type Deposit struct {
 
 	headers []types.Header
 
+	snapshotInterval time.Duration
+	snapshotSince    uint64
+
 	resourceCtx    context.Context
 	resourceCancel context.CancelFunc
 	tasks          tasks.Group
@@ -61,12 +64,13 @@ func NewDeposit(cfg *config.Config, db *database.DB, client node.EthClient, shut
 	resCtx, resCancel := context.WithCancel(context.Background())
 
 	return &Deposit{
-		db:              db,
-		chainConf:       &cfg.Chain,
-		client:          client,
-		headerTraversal: headerTraversal,
-		resourceCtx:     resCtx,
-		resourceCancel:  resCancel,
+		db:               db,
+		chainConf:        &cfg.Chain,
+		client:           client,
+		headerTraversal:  headerTraversal,
+		snapshotInterval: cfg.BalanceSnapshotInterval,
+		resourceCtx:      resCtx,
+		resourceCancel:   resCancel,
 		tasks: tasks.Group{HandleCrit: func(err error) {
 			shutdown(fmt.Errorf("critical error in deposit: %w", err))
 		}},
@@ -77,7 +81,7 @@ func (d *Deposit) Close() error {
 	var result error
 	d.resourceCancel()
 	if err := d.tasks.Wait(); err != nil {
-		result = errors.Join(result, fmt.Errorf("failed to await deposit %w"), err)
+		result = errors.Join(result, fmt.Errorf("failed to await deposit: %w", err))
 		return result
 	}
 	return nil
@@ -104,16 +108,38 @@ func (d *Deposit) Start() error {
 		}
 		return nil
 	})
+
+	if d.snapshotInterval > 0 {
+		tickerSnapshotWorker := time.NewTicker(d.snapshotInterval)
+		d.tasks.Go(func() error {
+			for range tickerSnapshotWorker.C {
+				// 定期全量比较，补录批次快照之外变化的余额
+				count, err := d.db.BalanceSnapshots.SnapshotLatestBalances()
+				if err != nil {
+					log.Error("snapshot balances fail", "err", err)
+					continue
+				}
+				log.Info("snapshot balances success", "count", count)
+			}
+			return nil
+		})
+	}
 	return nil
 }
 
 func (d *Deposit) processBatch(headers []types.Header) error {
+	// 没有新区块时没有可记录的批次，快照也没有对应的区块
+	if len(headers) == 0 {
+		return nil
+	}
 	blockListForStore := make([]database.Blocks, len(headers))
 	var depositList []database.Deposits
 	var withdrawList []database.Withdraws
 	var depositTransactionList []database.Transactions
 	var outherTransactionList []database.Transactions
-	var tokenBalanceList []database.TokenBalance
+	var withdrawBatchList []database.WithdrawBatches
+	var rebalanceList []database.Rebalances
+	var forwarderSweepList []database.ForwarderSweeps
 	var batchLastBlockNumber uint64
 	for i := range headers {
 		log.Info("handle block number", "number", headers[i].Number.String(), "blockHash", headers[i].Hash().String())
@@ -125,7 +151,7 @@ func (d *Deposit) processBatch(headers []types.Header) error {
 			log.Error("get block number error", "err", err)
 			return err
 		}
-		deposits, withdraws, depositTransactions, outherTransactions, tokenBalances, err := d.processTransactions(block.Transactions, block.BaseFee)
+		deposits, withdraws, depositTransactions, outherTransactions, withdrawBatches, rebalances, forwarderSweeps, err := d.processTransactions(block.Transactions, block.BaseFee)
 		if err != nil {
 			log.Error("process transaction fail", "err", err)
 			return err
@@ -135,9 +161,13 @@ func (d *Deposit) processBatch(headers []types.Header) error {
 		withdrawList = append(withdrawList, withdraws...)
 		depositTransactionList = append(depositTransactionList, depositTransactions...)
 		outherTransactionList = append(outherTransactionList, outherTransactions...)
-		tokenBalanceList = append(tokenBalanceList, tokenBalances...)
+		withdrawBatchList = append(withdrawBatchList, withdrawBatches...)
+		rebalanceList = append(rebalanceList, rebalances...)
+		forwarderSweepList = append(forwarderSweepList, forwarderSweeps...)
 		batchLastBlockNumber = headers[i].Number.Uint64()
 	}
+	lastHeader := headers[len(headers)-1]
+	snapshotStart := uint64(time.Now().Unix())
 	retryStrategy := &retry.ExponentialStrategy{Min: 1000, Max: 20_000, MaxJitter: 250}
 	if _, err := retry.Do[interface{}](d.resourceCtx, 10, retryStrategy, func() (interface{}, error) {
 		if err := d.db.Transaction(func(tx *database.DB) error {
@@ -164,6 +194,41 @@ func (d *Deposit) processBatch(headers []types.Header) error {
 				}
 			}
 
+			for _, withdrawBatch := range withdrawBatchList { // 批量提现：一笔回执更新批次内所有提现
+				if err := tx.WithdrawBatches.UpdateWithdrawBatchStatus(withdrawBatch); err != nil {
+					return err
+				}
+				if err := tx.Withdraws.UpdateBatchWithdrawsStatus(withdrawBatch); err != nil {
+					return err
+				}
+				if err := settleWithdrawLocks(tx, withdrawBatch.Hash, withdrawBatch.Status == 1); err != nil {
+					return err
+				}
+			}
+
+			for _, rebalance := range rebalanceList { // 冷热调拨：更新调拨状态和冷热钱包余额
+				if err := tx.Rebalances.UpdateRebalanceStatus(rebalance); err != nil {
+					return err
+				}
+				if err := applyRebalanceBalances(tx, rebalance); err != nil {
+					return err
+				}
+			}
+
+			settledSweeps := make(map[common.Hash]bool)
+			for _, sweep := range forwarderSweepList { // 转发合约批量归集：同一交易的锁定余额一次结算，成功时计入热钱包
+				if err := tx.ForwarderSweeps.UpdateForwarderSweepStatus(sweep); err != nil {
+					return err
+				}
+				if settledSweeps[sweep.Hash] {
+					continue
+				}
+				settledSweeps[sweep.Hash] = true
+				if err := settleCollectionLocks(tx, sweep.Hash, sweep.Status == 1, sweep.HotWallet, nil); err != nil {
+					return err
+				}
+			}
+
 			if len(depositTransactionList) > 0 {
 				if err := tx.Transactions.StoreTransactions(depositTransactionList, uint64(len(depositTransactionList))); err != nil {
 					return err
@@ -176,13 +241,32 @@ func (d *Deposit) processBatch(headers []types.Header) error {
 				}
 			}
 
-			if len(tokenBalanceList) > 0 {
-				log.Info("update or store token balance", "tokenBalanceList", len(tokenBalanceList))
-				if err := tx.Balances.UpdateOrCreate(tokenBalanceList); err != nil {
+			for _, deposit := range depositList { // 充值：外部账户转入充值地址可用余额
+				if err := postDepositJournal(tx, deposit); err != nil {
 					return err
 				}
 			}
 
+			for _, otherTx := range outherTransactionList { // 单笔提现和归集上链：成功时结算发送时锁定的余额，失败时退回可用余额
+				if otherTx.Status != 1 && otherTx.Status != 5 {
+					continue
+				}
+				var err error
+				switch otherTx.TxType {
+				case 1:
+					err = settleWithdrawLocks(tx, otherTx.Hash, otherTx.Status == 1)
+				case 2:
+					err = settleCollectionLocks(tx, otherTx.Hash, otherTx.Status == 1, otherTx.ToAddress, otherTx.Amount)
+				}
+				if err != nil {
+					return err
+				}
+			}
+
+			// 批次快照：按本批最后一个区块记录上次快照后有分录且发生变化的余额，首个批次全量比较
+			if _, err := tx.BalanceSnapshots.SnapshotBalances(d.snapshotSince, lastHeader.Number, lastHeader.Time); err != nil {
+				return err
+			}
 			return nil
 		}); err != nil {
 			log.Error("unable to persist batch", "err", err)
@@ -192,21 +276,44 @@ func (d *Deposit) processBatch(headers []types.Header) error {
 	}); err != nil {
 		return err
 	}
+	d.snapshotSince = snapshotStart
 	return nil
 }
 
-func (d *Deposit) processTransactions(txList []node.TransactionList, baseFee string) ([]database.Deposits, []database.Withdraws, []database.Transactions, []database.Transactions, []database.TokenBalance, error) {
+func (d *Deposit) processTransactions(txList []node.TransactionList, baseFee string) ([]database.Deposits, []database.Withdraws, []database.Transactions, []database.Transactions, []database.WithdrawBatches, []database.Rebalances, []database.ForwarderSweeps, error) {
 	if len(txList) == 0 {
 		log.Error("no transactions")
-		return nil, nil, nil, nil, nil, errors.New("no transactions")
+		return nil, nil, nil, nil, nil, nil, nil, errors.New("no transactions")
 	}
 	var depositList []database.Deposits
 	var withdrawList []database.Withdraws
 	var depositTransactionList []database.Transactions
 	var otherTransactionList []database.Transactions
-	var tokenBalanceList []database.TokenBalance
+	var withdrawBatchList []database.WithdrawBatches
+	var rebalanceList []database.Rebalances
+	var forwarderSweepList []database.ForwarderSweeps
 	for _, tx := range txList {
 		txHash := tx.Hash
+		if d.isDisperseContract(tx.To) {
+			withdrawBatch, err := d.HandleWithdrawBatch(common.HexToHash(txHash))
+			if err != nil {
+				log.Error("handle withdraw batch fail", "err", err)
+				return nil, nil, nil, nil, nil, nil, nil, err
+			}
+			if withdrawBatch != nil {
+				withdrawBatchList = append(withdrawBatchList, *withdrawBatch)
+			}
+			continue
+		}
+		if d.isForwarderFactory(tx.To) {
+			sweeps, err := d.HandleForwarderSweep(common.HexToHash(txHash))
+			if err != nil {
+				log.Error("handle forwarder sweep fail", "err", err)
+				return nil, nil, nil, nil, nil, nil, nil, err
+			}
+			forwarderSweepList = append(forwarderSweepList, sweeps...)
+			continue
+		}
 		var isToken bool
 		tokens, err := d.db.Tokens.TokensInfoByAddress(tx.To)
 		if err != nil {
@@ -227,7 +334,7 @@ func (d *Deposit) processTransactions(txList []node.TransactionList, baseFee str
 		transaction, err := d.client.TxByHash(common.HexToHash(txHash))
 		if err != nil {
 			log.Error("get tx fail", err)
-			return nil, nil, nil, nil, nil, err
+			return nil, nil, nil, nil, nil, nil, nil, err
 		}
 		signer := types.LatestSignerForChainID(big.NewInt(int64(d.chainConf.ChainID)))
 		if err != nil {
@@ -291,7 +398,7 @@ func (d *Deposit) processTransactions(txList []node.TransactionList, baseFee str
 		txReceipt, err := d.client.TxReceiptByHash(common.HexToHash(txHash))
 		if err != nil {
 			log.Error("get tx fail", err)
-			return nil, nil, nil, nil, nil, err
+			return nil, nil, nil, nil, nil, nil, nil, err
 		}
 		log.Info("============================================================")
 		log.Info("handle transaction success", "txHash", transaction.Hash().String(), "txReceiptHash", txReceipt.TxHash.String())
@@ -310,9 +417,32 @@ func (d *Deposit) processTransactions(txList []node.TransactionList, baseFee str
 			log.Error("query withdraw transaction fail", "err", err)
 			continue
 		}
+		// 冷热钱包调拨：成功和失败的回执都需要更新调拨状态
+		if ccTx != nil && (ccTx.TxType == 3 || ccTx.TxType == 4) {
+			rebalance, err := d.HandleRebalance(transaction.Hash(), txReceipt)
+			if err != nil {
+				log.Error("handle rebalance fail", "err", err)
+				return nil, nil, nil, nil, nil, nil, nil, err
+			}
+			if rebalance == nil {
+				continue
+			}
+			rebalanceList = append(rebalanceList, *rebalance)
+			tx, err := d.HandleTransaction(transaction, txReceipt, rebalance.Fee, ccTx.TxType, isToken, decValue, fromAddress, toAddress, tokenAddress)
+			if err != nil {
+				log.Error("handle rebalance error", "err", err)
+				return nil, nil, nil, nil, nil, nil, nil, err
+			}
+			otherTransactionList = append(otherTransactionList, tx)
+			continue
+		}
+
+		// gas 站给用户地址补充手续费的交易不是充值，也不是归集
+		isGasFunding := ccTx != nil && ccTx.TxType == database.TxTypeGasFunding
 		var gasPrice *big.Int
 		var transactionFee = big.NewInt(0)
-		if (addressTo != nil && txReceipt.Status == 1) || (ccTx != nil && txReceipt.Status == 1) || (withdraw != nil && txReceipt.Status == 1) {
+		// 提现和归集回执失败时也要更新状态并退回锁定余额，充值和 gas 补充只处理成功的回执
+		if (addressTo != nil && txReceipt.Status == 1) || ccTx != nil || withdraw != nil {
 			/*if txReceipt.Type == types.DynamicFeeTxType {
 				gasPrice = txReceipt.EffectiveGasPrice
 				baseFeeInt, _ := strconv.ParseInt(baseFee, 10, 64)
@@ -326,55 +456,209 @@ func (d *Deposit) processTransactions(txList []node.TransactionList, baseFee str
 			transactionFee.Mul(gasPrice, big.NewInt(int64(txReceipt.GasUsed)))
 
 			// 充值：to 是系统用户地址， from 地址是外部地址
-			if addressTo != nil && txReceipt.Status == 1 && addressFrom == nil {
+			if addressTo != nil && txReceipt.Status == 1 && addressFrom == nil && !isGasFunding {
 				log.Info("Find Deposit transaction", "TxHash", transaction.Hash().String())
 				deposit, err := d.HandleDeposit(transaction, txReceipt, transactionFee, isToken, decValue, fromAddress, toAddress, tokenAddress)
 				if err != nil {
 					log.Error("handle deposit error", "err", err)
-					return nil, nil, nil, nil, nil, err
+					return nil, nil, nil, nil, nil, nil, nil, err
 				}
 				depositList = append(depositList, deposit)
-				tx, tokenBalance, err := d.HandleTransaction(transaction, txReceipt, transactionFee, 0, isToken, decValue, fromAddress, toAddress, tokenAddress)
+				tx, err := d.HandleTransaction(transaction, txReceipt, transactionFee, 0, isToken, decValue, fromAddress, toAddress, tokenAddress)
 				if err != nil {
 					log.Error("handle deposit error", "err", err)
-					return nil, nil, nil, nil, nil, err
+					return nil, nil, nil, nil, nil, nil, nil, err
 				}
 				depositTransactionList = append(depositTransactionList, tx)
-				tokenBalanceList = append(tokenBalanceList, tokenBalance)
 			}
 
 			// 提现：from 地址系统的热钱包地址，to 地址是外部地址
-			if withdraw != nil && txReceipt.Status == 1 && addressFrom != nil && addressTo == nil {
+			if withdraw != nil && addressFrom != nil && addressTo == nil {
 				log.Info("Find withdraw transaction", "TxHash", transaction.Hash().String())
 				withdrawItem, err := d.HandleWithdaw(transaction, txReceipt, transactionFee, isToken, decValue, fromAddress, toAddress, tokenAddress)
 				if err != nil {
 					log.Error("handle deposit error", "err", err)
-					return nil, nil, nil, nil, nil, err
+					return nil, nil, nil, nil, nil, nil, nil, err
 				}
 				withdrawList = append(withdrawList, withdrawItem)
-				tx, tokenBalance, err := d.HandleTransaction(transaction, txReceipt, transactionFee, 1, isToken, decValue, fromAddress, toAddress, tokenAddress)
+				tx, err := d.HandleTransaction(transaction, txReceipt, transactionFee, 1, isToken, decValue, fromAddress, toAddress, tokenAddress)
 				if err != nil {
 					log.Error("handle deposit error", "err", err)
-					return nil, nil, nil, nil, nil, err
+					return nil, nil, nil, nil, nil, nil, nil, err
 				}
 				otherTransactionList = append(otherTransactionList, tx)
-				tokenBalanceList = append(tokenBalanceList, tokenBalance)
 			}
 
 			// 归集：to 地址是系统热钱包地址， from 地址系统用户
 			// 热转冷：from 是系统的热钱包地址，to 地址是系统的冷钱包地址
-			if ccTx != nil && txReceipt.Status == 1 && addressFrom != nil && addressTo != nil {
-				tx, tokenBalance, err := d.HandleTransaction(transaction, txReceipt, transactionFee, 2, isToken, decValue, fromAddress, toAddress, tokenAddress)
+			if ccTx != nil && addressFrom != nil && addressTo != nil && !isGasFunding {
+				tx, err := d.HandleTransaction(transaction, txReceipt, transactionFee, 2, isToken, decValue, fromAddress, toAddress, tokenAddress)
 				if err != nil {
 					log.Error("handle deposit error", "err", err)
-					return nil, nil, nil, nil, nil, err
+					return nil, nil, nil, nil, nil, nil, nil, err
+				}
+				otherTransactionList = append(otherTransactionList, tx)
+			}
+
+			// gas 补充：只更新交易状态和手续费，不变动用户余额
+			if isGasFunding && txReceipt.Status == 1 {
+				tx, err := d.HandleTransaction(transaction, txReceipt, transactionFee, database.TxTypeGasFunding, isToken, decValue, fromAddress, toAddress, tokenAddress)
+				if err != nil {
+					log.Error("handle gas funding error", "err", err)
+					return nil, nil, nil, nil, nil, nil, nil, err
 				}
 				otherTransactionList = append(otherTransactionList, tx)
-				tokenBalanceList = append(tokenBalanceList, tokenBalance)
 			}
 		}
 	}
-	return depositList, withdrawList, depositTransactionList, otherTransactionList, tokenBalanceList, nil
+	return depositList, withdrawList, depositTransactionList, otherTransactionList, withdrawBatchList, rebalanceList, forwarderSweepList, nil
+}
+
+func (d *Deposit) isDisperseContract(to string) bool {
+	if !common.IsHexAddress(d.chainConf.DisperseContract) || !common.IsHexAddress(to) {
+		return false
+	}
+	return common.HexToAddress(to) == common.HexToAddress(d.chainConf.DisperseContract)
+}
+
+// HandleRebalance 根据调拨交易回执确定调拨上链成功或失败
+func (d *Deposit) HandleRebalance(txHash common.Hash, txReceipt *types.Receipt) (*database.Rebalances, error) {
+	rebalance, err := d.db.Rebalances.QueryRebalanceByHash(txHash)
+	if err != nil {
+		return nil, err
+	}
+	if rebalance == nil || rebalance.Status != 2 {
+		return nil, nil
+	}
+	log.Info("Find rebalance transaction", "TxHash", txHash.String(), "status", txReceipt.Status, "direction", rebalance.Direction)
+	if txReceipt.Status == types.ReceiptStatusSuccessful {
+		rebalance.Status = 3
+	} else {
+		rebalance.Status = 5
+	}
+	rebalance.BlockNumber = txReceipt.BlockNumber
+	rebalance.Fee = new(big.Int).Mul(txReceipt.EffectiveGasPrice, new(big.Int).SetUint64(txReceipt.GasUsed))
+	return rebalance, nil
+}
+
+// applyRebalanceBalances 热转冷结算热钱包锁定余额，成功记入冷钱包、失败退回；冷转热成功时从冷钱包转入热钱包
+func applyRebalanceBalances(tx *database.DB, rebalance database.Rebalances) error {
+	success := rebalance.Status == 3
+	if rebalance.Direction == database.RebalanceHotToCold {
+		if !success {
+			return tx.LedgerEntries.SettleLocks(rebalance.Hash, database.LedgerReasonRebalanceLock, database.LedgerReasonRebalanceRelease, nil, nil)
+		}
+		cold := database.AvailableAccount(rebalance.ToAddress, 2)
+		return tx.LedgerEntries.SettleLocks(rebalance.Hash, database.LedgerReasonRebalanceLock, database.LedgerReasonRebalance, &cold, nil)
+	}
+	if !success {
+		return nil
+	}
+	cold := database.AvailableAccount(rebalance.FromAddress, 2)
+	journal := database.NewJournal(database.LedgerReasonRebalance, rebalance.Hash, rebalance.TokenAddress)
+	// 冷钱包可能由链外直接充值，账上不足的部分视为外部转入
+	coldBalance, err := tx.LedgerEntries.QueryLedgerBalance(rebalance.FromAddress, rebalance.TokenAddress, database.LedgerBucketAvailable)
+	if err != nil {
+		return err
+	}
+	if shortfall := new(big.Int).Sub(rebalance.Amount, coldBalance); shortfall.Sign() > 0 {
+		journal.Transfer(database.LedgerExternalAccount, cold, shortfall)
+	}
+	journal.Transfer(cold, database.AvailableAccount(rebalance.ToAddress, 1), rebalance.Amount)
+	return tx.LedgerEntries.PostJournal(journal)
+}
+
+// postDepositJournal 充值记账，新建余额记录时沿用地址表中的地址类型
+func postDepositJournal(tx *database.DB, deposit database.Deposits) error {
+	var addressType uint8
+	addressInfo, err := tx.Addresses.QueryAddressesByToAddress(&deposit.ToAddress)
+	if err != nil {
+		return err
+	}
+	if addressInfo != nil {
+		addressType = addressInfo.AddressType
+	}
+	journal := database.NewJournal(database.LedgerReasonDeposit, deposit.Hash, deposit.TokenAddress).
+		Transfer(database.LedgerExternalAccount, database.AvailableAccount(deposit.ToAddress, addressType), deposit.Amount)
+	return tx.LedgerEntries.PostJournal(journal)
+}
+
+// settleWithdrawLocks 提现上链成功时锁定余额转出到外部账户，失败时退回热钱包可用余额
+func settleWithdrawLocks(tx *database.DB, hash common.Hash, success bool) error {
+	if !success {
+		return tx.LedgerEntries.SettleLocks(hash, database.LedgerReasonWithdrawLock, database.LedgerReasonWithdrawRelease, nil, nil)
+	}
+	return tx.LedgerEntries.SettleLocks(hash, database.LedgerReasonWithdrawLock, database.LedgerReasonWithdraw, &database.LedgerExternalAccount, nil)
+}
+
+// settleCollectionLocks 归集上链成功时锁定余额转入热钱包，received 为热钱包实际到账金额，失败时退回用户地址可用余额
+func settleCollectionLocks(tx *database.DB, hash common.Hash, success bool, hotWallet common.Address, received *big.Int) error {
+	if !success {
+		return tx.LedgerEntries.SettleLocks(hash, database.LedgerReasonCollectionLock, database.LedgerReasonCollectionRelease, nil, nil)
+	}
+	hot := database.AvailableAccount(hotWallet, 1)
+	return tx.LedgerEntries.SettleLocks(hash, database.LedgerReasonCollectionLock, database.LedgerReasonCollection, &hot, received)
+}
+
+func (d *Deposit) isForwarderFactory(to string) bool {
+	if !common.IsHexAddress(d.chainConf.ForwarderFactory) || !common.IsHexAddress(to) {
+		return false
+	}
+	return common.HexToAddress(to) == common.HexToAddress(d.chainConf.ForwarderFactory)
+}
+
+// HandleForwarderSweep 根据工厂合约归集交易回执确定交易内所有转发地址归集成功或失败
+func (d *Deposit) HandleForwarderSweep(txHash common.Hash) ([]database.ForwarderSweeps, error) {
+	sweepList, err := d.db.ForwarderSweeps.QueryForwarderSweepsByHash(txHash)
+	if err != nil {
+		return nil, err
+	}
+	if len(sweepList) == 0 || sweepList[0].Status != 0 {
+		return nil, nil
+	}
+	txReceipt, err := d.client.TxReceiptByHash(txHash)
+	if err != nil {
+		log.Error("get tx receipt fail", "err", err)
+		return nil, err
+	}
+	log.Info("Find forwarder sweep transaction", "TxHash", txHash.String(), "status", txReceipt.Status, "forwarderCount", len(sweepList))
+	status := uint8(2)
+	if txReceipt.Status == types.ReceiptStatusSuccessful {
+		status = 1
+	}
+	fee := new(big.Int).Mul(txReceipt.EffectiveGasPrice, new(big.Int).SetUint64(txReceipt.GasUsed))
+	for i := range sweepList {
+		sweepList[i].Status = status
+		sweepList[i].BlockNumber = txReceipt.BlockNumber
+		sweepList[i].Fee = fee
+	}
+	return sweepList, nil
+}
+
+// HandleWithdrawBatch 根据批量提现交易回执确定批次上链成功或失败
+func (d *Deposit) HandleWithdrawBatch(txHash common.Hash) (*database.WithdrawBatches, error) {
+	withdrawBatch, err := d.db.WithdrawBatches.QueryWithdrawBatchByHash(txHash)
+	if err != nil {
+		return nil, err
+	}
+	if withdrawBatch == nil || withdrawBatch.Status != 0 {
+		return nil, nil
+	}
+	txReceipt, err := d.client.TxReceiptByHash(txHash)
+	if err != nil {
+		log.Error("get tx receipt fail", "err", err)
+		return nil, err
+	}
+	log.Info("Find withdraw batch transaction", "TxHash", txHash.String(), "status", txReceipt.Status, "withdrawCount", withdrawBatch.WithdrawCount)
+	if txReceipt.Status == types.ReceiptStatusSuccessful {
+		withdrawBatch.Status = 1
+	} else {
+		withdrawBatch.Status = 2
+	}
+	withdrawBatch.BlockHash = txReceipt.BlockHash
+	withdrawBatch.BlockNumber = txReceipt.BlockNumber
+	withdrawBatch.Fee = new(big.Int).Mul(txReceipt.EffectiveGasPrice, new(big.Int).SetUint64(txReceipt.GasUsed))
+	return withdrawBatch, nil
 }
 
 func (d *Deposit) HandleDeposit(transaction *types.Transaction, receipt *types.Receipt, Fee *big.Int, isToken bool, decValue *big.Int, fromAddr, toAddr, tokenAddress common.Address) (database.Deposits, error) {
@@ -440,9 +724,17 @@ func (d *Deposit) HandleWithdaw(transaction *types.Transaction, receipt *types.R
 	return withdraw, nil
 }
 
-func (d *Deposit) HandleTransaction(transaction *types.Transaction, receipt *types.Receipt, Fee *big.Int, txtype uint8, isToken bool, decValue *big.Int, fromAddr, toAddr, tokenAddress common.Address) (database.Transactions, database.TokenBalance, error) {
+// transactionStatus 回执成功为已到账，失败为上链失败
+func transactionStatus(receipt *types.Receipt) uint8 {
+	if receipt.Status == types.ReceiptStatusSuccessful {
+		return 1
+	}
+	return 5
+}
+
+func (d *Deposit) HandleTransaction(transaction *types.Transaction, receipt *types.Receipt, Fee *big.Int, txtype uint8, isToken bool, decValue *big.Int, fromAddr, toAddr, tokenAddress common.Address) (database.Transactions, error) {
 	if transaction == nil || receipt == nil {
-		return database.Transactions{}, database.TokenBalance{}, errors.New("transation or receipt is empty")
+		return database.Transactions{}, errors.New("transation or receipt is empty")
 	}
 	var amount *big.Int
 	var toAddress common.Address
@@ -464,18 +756,10 @@ func (d *Deposit) HandleTransaction(transaction *types.Transaction, receipt *typ
 		TokenAddress:     toAddress,
 		Fee:              Fee,
 		Amount:           amount,
-		Status:           uint8(receipt.Status),
+		Status:           transactionStatus(receipt),
 		TransactionIndex: big.NewInt(int64(receipt.TransactionIndex)),
 		TxType:           txtype,
 		Timestamp:        uint64(transaction.Time().Unix()),
 	}
-
-	balance := database.TokenBalance{
-		Address:      toAddr,
-		TokenAddress: tokenAddress,
-		Balance:      transaction.Value(),
-		LockBalance:  big.NewInt(0),
-		TxType:       txtype,
-	}
-	return tx, balance, nil
+	return tx, nil
 }
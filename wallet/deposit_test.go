@@ -0,0 +1,84 @@
+package wallet
+
+import (
+	"encoding/hex"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/the-web3/eth-wallet/config"
+	"github.com/the-web3/eth-wallet/database"
+	"github.com/the-web3/eth-wallet/wallet/signer"
+)
+
+// 单笔提现回执失败：提现标记为上链失败，锁定余额退回热钱包可用余额
+func TestFailedWithdrawReceiptReleasesLock(t *testing.T) {
+	db := newStressDB(t)
+	chainId := big.NewInt(1337)
+	_, token := newStressKey(t)
+	_, external := newStressKey(t)
+	hotKey, hot := newStressKey(t)
+	now := uint64(time.Now().Unix())
+	require.NoError(t, db.Addresses.StoreAddressess([]database.Addresses{{GUID: uuid.New(), Address: hot, AddressType: 1, PrivateKey: hex.EncodeToString(crypto.FromECDSA(hotKey)), Timestamp: now}}, 1))
+	require.NoError(t, db.Tokens.StoreTokens([]database.Tokens{{GUID: uuid.New(), TokenAddress: token, Uint: 18, TokenName: "FAIL", CollectAmount: big.NewInt(1), Timestamp: now}}, 1))
+	// 只在账本中有余额，链上余额不足，提现交易回执失败
+	require.NoError(t, db.LedgerEntries.PostJournal(database.NewJournal(database.LedgerReasonDeposit, common.BytesToHash(uuid.New().NodeID()), token).
+		Transfer(database.LedgerExternalAccount, database.AvailableAccount(hot, 1), big.NewInt(100))))
+
+	head := &types.Header{Number: big.NewInt(100), Extra: uuid.New().NodeID()}
+	latest, err := db.Blocks.LatestBlocks()
+	require.NoError(t, err)
+	if latest != nil {
+		head.Number = new(big.Int).Set(latest.Number)
+	}
+	chain := newStressChain(t, chainId, token, head)
+	cfg := &config.Config{
+		Chain:     config.ChainConfig{ChainID: uint(chainId.Uint64())},
+		HotWallet: config.HotWalletConfig{Strategy: HotWalletStrategyTokenAssign, TokenAssignments: map[common.Address]common.Address{token: hot}},
+	}
+	shutdown := func(cause error) { t.Error(cause) }
+	deposit, err := NewDeposit(cfg, db, chain, shutdown)
+	require.NoError(t, err)
+	withdraw, err := NewWithdraw(cfg, db, chain, signer.NewLocalSigner(db, nil, nil), shutdown)
+	require.NoError(t, err)
+
+	guid := uuid.New()
+	require.NoError(t, db.Withdraws.StoreWithdraws([]database.Withdraws{{
+		GUID:             guid,
+		BlockNumber:      big.NewInt(0),
+		ToAddress:        external,
+		TokenAddress:     token,
+		Fee:              big.NewInt(0),
+		Amount:           big.NewInt(40),
+		TransactionIndex: big.NewInt(0),
+		Timestamp:        now,
+	}}, 1))
+	require.NoError(t, withdraw.processWithdraws())
+	balance, err := db.Balances.QueryWalletBalanceByTokenAndAddress(hot, token)
+	require.NoError(t, err)
+	require.Equal(t, big.NewInt(60), balance.Balance)
+	require.Equal(t, big.NewInt(40), balance.LockBalance)
+
+	header, err := chain.mine(external, big.NewInt(1))
+	require.NoError(t, err)
+	deposit.headers = []types.Header{*header}
+	require.NoError(t, deposit.processBatch(deposit.headers))
+
+	failed, err := db.Withdraws.QueryWithdrawsByGuid(guid)
+	require.NoError(t, err)
+	require.Equal(t, uint8(8), failed.Status)
+	receipt, err := chain.TxReceiptByHash(failed.Hash)
+	require.NoError(t, err)
+	require.Equal(t, types.ReceiptStatusFailed, receipt.Status)
+	balance, err = db.Balances.QueryWalletBalanceByTokenAndAddress(hot, token)
+	require.NoError(t, err)
+	require.Equal(t, big.NewInt(100), balance.Balance)
+	require.Equal(t, big.NewInt(0), balance.LockBalance)
+}
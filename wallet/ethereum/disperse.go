@@ -0,0 +1,25 @@
+package ethereum
+
+import (
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// DisperseABI disperse.app 合约的批量转账方法，disperseToken 通过 transferFrom 扣款，热钱包需要预先 approve 该合约
+const DisperseABI = `[
+	{"name":"disperseEther","type":"function","stateMutability":"payable","inputs":[{"name":"recipients","type":"address[]"},{"name":"values","type":"uint256[]"}],"outputs":[]},
+	{"name":"disperseToken","type":"function","stateMutability":"nonpayable","inputs":[{"name":"token","type":"address"},{"name":"recipients","type":"address[]"},{"name":"values","type":"uint256[]"}],"outputs":[]}
+]`
+
+var disperseAbi, _ = abi.JSON(strings.NewReader(DisperseABI))
+
+func BuildDisperseEtherData(recipients []common.Address, values []*big.Int) ([]byte, error) {
+	return disperseAbi.Pack("disperseEther", recipients, values)
+}
+
+func BuildDisperseTokenData(tokenAddress common.Address, recipients []common.Address, values []*big.Int) ([]byte, error) {
+	return disperseAbi.Pack("disperseToken", tokenAddress, recipients, values)
+}
@@ -0,0 +1,35 @@
+package ethereum
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestBuildDisperseData(t *testing.T) {
+	recipients := []common.Address{common.HexToAddress("0x35096AD62E57e86032a3Bb35aDaCF2240d55421D"), common.HexToAddress("0x72ffaa289993bcada2e01612995e5c75dd81cdbc")}
+	values := []*big.Int{big.NewInt(1000), big.NewInt(2000)}
+
+	etherData, err := BuildDisperseEtherData(recipients, values)
+	require.NoError(t, err)
+	require.Equal(t, crypto.Keccak256([]byte("disperseEther(address[],uint256[])"))[:4], etherData[:4])
+
+	unpacked, err := disperseAbi.Methods["disperseEther"].Inputs.Unpack(etherData[4:])
+	require.NoError(t, err)
+	require.Equal(t, recipients, unpacked[0].([]common.Address))
+	require.Equal(t, values, unpacked[1].([]*big.Int))
+
+	tokenAddress := common.HexToAddress("0xC02aaA39b223FE8D0A0e5C4F27eAD9083C756Cc2")
+	tokenData, err := BuildDisperseTokenData(tokenAddress, recipients, values)
+	require.NoError(t, err)
+	require.Equal(t, crypto.Keccak256([]byte("disperseToken(address,address[],uint256[])"))[:4], tokenData[:4])
+
+	unpacked, err = disperseAbi.Methods["disperseToken"].Inputs.Unpack(tokenData[4:])
+	require.NoError(t, err)
+	require.Equal(t, tokenAddress, unpacked[0].(common.Address))
+	require.Equal(t, recipients, unpacked[1].([]common.Address))
+}
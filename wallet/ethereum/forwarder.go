@@ -0,0 +1,34 @@
+package ethereum
+
+import (
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// ForwarderFactoryABI 充值转发合约工厂的批量归集方法：按 salt 依次取得转发合约，未部署时先用 CREATE2 部署，再把 token（零地址为 ETH）全部余额转给 to，只允许热钱包调用
+const ForwarderFactoryABI = `[
+	{"name":"sweep","type":"function","stateMutability":"nonpayable","inputs":[{"name":"token","type":"address"},{"name":"salts","type":"bytes32[]"},{"name":"to","type":"address"}],"outputs":[]}
+]`
+
+var forwarderFactoryAbi, _ = abi.JSON(strings.NewReader(ForwarderFactoryABI))
+
+// ForwarderSalt 用户充值转发地址的 salt
+func ForwarderSalt(userUid string) common.Hash {
+	return crypto.Keccak256Hash([]byte(userUid))
+}
+
+// ForwarderAddress 按 CREATE2 规则计算工厂合约用 salt 部署的转发合约地址，合约无需部署即可接收充值
+func ForwarderAddress(factory common.Address, salt common.Hash, initCodeHash common.Hash) common.Address {
+	return crypto.CreateAddress2(factory, salt, initCodeHash.Bytes())
+}
+
+func BuildForwarderSweepData(tokenAddress common.Address, salts []common.Hash, to common.Address) ([]byte, error) {
+	saltList := make([][32]byte, len(salts))
+	for i, salt := range salts {
+		saltList[i] = salt
+	}
+	return forwarderFactoryAbi.Pack("sweep", tokenAddress, saltList, to)
+}
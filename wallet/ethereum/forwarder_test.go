@@ -0,0 +1,37 @@
+package ethereum
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestForwarderAddress(t *testing.T) {
+	// EIP-1014 示例：factory 与 salt 全零，init code 为 0x00
+	address := ForwarderAddress(common.Address{}, common.Hash{}, crypto.Keccak256Hash([]byte{0x00}))
+	require.Equal(t, common.HexToAddress("0x4D1A2e2bB4F88F0250f26Ffff098B0b30B26BF38"), address)
+
+	factory := common.HexToAddress("0x00000000000000000000000000000000deadbeef")
+	initCodeHash := crypto.Keccak256Hash(common.FromHex("0xdeadbeef"))
+	require.Equal(t, common.HexToAddress("0x60f3f640a8508fC6a86d45DF051962668E1e8AC7"), ForwarderAddress(factory, common.HexToHash("0xcafebabe"), initCodeHash))
+	require.NotEqual(t, ForwarderAddress(factory, ForwarderSalt("user-1"), initCodeHash), ForwarderAddress(factory, ForwarderSalt("user-2"), initCodeHash))
+}
+
+func TestBuildForwarderSweepData(t *testing.T) {
+	tokenAddress := common.HexToAddress("0xC02aaA39b223FE8D0A0e5C4F27eAD9083C756Cc2")
+	hotWallet := common.HexToAddress("0x35096AD62E57e86032a3Bb35aDaCF2240d55421D")
+	salts := []common.Hash{ForwarderSalt("user-1"), ForwarderSalt("user-2")}
+
+	data, err := BuildForwarderSweepData(tokenAddress, salts, hotWallet)
+	require.NoError(t, err)
+	require.Equal(t, crypto.Keccak256([]byte("sweep(address,bytes32[],address)"))[:4], data[:4])
+
+	unpacked, err := forwarderFactoryAbi.Methods["sweep"].Inputs.Unpack(data[4:])
+	require.NoError(t, err)
+	require.Equal(t, tokenAddress, unpacked[0].(common.Address))
+	require.Equal(t, [][32]byte{salts[0], salts[1]}, unpacked[1].([][32]byte))
+	require.Equal(t, hotWallet, unpacked[2].(common.Address))
+}
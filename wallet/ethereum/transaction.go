@@ -7,7 +7,6 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
-	"github.com/ethereum/go-ethereum/rlp"
 )
 
 func BuildErc20Data(toAddress common.Address, amount *big.Int) []byte {
@@ -15,7 +14,7 @@ func BuildErc20Data(toAddress common.Address, amount *big.Int) []byte {
 
 	transferFnSignature := []byte("transfer(address,uint256)")
 	hash := crypto.Keccak256Hash(transferFnSignature)
-	methodId := hash[:5]
+	methodId := hash[:4]
 	dataAddress := common.LeftPadBytes(toAddress.Bytes(), 32)
 	dataAmount := common.LeftPadBytes(amount.Bytes(), 32)
 
@@ -26,12 +25,27 @@ func BuildErc20Data(toAddress common.Address, amount *big.Int) []byte {
 	return data
 }
 
+// BuildBalanceOfData 构造 ERC20 balanceOf(address) 调用数据
+func BuildBalanceOfData(owner common.Address) []byte {
+	var data []byte
+
+	balanceOfFnSignature := []byte("balanceOf(address)")
+	hash := crypto.Keccak256Hash(balanceOfFnSignature)
+	methodId := hash[:4]
+	dataOwner := common.LeftPadBytes(owner.Bytes(), 32)
+
+	data = append(data, methodId...)
+	data = append(data, dataOwner...)
+
+	return data
+}
+
 func BuildErc721Data(fromAddress, toAddress common.Address, tokenId *big.Int) []byte {
 	var data []byte
 
 	transferFnSignature := []byte("safeTransferFrom(address,address,uint256)")
 	hash := crypto.Keccak256Hash(transferFnSignature)
-	methodId := hash[:5]
+	methodId := hash[:4]
 
 	dataFromAddress := common.LeftPadBytes(fromAddress.Bytes(), 32)
 	dataToAddress := common.LeftPadBytes(toAddress.Bytes(), 32)
@@ -61,7 +75,12 @@ func OfflineSignTx(txData *types.DynamicFeeTx, privateKey string, chainId *big.I
 		return "", "", err
 	}
 
-	signedTxData, err := rlp.EncodeToBytes(signedTx)
+	return EncodeSignedTx(signedTx)
+}
+
+// EncodeSignedTx 将已签名交易编码为 eth_sendRawTransaction 所需的十六进制字符串，并返回交易哈希
+func EncodeSignedTx(signedTx *types.Transaction) (string, string, error) {
+	signedTxData, err := signedTx.MarshalBinary()
 	if err != nil {
 		return "", "", err
 	}
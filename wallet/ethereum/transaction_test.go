@@ -1,10 +1,13 @@
 package ethereum
 
 import (
+	"encoding/hex"
 	"fmt"
 	"math/big"
 	"testing"
 
+	"github.com/stretchr/testify/require"
+
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
 )
@@ -31,3 +34,9 @@ func TestOfflineSignTx(t *testing.T) {
 	txHex, txHash, _ := OfflineSignTx(dFeeTx, privateKeyHex, chainID)
 	fmt.Println("txHex===", txHex, "txHash==", txHash)
 }
+
+func TestBuildErc20Data(t *testing.T) {
+	data := BuildErc20Data(common.HexToAddress("0x35096AD62E57e86032a3Bb35aDaCF2240d55421D"), big.NewInt(1000))
+	require.Len(t, data, 68)
+	require.Equal(t, "a9059cbb", hex.EncodeToString(data[:4]))
+}
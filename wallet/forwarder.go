@@ -0,0 +1,160 @@
+package wallet
+
+import (
+	"errors"
+	"math/big"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/the-web3/eth-wallet/database"
+	"github.com/the-web3/eth-wallet/wallet/ethereum"
+	"github.com/the-web3/eth-wallet/wallet/retry"
+)
+
+var (
+	ForwarderSweepBaseGasLimit    uint64 = 60000
+	ForwarderSweepGasPerForwarder uint64 = 120000 // 包含首次归集时部署转发合约的开销
+	ForwarderSweepMaxCount               = 50
+)
+
+// forwarderBalance 待工厂合约归集的转发地址余额
+type forwarderBalance struct {
+	balance database.Balances
+	salt    common.Hash
+}
+
+func (cc *CollectionCold) forwarderEnable() bool {
+	return common.IsHexAddress(cc.chainConf.ForwarderFactory)
+}
+
+// forwarderSalt 用户地址为 CREATE2 转发地址时返回其 salt
+func (cc *CollectionCold) forwarderSalt(address common.Address) (common.Hash, bool, error) {
+	if !cc.forwarderEnable() {
+		return common.Hash{}, false, nil
+	}
+	addressInfo, err := cc.db.Addresses.QueryAddressesByToAddress(&address)
+	if err != nil {
+		return common.Hash{}, false, err
+	}
+	if addressInfo == nil || addressInfo.ForwarderSalt == "" {
+		return common.Hash{}, false, nil
+	}
+	return common.HexToHash(addressInfo.ForwarderSalt), true, nil
+}
+
+// sweepForwarders 每个币种按批次由热钱包调用一次工厂合约归集多个转发地址，转发地址不需要 gas
+func (cc *CollectionCold) sweepForwarders(tokenList []common.Address, forwarders map[common.Address][]forwarderBalance) error {
+	for _, tokenAddress := range tokenList {
+		forwarderList := forwarders[tokenAddress]
+		for start := 0; start < len(forwarderList); start += ForwarderSweepMaxCount {
+			end := min(start+ForwarderSweepMaxCount, len(forwarderList))
+			if err := cc.sweepForwarderBatch(tokenAddress, forwarderList[start:end]); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (cc *CollectionCold) sweepForwarderBatch(tokenAddress common.Address, forwarderList []forwarderBalance) error {
+	hotWallet, err := cc.hotWallets.SelectForCollection(tokenAddress)
+	if err != nil {
+		log.Error("select hot wallet fail", "err", err)
+		return err
+	}
+	if hotWallet == nil {
+		log.Warn("no hot wallet for forwarder sweep", "tokenAddress", tokenAddress)
+		return nil
+	}
+	nonce, err := cc.hotWallets.NextNonce(hotWallet.Address)
+	if errors.Is(err, ErrHotWalletStuck) {
+		return nil
+	}
+	if err != nil {
+		log.Error("query nonce by address fail", "address", hotWallet.Address, "err", err)
+		return nil
+	}
+	dFeeTx, err := cc.buildForwarderSweepTx(tokenAddress, forwarderList, hotWallet.Address, nonce)
+	if err != nil {
+		return err
+	}
+	// 模拟回滚的交易不广播，避免白白消耗手续费
+	if err := simulateTx(cc.client, hotWallet.Address, dFeeTx); err != nil {
+		log.Warn("skip forwarder sweep tx that fails simulation", "tokenAddress", tokenAddress, "count", len(forwarderList), "err", err)
+		return nil
+	}
+	rawTx, txHash, err := cc.signer.SignTx(hotWallet.Address, dFeeTx, big.NewInt(int64(cc.chainConf.ChainID)))
+	if err != nil {
+		log.Error("offline transaction fail", "err", err)
+		return err
+	}
+	log.Info("Offline sign forwarder sweep tx success", "rawTx", rawTx, "tokenAddress", tokenAddress, "count", len(forwarderList))
+
+	lockJournal := database.NewJournal(database.LedgerReasonCollectionLock, common.HexToHash(txHash), tokenAddress)
+	sweepList := make([]database.ForwarderSweeps, len(forwarderList))
+	for i, forwarder := range forwarderList {
+		lockJournal.Transfer(database.AvailableAccount(forwarder.balance.Address, 0), database.LockedAccount(forwarder.balance.Address, 0), forwarder.balance.Balance)
+		sweepList[i] = database.ForwarderSweeps{
+			GUID:             uuid.New(),
+			Hash:             common.HexToHash(txHash),
+			HotWallet:        hotWallet.Address,
+			ForwarderAddress: forwarder.balance.Address,
+			TokenAddress:     tokenAddress,
+			Amount:           forwarder.balance.Balance,
+			Fee:              big.NewInt(0),
+			BlockNumber:      big.NewInt(0),
+			Status:           0,
+			TxSignHex:        rawTx,
+			Timestamp:        uint64(time.Now().Unix()),
+		}
+	}
+	retryStrategy := &retry.ExponentialStrategy{Min: 1000, Max: 20_000, MaxJitter: 250}
+	if _, err := retry.Do[interface{}](cc.resourceCtx, 10, retryStrategy, func() (interface{}, error) {
+		if err := cc.db.Transaction(func(tx *database.DB) error {
+			if err := tx.LedgerEntries.PostJournal(lockJournal); err != nil {
+				return err
+			}
+			return tx.ForwarderSweeps.StoreForwarderSweeps(sweepList)
+		}); err != nil {
+			log.Error("unable to persist forwarder sweep", "err", err)
+			return nil, err
+		}
+		return nil, nil
+	}); err != nil {
+		return err
+	}
+
+	// 已持久化，广播失败由重新广播任务继续发送，nonce 被占用时归集记录标记失败并释放锁定余额
+	if err := cc.client.SendRawTransaction(rawTx); err != nil {
+		log.Error("send forwarder sweep tx fail", "hotWallet", hotWallet.Address, "err", err)
+	}
+	return nil
+}
+
+func (cc *CollectionCold) buildForwarderSweepTx(tokenAddress common.Address, forwarderList []forwarderBalance, to common.Address, nonce uint64) (*types.DynamicFeeTx, error) {
+	salts := make([]common.Hash, len(forwarderList))
+	for i, forwarder := range forwarderList {
+		salts[i] = forwarder.salt
+	}
+	buildData, err := ethereum.BuildForwarderSweepData(tokenAddress, salts, to)
+	if err != nil {
+		log.Error("build forwarder sweep data fail", "err", err)
+		return nil, err
+	}
+	factory := common.HexToAddress(cc.chainConf.ForwarderFactory)
+	return &types.DynamicFeeTx{
+		ChainID:   big.NewInt(int64(cc.chainConf.ChainID)),
+		Nonce:     nonce,
+		GasTipCap: maxPriorityFeePerGas,
+		GasFeeCap: maxFeePerGas,
+		Gas:       ForwarderSweepBaseGasLimit + ForwarderSweepGasPerForwarder*uint64(len(forwarderList)),
+		To:        &factory,
+		Value:     big.NewInt(0),
+		Data:      buildData,
+	}, nil
+}
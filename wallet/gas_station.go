@@ -0,0 +1,157 @@
+package wallet
+
+import (
+	"errors"
+	"math/big"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/the-web3/eth-wallet/database"
+	"github.com/the-web3/eth-wallet/wallet/retry"
+)
+
+// GasFundingTimeout 补充交易超过该时间仍未上链则视为失败，下一轮重新补充
+var GasFundingTimeout = 30 * time.Minute
+
+// gasTopUpAmount 返回地址补足一笔 token 归集手续费所需的金额，gasFeeCap 为归集交易实际使用的手续费上限，余额足够时返回 0
+func gasTopUpAmount(balance *big.Int, gasFeeCap *big.Int) *big.Int {
+	need := new(big.Int).Mul(new(big.Int).SetUint64(TokenGasLimit), gasFeeCap)
+	if balance.Cmp(need) >= 0 {
+		return big.NewInt(0)
+	}
+	return need.Sub(need, balance)
+}
+
+func (cc *CollectionCold) gasStationEnable() bool {
+	return common.IsHexAddress(cc.chainConf.GasStationAddress)
+}
+
+// prepareCollectionGas 检查用户地址是否有足够的 ETH 支付 token 归集手续费，不足时由 gas 站钱包补充
+// 返回 true 表示可以归集，同时返回需要关联归集交易的补充记录；返回 false 表示等待补充交易上链
+func (cc *CollectionCold) prepareCollectionGas(uncollect database.Balances, gasFeeCap *big.Int, stationNonce map[common.Address]uint64) (*database.GasFundings, bool, error) {
+	funding, err := cc.db.GasFundings.QueryPendingGasFunding(uncollect.Address, uncollect.TokenAddress)
+	if err != nil {
+		log.Error("query pending gas funding fail", "address", uncollect.Address, "err", err)
+		return nil, false, err
+	}
+	if funding != nil {
+		receipt, err := cc.client.TxReceiptByHash(funding.FundingHash)
+		if errors.Is(err, ethereum.NotFound) {
+			if time.Since(time.Unix(int64(funding.Timestamp), 0)) < GasFundingTimeout {
+				log.Info("wait gas funding tx to be mined", "address", uncollect.Address, "hash", funding.FundingHash)
+				return nil, false, nil
+			}
+			log.Warn("gas funding tx not mined in time, fund again", "address", uncollect.Address, "hash", funding.FundingHash)
+			return nil, false, cc.db.GasFundings.MarkGasFundingFailed(funding.GUID)
+		}
+		if err != nil {
+			log.Error("query gas funding receipt fail", "hash", funding.FundingHash, "err", err)
+			return nil, false, nil
+		}
+		if receipt.Status != types.ReceiptStatusSuccessful {
+			log.Warn("gas funding tx failed, fund again", "address", uncollect.Address, "hash", funding.FundingHash)
+			return nil, false, cc.db.GasFundings.MarkGasFundingFailed(funding.GUID)
+		}
+	}
+
+	balance, err := cc.client.BalanceAt(uncollect.Address)
+	if err != nil {
+		log.Error("query native balance fail", "address", uncollect.Address, "err", err)
+		return nil, false, nil
+	}
+	topUp := gasTopUpAmount(balance, gasFeeCap)
+	if topUp.Sign() == 0 {
+		return funding, true, nil
+	}
+	if funding != nil {
+		// 补充已上链但余额仍不足（如 gas 价格上涨），作废后重新补充差额
+		if err := cc.db.GasFundings.MarkGasFundingFailed(funding.GUID); err != nil {
+			return nil, false, err
+		}
+	}
+	return nil, false, cc.sendGasFunding(uncollect, topUp, stationNonce)
+}
+
+// sendGasFunding 从 gas 站钱包向用户地址发送刚好够归集手续费的 ETH
+// 先持久化补充记录再广播，广播前后崩溃时下一轮能看到待上链的补充，不会重复补充
+func (cc *CollectionCold) sendGasFunding(uncollect database.Balances, topUp *big.Int, stationNonce map[common.Address]uint64) error {
+	station := common.HexToAddress(cc.chainConf.GasStationAddress)
+	nonce, ok := stationNonce[station]
+	if !ok {
+		pendingNonce, err := cc.client.PendingTxCountByAddress(station)
+		if err != nil {
+			log.Error("query gas station nonce fail", "address", station, "err", err)
+			return nil
+		}
+		nonce = uint64(pendingNonce)
+	}
+	dFeeTx := &types.DynamicFeeTx{
+		ChainID:   big.NewInt(int64(cc.chainConf.ChainID)),
+		Nonce:     nonce,
+		GasTipCap: maxPriorityFeePerGas,
+		GasFeeCap: maxFeePerGas,
+		Gas:       EthGasLimit,
+		To:        &uncollect.Address,
+		Value:     topUp,
+	}
+	rawTx, txHash, err := cc.signer.SignTx(station, dFeeTx, big.NewInt(int64(cc.chainConf.ChainID)))
+	if err != nil {
+		log.Error("offline transaction fail", "err", err)
+		return err
+	}
+	now := uint64(time.Now().Unix())
+	funding := database.GasFundings{
+		GUID:          uuid.New(),
+		Address:       uncollect.Address,
+		TokenAddress:  uncollect.TokenAddress,
+		FundingHash:   common.HexToHash(txHash),
+		FundingAmount: topUp,
+		Status:        0,
+		Timestamp:     now,
+	}
+	fundingTx := database.Transactions{
+		GUID:             uuid.New(),
+		BlockHash:        common.Hash{},
+		BlockNumber:      big.NewInt(1),
+		Hash:             common.HexToHash(txHash),
+		FromAddress:      station,
+		ToAddress:        uncollect.Address,
+		TokenAddress:     common.Address{},
+		Fee:              big.NewInt(1),
+		Amount:           topUp,
+		Status:           0,
+		TxType:           database.TxTypeGasFunding,
+		TransactionIndex: big.NewInt(time.Now().Unix()),
+		Timestamp:        now,
+	}
+	retryStrategy := &retry.ExponentialStrategy{Min: 1000, Max: 20_000, MaxJitter: 250}
+	if _, err := retry.Do[interface{}](cc.resourceCtx, 10, retryStrategy, func() (interface{}, error) {
+		if err := cc.db.Transaction(func(tx *database.DB) error {
+			if err := tx.GasFundings.StoreGasFunding(funding); err != nil {
+				return err
+			}
+			return tx.Transactions.StoreTransactions([]database.Transactions{fundingTx}, 1)
+		}); err != nil {
+			log.Error("unable to persist gas funding", "err", err)
+			return nil, err
+		}
+		return nil, nil
+	}); err != nil {
+		return err
+	}
+	stationNonce[station] = nonce + 1
+
+	if err := cc.client.SendRawTransaction(rawTx); err != nil {
+		// 补充记录已持久化，未上链的补充超过 GasFundingTimeout 后作废重新补充
+		log.Error("send gas funding tx fail", "address", uncollect.Address, "hash", txHash, "err", err)
+		return err
+	}
+	log.Info("send gas funding tx success", "address", uncollect.Address, "tokenAddress", uncollect.TokenAddress, "amount", topUp, "hash", txHash)
+	return nil
+}
@@ -0,0 +1,20 @@
+package wallet
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGasTopUpAmount(t *testing.T) {
+	gasFeeCap := big.NewInt(30_000_000_000)
+	need := new(big.Int).Mul(new(big.Int).SetUint64(TokenGasLimit), gasFeeCap)
+
+	require.Equal(t, need, gasTopUpAmount(big.NewInt(0), gasFeeCap))
+	require.Equal(t, big.NewInt(1), gasTopUpAmount(new(big.Int).Sub(need, big.NewInt(1)), gasFeeCap))
+	require.Equal(t, 0, gasTopUpAmount(need, gasFeeCap).Sign())
+	require.Equal(t, 0, gasTopUpAmount(new(big.Int).Add(need, big.NewInt(1)), gasFeeCap).Sign())
+	// gas 价格上涨后按新的手续费上限补足差额
+	require.Equal(t, new(big.Int).SetUint64(TokenGasLimit), gasTopUpAmount(need, new(big.Int).Add(gasFeeCap, big.NewInt(1))))
+}
@@ -0,0 +1,187 @@
+package hdwallet
+
+import (
+	"crypto/ecdsa"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+
+	"golang.org/x/crypto/pbkdf2"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/the-web3/eth-wallet/config"
+)
+
+var (
+	ErrInvalidChildKey = errors.New("invalid child key, try next index")
+	ErrInvalidMnemonic = errors.New("invalid mnemonic")
+)
+
+// extendedKey BIP-32 扩展私钥
+type extendedKey struct {
+	key       []byte
+	chainCode []byte
+}
+
+// HDWallet 从 BIP-39 助记词派生 m/44'/60'/0'/0/i 路径下的地址私钥
+type HDWallet struct {
+	accountKey *extendedKey
+}
+
+// NewSeedFromMnemonic 按 BIP-39 由助记词和密码生成种子，助记词须在英文词表中且校验和正确
+func NewSeedFromMnemonic(mnemonic, passphrase string) ([]byte, error) {
+	words := strings.Fields(mnemonic)
+	if err := validateMnemonic(words); err != nil {
+		return nil, err
+	}
+	return pbkdf2.Key([]byte(strings.Join(words, " ")), []byte("mnemonic"+passphrase), 2048, 64, sha512.New), nil
+}
+
+// validateMnemonic 每个单词编码 11 位，前 ENT 位为熵，末尾 ENT/32 位须等于 sha256(熵) 的前几位
+func validateMnemonic(words []string) error {
+	switch len(words) {
+	case 12, 15, 18, 21, 24:
+	default:
+		return fmt.Errorf("%w: word count %d", ErrInvalidMnemonic, len(words))
+	}
+	bits := new(big.Int)
+	for _, word := range words {
+		index, ok := englishWordIndex[word]
+		if !ok {
+			return fmt.Errorf("%w: unknown word %q", ErrInvalidMnemonic, word)
+		}
+		bits.Lsh(bits, 11)
+		bits.Or(bits, big.NewInt(int64(index)))
+	}
+	checksumBits := uint(len(words) * 11 / 33)
+	checksum := new(big.Int).And(bits, big.NewInt(1<<checksumBits-1))
+	entropy := common.LeftPadBytes(new(big.Int).Rsh(bits, checksumBits).Bytes(), int(checksumBits)*4)
+	hash := sha256.Sum256(entropy)
+	if uint64(hash[0]>>(8-checksumBits)) != checksum.Uint64() {
+		return fmt.Errorf("%w: checksum mismatch", ErrInvalidMnemonic)
+	}
+	return nil
+}
+
+func NewFromMnemonic(mnemonic, passphrase string) (*HDWallet, error) {
+	seed, err := NewSeedFromMnemonic(mnemonic, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	return NewFromSeed(seed)
+}
+
+func NewFromSeed(seed []byte) (*HDWallet, error) {
+	master, err := newMasterKey(seed)
+	if err != nil {
+		return nil, err
+	}
+	// 缓存 m/44'/60'/0'/0，派生地址时只需再做一次非硬化派生
+	basePath := accounts.DefaultBaseDerivationPath
+	accountKey, err := master.derivePath(basePath[:len(basePath)-1])
+	if err != nil {
+		return nil, err
+	}
+	return &HDWallet{accountKey: accountKey}, nil
+}
+
+// DerivePrivateKey 派生 m/44'/60'/0'/0/index 的私钥
+func (w *HDWallet) DerivePrivateKey(index uint32) (*ecdsa.PrivateKey, error) {
+	if index >= hardenedKeyStart {
+		return nil, fmt.Errorf("derivation index %d out of range", index)
+	}
+	child, err := w.accountKey.child(index)
+	if err != nil {
+		return nil, err
+	}
+	return crypto.ToECDSA(child.key)
+}
+
+func (w *HDWallet) DeriveAddress(index uint32) (common.Address, error) {
+	privateKey, err := w.DerivePrivateKey(index)
+	if err != nil {
+		return common.Address{}, err
+	}
+	return crypto.PubkeyToAddress(privateKey.PublicKey), nil
+}
+
+const hardenedKeyStart = 0x80000000
+
+func newMasterKey(seed []byte) (*extendedKey, error) {
+	mac := hmac.New(sha512.New, []byte("Bitcoin seed"))
+	mac.Write(seed)
+	sum := mac.Sum(nil)
+	key := new(big.Int).SetBytes(sum[:32])
+	if key.Sign() == 0 || key.Cmp(crypto.S256().Params().N) >= 0 {
+		return nil, errors.New("invalid master key from seed")
+	}
+	return &extendedKey{key: sum[:32], chainCode: sum[32:]}, nil
+}
+
+func (k *extendedKey) derivePath(path accounts.DerivationPath) (*extendedKey, error) {
+	var err error
+	key := k
+	for _, index := range path {
+		key, err = key.child(index)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return key, nil
+}
+
+// child BIP-32 CKDpriv
+func (k *extendedKey) child(index uint32) (*extendedKey, error) {
+	var data []byte
+	if index >= hardenedKeyStart {
+		data = append([]byte{0x00}, k.key...)
+	} else {
+		privateKey, err := crypto.ToECDSA(k.key)
+		if err != nil {
+			return nil, err
+		}
+		data = crypto.CompressPubkey(&privateKey.PublicKey)
+	}
+	data = binary.BigEndian.AppendUint32(data, index)
+
+	mac := hmac.New(sha512.New, k.chainCode)
+	mac.Write(data)
+	sum := mac.Sum(nil)
+
+	curveOrder := crypto.S256().Params().N
+	il := new(big.Int).SetBytes(sum[:32])
+	if il.Cmp(curveOrder) >= 0 {
+		return nil, ErrInvalidChildKey
+	}
+	childKey := il.Add(il, new(big.Int).SetBytes(k.key))
+	childKey.Mod(childKey, curveOrder)
+	if childKey.Sign() == 0 {
+		return nil, ErrInvalidChildKey
+	}
+	return &extendedKey{key: common.LeftPadBytes(childKey.Bytes(), 32), chainCode: sum[32:]}, nil
+}
+
+// NewHDWallet 按配置创建 HD 钱包，未配置助记词时返回 nil
+func NewHDWallet(cfg *config.HDWalletConfig) (*HDWallet, error) {
+	mnemonic := cfg.Mnemonic
+	if mnemonic == "" && cfg.MnemonicFile != "" {
+		content, err := os.ReadFile(cfg.MnemonicFile)
+		if err != nil {
+			return nil, fmt.Errorf("read mnemonic file fail: %w", err)
+		}
+		mnemonic = string(content)
+	}
+	if strings.TrimSpace(mnemonic) == "" {
+		return nil, nil
+	}
+	return NewFromMnemonic(mnemonic, cfg.Passphrase)
+}
@@ -0,0 +1,65 @@
+package hdwallet
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestDeriveAddress(t *testing.T) {
+	wallet, err := NewFromMnemonic("test test test test test test test test test test test junk", "")
+	require.NoError(t, err)
+
+	address, err := wallet.DeriveAddress(0)
+	require.NoError(t, err)
+	require.Equal(t, common.HexToAddress("0xf39Fd6e51aad88F6F4ce6aB8827279cffFb92266"), address)
+
+	address, err = wallet.DeriveAddress(1)
+	require.NoError(t, err)
+	require.Equal(t, common.HexToAddress("0x70997970C51812dc3A010C7d01b50e0d17dc79C8"), address)
+
+	_, err = wallet.DeriveAddress(hardenedKeyStart)
+	require.Error(t, err)
+
+	_, err = NewFromMnemonic("test test test", "")
+	require.Error(t, err)
+}
+
+func TestValidateMnemonic(t *testing.T) {
+	for _, mnemonic := range []string{
+		"abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about",
+		"legal winner thank year wave sausage worth useful legal winner thank yellow",
+		"zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo wrong",
+		"hamster diagram private dutch cause delay private meat slide toddler razor book happy fancy gospel tennis maple dilemma loan word shrug inflict delay length",
+	} {
+		_, err := NewSeedFromMnemonic(mnemonic, "")
+		require.NoError(t, err, mnemonic)
+	}
+
+	// 校验和错误
+	_, err := NewSeedFromMnemonic("abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon", "")
+	require.ErrorIs(t, err, ErrInvalidMnemonic)
+	// 不在词表中的单词
+	_, err = NewSeedFromMnemonic("abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abouts", "")
+	require.ErrorIs(t, err, ErrInvalidMnemonic)
+	require.Len(t, englishWordList, 2048)
+}
+
+// BIP-32 test vector 1: m/0'/1/2'/2/1000000000
+func TestBip32Vector(t *testing.T) {
+	seed, err := hex.DecodeString("000102030405060708090a0b0c0d0e0f")
+	require.NoError(t, err)
+	master, err := newMasterKey(seed)
+	require.NoError(t, err)
+	require.Equal(t, "e8f32e723decf4051aefac8e2c93c9c5b214313817cdb01a1494b917c8436b35", hex.EncodeToString(master.key))
+
+	path, err := accounts.ParseDerivationPath("m/0'/1/2'/2/1000000000")
+	require.NoError(t, err)
+	key, err := master.derivePath(path)
+	require.NoError(t, err)
+	require.Equal(t, "471b76e389e528d6de6d816857e012c5455051cad6660850e58372a6c3e6e7c8", hex.EncodeToString(key.key))
+}
@@ -0,0 +1,196 @@
+package wallet
+
+import (
+	"errors"
+	"math/big"
+	"sync/atomic"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/the-web3/eth-wallet/config"
+	"github.com/the-web3/eth-wallet/database"
+	"github.com/the-web3/eth-wallet/wallet/node"
+)
+
+const (
+	HotWalletStrategyRoundRobin  = "round-robin"
+	HotWalletStrategyMostFunded  = "most-funded"
+	HotWalletStrategyTokenAssign = "token-assign"
+)
+
+var ErrHotWalletStuck = errors.New("hot wallet has too many pending transactions")
+
+type hotWalletCandidate struct {
+	wallet  database.Addresses
+	balance *big.Int
+}
+
+// HotWalletSelector 在多个热钱包之间按策略选择提现付款地址和归集收款地址
+type HotWalletSelector struct {
+	db        *database.DB
+	client    node.EthClient
+	conf      *config.HotWalletConfig
+	nextIndex atomic.Uint64
+}
+
+func NewHotWalletSelector(cfg *config.Config, db *database.DB, client node.EthClient) *HotWalletSelector {
+	return &HotWalletSelector{
+		db:     db,
+		client: client,
+		conf:   &cfg.HotWallet,
+	}
+}
+
+// SelectForWithdraw 选出可用余额足够支付 amount 的热钱包，exclude 中的热钱包不参与选择
+func (s *HotWalletSelector) SelectForWithdraw(tokenAddress common.Address, amount *big.Int, exclude map[common.Address]bool) (*database.Addresses, error) {
+	candidates, err := s.candidates(tokenAddress, exclude)
+	if err != nil {
+		return nil, err
+	}
+	var fundedCandidates []hotWalletCandidate
+	for _, candidate := range candidates {
+		if candidate.balance.Cmp(amount) >= 0 {
+			fundedCandidates = append(fundedCandidates, candidate)
+		}
+	}
+	return s.pick(tokenAddress, fundedCandidates, false), nil
+}
+
+// SelectForCollection 选出归集的目标热钱包，尽量把归集资金分散到不同热钱包
+func (s *HotWalletSelector) SelectForCollection(tokenAddress common.Address) (*database.Addresses, error) {
+	candidates, err := s.candidates(tokenAddress, nil)
+	if err != nil {
+		return nil, err
+	}
+	return s.pick(tokenAddress, candidates, true), nil
+}
+
+// NextNonce 返回热钱包下一笔交易的 nonce，待打包交易过多时认为该热钱包 nonce 卡住
+// 已持久化但广播失败的交易不在节点的待打包列表中，nonce 取两者中较大的，避免把同一 nonce 分配给新交易
+func (s *HotWalletSelector) NextNonce(address common.Address) (uint64, error) {
+	latestNonce, err := s.client.TxCountByAddress(address)
+	if err != nil {
+		return 0, err
+	}
+	pendingNonce, err := s.client.PendingTxCountByAddress(address)
+	if err != nil {
+		return 0, err
+	}
+	persistedNonce, err := s.persistedNextNonce(address)
+	if err != nil {
+		log.Error("query persisted nonce fail", "address", address, "err", err)
+		return 0, err
+	}
+	nextNonce := max(uint64(pendingNonce), persistedNonce)
+	if s.conf.MaxPendingTx > 0 && nextNonce > uint64(latestNonce)+uint64(s.conf.MaxPendingTx) {
+		log.Warn("hot wallet nonce is stuck", "address", address, "latestNonce", latestNonce, "pendingNonce", pendingNonce, "persistedNonce", persistedNonce)
+		return 0, ErrHotWalletStuck
+	}
+	return nextNonce, nil
+}
+
+// persistedNextNonce 热钱包已签名持久化但未上链的提现、批量提现和调拨交易中最大 nonce 加一，没有时返回 0
+func (s *HotWalletSelector) persistedNextNonce(address common.Address) (uint64, error) {
+	var rawTxList []string
+	withdrawList, err := s.db.Withdraws.QueryUnconfirmedWithdraws()
+	if err != nil {
+		return 0, err
+	}
+	for _, withdraw := range withdrawList {
+		rawTxList = append(rawTxList, withdraw.TxSignHex)
+	}
+	batchList, err := s.db.WithdrawBatches.QueryUnconfirmedWithdrawBatches()
+	if err != nil {
+		return 0, err
+	}
+	for _, batch := range batchList {
+		rawTxList = append(rawTxList, batch.TxSignHex)
+	}
+	rebalanceList, err := s.db.Rebalances.QueryUnconfirmedRebalances()
+	if err != nil {
+		return 0, err
+	}
+	for _, rebalance := range rebalanceList {
+		rawTxList = append(rawTxList, rebalance.TxSignHex)
+	}
+	return nextSignedNonce(address, rawTxList), nil
+}
+
+// nextSignedNonce 已签名交易中由 address 发出的最大 nonce 加一
+func nextSignedNonce(address common.Address, rawTxList []string) uint64 {
+	var nextNonce uint64
+	for _, rawTx := range rawTxList {
+		signedTx, from, err := decodeSignedTx(rawTx)
+		if err != nil || from != address {
+			continue
+		}
+		nextNonce = max(nextNonce, signedTx.Nonce()+1)
+	}
+	return nextNonce
+}
+
+func (s *HotWalletSelector) candidates(tokenAddress common.Address, exclude map[common.Address]bool) ([]hotWalletCandidate, error) {
+	hotWalletList, err := s.db.Addresses.QueryHotWalletList()
+	if err != nil {
+		log.Error("query hot wallet list fail", "err", err)
+		return nil, err
+	}
+	var candidates []hotWalletCandidate
+	for _, hotWallet := range hotWalletList {
+		if exclude[hotWallet.Address] {
+			continue
+		}
+		balance := big.NewInt(0)
+		tokenBalance, err := s.db.Balances.QueryWalletBalanceByTokenAndAddress(hotWallet.Address, tokenAddress)
+		if err != nil {
+			log.Error("query hot wallet balance fail", "address", hotWallet.Address, "err", err)
+			return nil, err
+		}
+		if tokenBalance != nil && tokenBalance.Balance != nil {
+			balance = tokenBalance.Balance
+		}
+		candidates = append(candidates, hotWalletCandidate{wallet: hotWallet, balance: balance})
+	}
+	return candidates, nil
+}
+
+func (s *HotWalletSelector) pick(tokenAddress common.Address, candidates []hotWalletCandidate, forCollection bool) *database.Addresses {
+	var assigned *common.Address
+	if assignedWallet, ok := s.conf.TokenAssignments[tokenAddress]; ok {
+		assigned = &assignedWallet
+	}
+	index := selectHotWallet(s.conf.Strategy, assigned, candidates, forCollection, s.nextIndex.Add(1)-1)
+	if index < 0 {
+		return nil
+	}
+	return &candidates[index].wallet
+}
+
+// selectHotWallet 返回按策略选中的候选热钱包下标，没有可选热钱包时返回 -1
+func selectHotWallet(strategy string, assigned *common.Address, candidates []hotWalletCandidate, forCollection bool, counter uint64) int {
+	if len(candidates) == 0 {
+		return -1
+	}
+	switch strategy {
+	case HotWalletStrategyTokenAssign:
+		if assigned != nil {
+			for i, candidate := range candidates {
+				if candidate.wallet.Address == *assigned {
+					return i
+				}
+			}
+			return -1
+		}
+	case HotWalletStrategyMostFunded:
+		selected := 0
+		for i, candidate := range candidates {
+			cmp := candidate.balance.Cmp(candidates[selected].balance)
+			if (!forCollection && cmp > 0) || (forCollection && cmp < 0) {
+				selected = i
+			}
+		}
+		return selected
+	}
+	return int(counter % uint64(len(candidates)))
+}
@@ -0,0 +1,134 @@
+package wallet
+
+import (
+	"encoding/hex"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/the-web3/eth-wallet/config"
+	"github.com/the-web3/eth-wallet/database"
+	ethwallet "github.com/the-web3/eth-wallet/wallet/ethereum"
+	"github.com/the-web3/eth-wallet/wallet/signer"
+)
+
+func testHotWalletCandidates() []hotWalletCandidate {
+	return []hotWalletCandidate{
+		{wallet: database.Addresses{Address: common.HexToAddress("0x01")}, balance: big.NewInt(300)},
+		{wallet: database.Addresses{Address: common.HexToAddress("0x02")}, balance: big.NewInt(900)},
+		{wallet: database.Addresses{Address: common.HexToAddress("0x03")}, balance: big.NewInt(100)},
+	}
+}
+
+func TestSelectHotWalletRoundRobin(t *testing.T) {
+	candidates := testHotWalletCandidates()
+	require.Equal(t, -1, selectHotWallet(HotWalletStrategyRoundRobin, nil, nil, false, 0))
+	require.Equal(t, 0, selectHotWallet(HotWalletStrategyRoundRobin, nil, candidates, false, 0))
+	require.Equal(t, 1, selectHotWallet(HotWalletStrategyRoundRobin, nil, candidates, false, 1))
+	require.Equal(t, 0, selectHotWallet(HotWalletStrategyRoundRobin, nil, candidates, true, 3))
+}
+
+func TestSelectHotWalletMostFunded(t *testing.T) {
+	candidates := testHotWalletCandidates()
+	require.Equal(t, 1, selectHotWallet(HotWalletStrategyMostFunded, nil, candidates, false, 7))
+	require.Equal(t, 2, selectHotWallet(HotWalletStrategyMostFunded, nil, candidates, true, 7))
+}
+
+func TestSelectHotWalletTokenAssign(t *testing.T) {
+	candidates := testHotWalletCandidates()
+	assigned := common.HexToAddress("0x03")
+	require.Equal(t, 2, selectHotWallet(HotWalletStrategyTokenAssign, &assigned, candidates, false, 0))
+
+	missing := common.HexToAddress("0x04")
+	require.Equal(t, -1, selectHotWallet(HotWalletStrategyTokenAssign, &missing, candidates, false, 0))
+
+	// 没有绑定热钱包的币种按轮询选择
+	require.Equal(t, 1, selectHotWallet(HotWalletStrategyTokenAssign, nil, candidates, false, 4))
+}
+
+func TestNextSignedNonce(t *testing.T) {
+	privateKey := "0cbb2ff952da876c4779200c83f6b90d73ea85a8da82e06c2276a11499922720"
+	key, err := crypto.HexToECDSA(privateKey)
+	require.NoError(t, err)
+	sender := crypto.PubkeyToAddress(key.PublicKey)
+	toAddress := common.HexToAddress("0x35096AD62E57e86032a3Bb35aDaCF2240d55421D")
+	var rawTxList []string
+	for _, nonce := range []uint64{7, 9, 8} {
+		rawTx, _, err := ethwallet.OfflineSignTx(&types.DynamicFeeTx{
+			ChainID:   big.NewInt(1),
+			Nonce:     nonce,
+			GasTipCap: maxPriorityFeePerGas,
+			GasFeeCap: maxFeePerGas,
+			Gas:       EthGasLimit,
+			To:        &toAddress,
+			Value:     big.NewInt(1000),
+		}, privateKey, big.NewInt(1))
+		require.NoError(t, err)
+		rawTxList = append(rawTxList, rawTx)
+	}
+	require.Equal(t, uint64(10), nextSignedNonce(sender, rawTxList))
+	// 其它地址发出的交易和无法解码的交易不计入
+	require.Equal(t, uint64(0), nextSignedNonce(toAddress, rawTxList))
+	require.Equal(t, uint64(0), nextSignedNonce(sender, []string{"0x01"}))
+}
+
+// TestNextNonceAfterFailedBroadcast 第一轮提现已持久化但广播失败，第二轮提现不能复用同一个 nonce
+func TestNextNonceAfterFailedBroadcast(t *testing.T) {
+	db := newStressDB(t)
+	chainId := big.NewInt(1337)
+	_, token := newStressKey(t)
+	_, external := newStressKey(t)
+	hotKey, hot := newStressKey(t)
+	now := uint64(time.Now().Unix())
+	require.NoError(t, db.Addresses.StoreAddressess([]database.Addresses{{GUID: uuid.New(), Address: hot, AddressType: 1, PrivateKey: hex.EncodeToString(crypto.FromECDSA(hotKey)), Timestamp: now}}, 1))
+	require.NoError(t, db.LedgerEntries.PostJournal(database.NewJournal(database.LedgerReasonDeposit, common.BytesToHash(uuid.New().NodeID()), token).
+		Transfer(database.LedgerExternalAccount, database.AvailableAccount(hot, 1), big.NewInt(100))))
+
+	chain := newStressChain(t, chainId, token, &types.Header{Number: big.NewInt(1)})
+	cfg := &config.Config{
+		Chain:     config.ChainConfig{ChainID: uint(chainId.Uint64())},
+		HotWallet: config.HotWalletConfig{Strategy: HotWalletStrategyTokenAssign, TokenAssignments: map[common.Address]common.Address{token: hot}},
+	}
+	withdraw, err := NewWithdraw(cfg, db, chain, signer.NewLocalSigner(db, nil, nil), func(cause error) { t.Error(cause) })
+	require.NoError(t, err)
+
+	tick := func() uuid.UUID {
+		guid := uuid.New()
+		require.NoError(t, db.Withdraws.StoreWithdraws([]database.Withdraws{{
+			GUID:             guid,
+			BlockNumber:      big.NewInt(0),
+			ToAddress:        external,
+			TokenAddress:     token,
+			Fee:              big.NewInt(0),
+			Amount:           big.NewInt(1),
+			TransactionIndex: big.NewInt(0),
+			Timestamp:        uint64(time.Now().Unix()),
+		}}, 1))
+		require.NoError(t, withdraw.processWithdraws())
+		return guid
+	}
+	signedNonce := func(guid uuid.UUID) uint64 {
+		signed, err := db.Withdraws.QueryWithdrawsByGuid(guid)
+		require.NoError(t, err)
+		require.NotNil(t, signed)
+		require.NotEmpty(t, signed.TxSignHex)
+		signedTx, from, err := decodeSignedTx(signed.TxSignHex)
+		require.NoError(t, err)
+		require.Equal(t, hot, from)
+		return signedTx.Nonce()
+	}
+
+	chain.failSend = true
+	first := tick()
+	chain.failSend = false
+	second := tick()
+	require.Equal(t, uint64(0), signedNonce(first))
+	require.Equal(t, uint64(1), signedNonce(second))
+}
@@ -0,0 +1,131 @@
+package kms
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+const dataKeyLength = 32
+
+// AddressBoundPrefix 私钥密文以地址作为 AES-GCM 附加数据，密文无法挪到其它地址下解密；没有前缀的是绑定地址之前的历史密文，由 rekey 重新加密
+const AddressBoundPrefix = "v2:"
+
+var ErrUnknownKeyId = errors.New("unknown master key id")
+
+// KMS 负责用主密钥包装和解包数据密钥，私钥本身只用数据密钥加密
+type KMS interface {
+	// KeyId 返回当前用于包装新数据密钥的主密钥标识
+	KeyId() string
+	WrapKey(dataKey []byte) ([]byte, error)
+	UnwrapKey(keyId string, wrappedKey []byte) ([]byte, error)
+}
+
+// SealPrivateKey 生成新的数据密钥加密私钥并绑定地址，返回带前缀的十六进制密文、被包装的数据密钥和主密钥标识
+func SealPrivateKey(k KMS, privateKey string, address common.Address) (string, string, string, error) {
+	dataKey := make([]byte, dataKeyLength)
+	if _, err := io.ReadFull(rand.Reader, dataKey); err != nil {
+		return "", "", "", err
+	}
+	ciphertext, err := aesGCMSeal(dataKey, []byte(privateKey), address.Bytes())
+	if err != nil {
+		return "", "", "", err
+	}
+	wrappedKey, err := k.WrapKey(dataKey)
+	if err != nil {
+		return "", "", "", err
+	}
+	return AddressBoundPrefix + hex.EncodeToString(ciphertext), hex.EncodeToString(wrappedKey), k.KeyId(), nil
+}
+
+// OpenPrivateKey 解包数据密钥并以地址为附加数据解密私钥，仅在签名和 rekey 时调用
+func OpenPrivateKey(k KMS, sealedKey, wrappedKey, keyId string, address common.Address) (string, error) {
+	dataKey, err := unwrapHexKey(k, wrappedKey, keyId)
+	if err != nil {
+		return "", err
+	}
+	var additionalData []byte
+	if IsAddressBound(sealedKey) {
+		sealedKey = strings.TrimPrefix(sealedKey, AddressBoundPrefix)
+		additionalData = address.Bytes()
+	}
+	ciphertext, err := hex.DecodeString(sealedKey)
+	if err != nil {
+		return "", err
+	}
+	privateKey, err := aesGCMOpen(dataKey, ciphertext, additionalData)
+	if err != nil {
+		return "", err
+	}
+	return string(privateKey), nil
+}
+
+// IsAddressBound 密文是否已绑定地址
+func IsAddressBound(sealedKey string) bool {
+	return strings.HasPrefix(sealedKey, AddressBoundPrefix)
+}
+
+// RewrapKey 用当前主密钥重新包装数据密钥，私钥密文不变
+func RewrapKey(k KMS, wrappedKey, keyId string) (string, string, error) {
+	dataKey, err := unwrapHexKey(k, wrappedKey, keyId)
+	if err != nil {
+		return "", "", err
+	}
+	newWrappedKey, err := k.WrapKey(dataKey)
+	if err != nil {
+		return "", "", err
+	}
+	return hex.EncodeToString(newWrappedKey), k.KeyId(), nil
+}
+
+func unwrapHexKey(k KMS, wrappedKey, keyId string) ([]byte, error) {
+	wrapped, err := hex.DecodeString(wrappedKey)
+	if err != nil {
+		return nil, err
+	}
+	dataKey, err := k.UnwrapKey(keyId, wrapped)
+	if err != nil {
+		return nil, fmt.Errorf("unwrap data key fail: %w", err)
+	}
+	return dataKey, nil
+}
+
+// aesGCMSeal 输出 nonce || ciphertext，additionalData 不加密但参与认证，解密时必须一致
+func aesGCMSeal(key, plaintext, additionalData []byte) ([]byte, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+	return aead.Seal(nonce, nonce, plaintext, additionalData), nil
+}
+
+func aesGCMOpen(key, ciphertext, additionalData []byte) ([]byte, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(ciphertext) < aead.NonceSize() {
+		return nil, errors.New("ciphertext too short")
+	}
+	nonce, sealed := ciphertext[:aead.NonceSize()], ciphertext[aead.NonceSize():]
+	return aead.Open(nil, nonce, sealed, additionalData)
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
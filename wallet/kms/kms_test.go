@@ -0,0 +1,60 @@
+package kms
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestSealAndRewrapPrivateKey(t *testing.T) {
+	oldMasterKey := bytes.Repeat([]byte{1}, 32)
+	newMasterKey := bytes.Repeat([]byte{2}, 32)
+	privateKey := "0cbb2ff952da876c4779200c83f6b90d73ea85a8da82e06c2276a11499922720"
+	address := common.HexToAddress("0x35096AD62E57e86032a3Bb35aDaCF2240d55421D")
+
+	oldKMS, err := NewLocalKMS(oldMasterKey)
+	require.NoError(t, err)
+	sealedKey, wrappedKey, keyId, err := SealPrivateKey(oldKMS, privateKey, address)
+	require.NoError(t, err)
+	require.NotContains(t, sealedKey, privateKey)
+	require.True(t, IsAddressBound(sealedKey))
+
+	opened, err := OpenPrivateKey(oldKMS, sealedKey, wrappedKey, keyId, address)
+	require.NoError(t, err)
+	require.Equal(t, privateKey, opened)
+
+	// 密文绑定了地址，挪到其它地址下无法解密
+	_, err = OpenPrivateKey(oldKMS, sealedKey, wrappedKey, keyId, common.HexToAddress("0x70997970C51812dc3A010C7d01b50e0d17dc79C8"))
+	require.Error(t, err)
+
+	// 轮换主密钥后，未重新包装的数据密钥只有带上旧主密钥才能解包
+	newKMS, err := NewLocalKMS(newMasterKey)
+	require.NoError(t, err)
+	_, err = OpenPrivateKey(newKMS, sealedKey, wrappedKey, keyId, address)
+	require.ErrorIs(t, err, ErrUnknownKeyId)
+
+	rotateKMS, err := NewLocalKMS(newMasterKey, oldMasterKey)
+	require.NoError(t, err)
+	newWrappedKey, newKeyId, err := RewrapKey(rotateKMS, wrappedKey, keyId)
+	require.NoError(t, err)
+	require.Equal(t, newKMS.KeyId(), newKeyId)
+
+	opened, err = OpenPrivateKey(newKMS, sealedKey, newWrappedKey, newKeyId, address)
+	require.NoError(t, err)
+	require.Equal(t, privateKey, opened)
+
+	// 绑定地址之前的历史密文不带前缀，仍可解密以便 rekey 重新加密
+	dataKey, err := newKMS.UnwrapKey(newKeyId, common.FromHex(newWrappedKey))
+	require.NoError(t, err)
+	legacy, err := aesGCMSeal(dataKey, []byte(privateKey), nil)
+	require.NoError(t, err)
+	opened, err = OpenPrivateKey(newKMS, common.Bytes2Hex(legacy), newWrappedKey, newKeyId, address)
+	require.NoError(t, err)
+	require.Equal(t, privateKey, opened)
+
+	_, err = NewLocalKMS([]byte("short"))
+	require.Error(t, err)
+}
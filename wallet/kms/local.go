@@ -0,0 +1,91 @@
+package kms
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/the-web3/eth-wallet/config"
+)
+
+// LocalKMS 使用本地提供的 AES-256 主密钥，previous 中的旧主密钥只用于解包，便于轮换
+type LocalKMS struct {
+	keyId      string
+	masterKeys map[string][]byte
+}
+
+func NewLocalKMS(masterKey []byte, previousKeys ...[]byte) (*LocalKMS, error) {
+	k := &LocalKMS{masterKeys: make(map[string][]byte)}
+	for i, key := range append([][]byte{masterKey}, previousKeys...) {
+		if len(key) != dataKeyLength {
+			return nil, fmt.Errorf("master key must be %d bytes, got %d", dataKeyLength, len(key))
+		}
+		keyId := masterKeyId(key)
+		if i == 0 {
+			k.keyId = keyId
+		}
+		k.masterKeys[keyId] = key
+	}
+	return k, nil
+}
+
+// NewKMS 按配置创建 KMS，未配置主密钥时返回 nil
+func NewKMS(cfg *config.KMSConfig) (KMS, error) {
+	masterKey, err := loadMasterKey(cfg.MasterKey, cfg.MasterKeyFile)
+	if err != nil {
+		return nil, err
+	}
+	if masterKey == nil {
+		return nil, nil
+	}
+	var previousKeys [][]byte
+	for _, keyFile := range cfg.PreviousMasterKeyFiles {
+		previousKey, err := loadMasterKey("", keyFile)
+		if err != nil {
+			return nil, err
+		}
+		if previousKey != nil {
+			previousKeys = append(previousKeys, previousKey)
+		}
+	}
+	return NewLocalKMS(masterKey, previousKeys...)
+}
+
+func (k *LocalKMS) KeyId() string {
+	return k.keyId
+}
+
+func (k *LocalKMS) WrapKey(dataKey []byte) ([]byte, error) {
+	return aesGCMSeal(k.masterKeys[k.keyId], dataKey, nil)
+}
+
+func (k *LocalKMS) UnwrapKey(keyId string, wrappedKey []byte) ([]byte, error) {
+	masterKey, ok := k.masterKeys[keyId]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownKeyId, keyId)
+	}
+	return aesGCMOpen(masterKey, wrappedKey, nil)
+}
+
+// masterKeyId 取主密钥哈希的前 8 字节作为标识，不泄露主密钥本身
+func masterKeyId(masterKey []byte) string {
+	hash := sha256.Sum256(masterKey)
+	return "local:" + hex.EncodeToString(hash[:8])
+}
+
+func loadMasterKey(value, keyFile string) ([]byte, error) {
+	if value == "" && keyFile != "" {
+		content, err := os.ReadFile(keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("read master key file fail: %w", err)
+		}
+		value = string(content)
+	}
+	value = strings.TrimPrefix(strings.TrimSpace(value), "0x")
+	if value == "" {
+		return nil, nil
+	}
+	return hex.DecodeString(value)
+}
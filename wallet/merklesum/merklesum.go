@@ -0,0 +1,98 @@
+package merklesum
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+var ErrLeafOutOfRange = errors.New("leaf index out of range")
+
+// Node 默克尔求和树节点：父节点哈希覆盖左右子节点的哈希和金额，金额为左右之和
+type Node struct {
+	Hash common.Hash `json:"hash"`
+	Sum  *big.Int    `json:"sum"`
+}
+
+// ProofNode 证明路径上的兄弟节点，Left 表示兄弟节点在左侧
+type ProofNode struct {
+	Hash common.Hash `json:"hash"`
+	Sum  string      `json:"sum"`
+	Left bool        `json:"left"`
+}
+
+// LeafHash 用户叶子节点哈希，salt 防止通过哈希反推用户和余额
+func LeafHash(salt common.Hash, userId string, balance *big.Int) common.Hash {
+	return crypto.Keccak256Hash(salt.Bytes(), crypto.Keccak256([]byte(userId)), common.LeftPadBytes(balance.Bytes(), 32))
+}
+
+func parent(left, right Node) Node {
+	return Node{
+		Hash: crypto.Keccak256Hash(left.Hash.Bytes(), common.LeftPadBytes(left.Sum.Bytes(), 32), right.Hash.Bytes(), common.LeftPadBytes(right.Sum.Bytes(), 32)),
+		Sum:  new(big.Int).Add(left.Sum, right.Sum),
+	}
+}
+
+// Tree 逐层保存的默克尔求和树，层节点数为奇数时以零节点补齐
+type Tree struct {
+	levels    [][]Node
+	leafCount int
+}
+
+func NewTree(leaves []Node) *Tree {
+	if len(leaves) == 0 {
+		return &Tree{levels: [][]Node{{{Sum: big.NewInt(0)}}}}
+	}
+	levels := [][]Node{leaves}
+	for level := leaves; len(level) > 1; {
+		if len(level)%2 == 1 {
+			level = append(level[:len(level):len(level)], Node{Sum: big.NewInt(0)})
+			levels[len(levels)-1] = level
+		}
+		next := make([]Node, len(level)/2)
+		for i := range next {
+			next[i] = parent(level[2*i], level[2*i+1])
+		}
+		levels = append(levels, next)
+		level = next
+	}
+	return &Tree{levels: levels, leafCount: len(leaves)}
+}
+
+func (t *Tree) Root() Node {
+	return t.levels[len(t.levels)-1][0]
+}
+
+// Proof 返回第 index 个叶子到根的兄弟节点
+func (t *Tree) Proof(index int) ([]ProofNode, error) {
+	if index < 0 || index >= t.leafCount {
+		return nil, ErrLeafOutOfRange
+	}
+	var proof []ProofNode
+	for _, level := range t.levels[:len(t.levels)-1] {
+		sibling := index ^ 1
+		proof = append(proof, ProofNode{Hash: level[sibling].Hash, Sum: level[sibling].Sum.String(), Left: sibling < index})
+		index /= 2
+	}
+	return proof, nil
+}
+
+// VerifyProof 从叶子沿证明路径计算根节点，哈希和总额都与 root 一致才通过
+func VerifyProof(leaf Node, proof []ProofNode, root Node) bool {
+	node := leaf
+	for _, sibling := range proof {
+		sum, ok := new(big.Int).SetString(sibling.Sum, 10)
+		if !ok || sum.Sign() < 0 {
+			return false
+		}
+		siblingNode := Node{Hash: sibling.Hash, Sum: sum}
+		if sibling.Left {
+			node = parent(siblingNode, node)
+		} else {
+			node = parent(node, siblingNode)
+		}
+	}
+	return node.Hash == root.Hash && node.Sum.Cmp(root.Sum) == 0
+}
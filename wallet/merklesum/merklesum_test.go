@@ -0,0 +1,40 @@
+package merklesum
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestTreeProof(t *testing.T) {
+	var leaves []Node
+	for i, userId := range []string{"alice", "bob", "carol", "dave", "erin"} {
+		balance := big.NewInt(int64(100 * (i + 1)))
+		salt := common.BigToHash(big.NewInt(int64(i)))
+		leaves = append(leaves, Node{Hash: LeafHash(salt, userId, balance), Sum: balance})
+	}
+	tree := NewTree(leaves)
+	root := tree.Root()
+	require.Equal(t, big.NewInt(1500), root.Sum)
+
+	for i, leaf := range leaves {
+		proof, err := tree.Proof(i)
+		require.NoError(t, err)
+		require.True(t, VerifyProof(leaf, proof, root))
+	}
+
+	// 篡改兄弟节点金额或叶子余额都无法通过校验
+	proof, err := tree.Proof(2)
+	require.NoError(t, err)
+	proof[0].Sum = "0"
+	require.False(t, VerifyProof(leaves[2], proof, root))
+	proof, err = tree.Proof(2)
+	require.NoError(t, err)
+	require.False(t, VerifyProof(Node{Hash: leaves[2].Hash, Sum: big.NewInt(1)}, proof, root))
+
+	_, err = tree.Proof(len(leaves))
+	require.ErrorIs(t, err, ErrLeafOutOfRange)
+}
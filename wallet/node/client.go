@@ -18,6 +18,7 @@ import (
 	"github.com/ethereum/go-ethereum/rpc"
 
 	"github.com/the-web3/eth-wallet/common/global_const"
+	ethwallet "github.com/the-web3/eth-wallet/wallet/ethereum"
 	retry2 "github.com/the-web3/eth-wallet/wallet/retry"
 )
 
@@ -43,6 +44,12 @@ type RpcBlock struct {
 	BaseFee      string            `json:"baseFeePerGas"`
 }
 
+// BalanceQuery 查询 Address 持有的 TokenAddress 余额，TokenAddress 为零地址时查询 ETH
+type BalanceQuery struct {
+	Address      common.Address
+	TokenAddress common.Address
+}
+
 type EthClient interface {
 	BlockHeaderByNumber(*big.Int) (*types.Header, error)
 	BlockByNumber(*big.Int) (*RpcBlock, error)
@@ -55,9 +62,13 @@ type EthClient interface {
 	StorageHash(common.Address, *big.Int) (common.Hash, error)
 	FilterLogs(filterQuery ethereum.FilterQuery, chainId uint) (Logs, error)
 	TxCountByAddress(common.Address) (hexutil.Uint64, error)
+	PendingTxCountByAddress(common.Address) (hexutil.Uint64, error)
 	SendRawTransaction(rawTx string) error
+	CallContract(msg ethereum.CallMsg) ([]byte, error)
 	SuggestGasPrice() (*big.Int, error)
 	SuggestGasTipCap() (*big.Int, error)
+	BalanceAt(common.Address) (*big.Int, error)
+	BalancesAtBlock([]BalanceQuery, *big.Int) ([]*big.Int, error)
 	Close()
 }
 
@@ -341,6 +352,18 @@ func (c *clnt) TxCountByAddress(address common.Address) (hexutil.Uint64, error)
 	return nonce, err
 }
 
+func (c *clnt) PendingTxCountByAddress(address common.Address) (hexutil.Uint64, error) {
+	ctxwt, cancel := context.WithTimeout(context.Background(), defaultRequestTimeout)
+	defer cancel()
+	var nonce hexutil.Uint64
+	err := c.rpc.CallContext(ctxwt, &nonce, "eth_getTransactionCount", address, "pending")
+	if err != nil {
+		log.Error("Call eth_getTransactionCount method fail", "err", err)
+		return 0, err
+	}
+	return nonce, err
+}
+
 func (c *clnt) SendRawTransaction(rawTx string) error {
 	ctxwt, cancel := context.WithTimeout(context.Background(), defaultRequestTimeout)
 	defer cancel()
@@ -351,6 +374,17 @@ func (c *clnt) SendRawTransaction(rawTx string) error {
 	return nil
 }
 
+// CallContract 基于 pending 状态执行 eth_call，用于广播前模拟交易
+func (c *clnt) CallContract(msg ethereum.CallMsg) ([]byte, error) {
+	ctxwt, cancel := context.WithTimeout(context.Background(), defaultRequestTimeout)
+	defer cancel()
+	var result hexutil.Bytes
+	if err := c.rpc.CallContext(ctxwt, &result, "eth_call", toCallArg(msg), "pending"); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
 func (c *clnt) SuggestGasPrice() (*big.Int, error) {
 	ctxwt, cancel := context.WithTimeout(context.Background(), defaultRequestTimeout)
 	defer cancel()
@@ -371,6 +405,51 @@ func (c *clnt) SuggestGasTipCap() (*big.Int, error) {
 	return (*big.Int)(&hex), nil
 }
 
+// BalanceAt 查询地址在 pending 状态下的原生币余额
+func (c *clnt) BalanceAt(address common.Address) (*big.Int, error) {
+	ctxwt, cancel := context.WithTimeout(context.Background(), defaultRequestTimeout)
+	defer cancel()
+	var hex hexutil.Big
+	if err := c.rpc.CallContext(ctxwt, &hex, "eth_getBalance", address, "pending"); err != nil {
+		return nil, err
+	}
+	return (*big.Int)(&hex), nil
+}
+
+// BalancesAtBlock 在同一个批量请求中查询指定区块高度的 ETH 和 ERC20 余额，结果与 queries 顺序一致
+func (c *clnt) BalancesAtBlock(queries []BalanceQuery, blockNumber *big.Int) ([]*big.Int, error) {
+	ctxwt, cancel := context.WithTimeout(context.Background(), defaultRequestTimeout)
+	defer cancel()
+	results := make([]hexutil.Bytes, len(queries))
+	batchElems := make([]rpc.BatchElem, len(queries))
+	for i, query := range queries {
+		if query.TokenAddress == (common.Address{}) {
+			batchElems[i] = rpc.BatchElem{Method: "eth_getBalance", Args: []interface{}{query.Address, toBlockNumArg(blockNumber)}, Result: new(hexutil.Big)}
+			continue
+		}
+		msg := ethereum.CallMsg{To: &query.TokenAddress, Data: ethwallet.BuildBalanceOfData(query.Address)}
+		batchElems[i] = rpc.BatchElem{Method: "eth_call", Args: []interface{}{toCallArg(msg), toBlockNumArg(blockNumber)}, Result: &results[i]}
+	}
+	if err := c.rpc.BatchCallContext(ctxwt, batchElems); err != nil {
+		return nil, err
+	}
+	balances := make([]*big.Int, len(queries))
+	for i, batchElem := range batchElems {
+		if batchElem.Error != nil {
+			return nil, fmt.Errorf("query balance of %s token %s fail: %w", queries[i].Address, queries[i].TokenAddress, batchElem.Error)
+		}
+		if balance, ok := batchElem.Result.(*hexutil.Big); ok {
+			balances[i] = (*big.Int)(balance)
+			continue
+		}
+		if len(results[i]) == 0 {
+			return nil, fmt.Errorf("token %s returns empty balanceOf", queries[i].TokenAddress)
+		}
+		balances[i] = new(big.Int).SetBytes(results[i])
+	}
+	return balances, nil
+}
+
 func (c *clnt) Close() {
 	c.rpc.Close()
 }
@@ -393,6 +472,29 @@ func (c *rpcClient) BatchCallContext(ctx context.Context, b []rpc.BatchElem) err
 	return err
 }
 
+func toCallArg(msg ethereum.CallMsg) interface{} {
+	arg := map[string]interface{}{
+		"from": msg.From,
+		"to":   msg.To,
+	}
+	if len(msg.Data) > 0 {
+		arg["input"] = hexutil.Bytes(msg.Data)
+	}
+	if msg.Value != nil {
+		arg["value"] = (*hexutil.Big)(msg.Value)
+	}
+	if msg.Gas != 0 {
+		arg["gas"] = hexutil.Uint64(msg.Gas)
+	}
+	if msg.GasFeeCap != nil {
+		arg["maxFeePerGas"] = (*hexutil.Big)(msg.GasFeeCap)
+	}
+	if msg.GasTipCap != nil {
+		arg["maxPriorityFeePerGas"] = (*hexutil.Big)(msg.GasTipCap)
+	}
+	return arg
+}
+
 func toBlockNumArg(number *big.Int) string {
 	if number == nil {
 		return "latest"
@@ -0,0 +1,222 @@
+package wallet
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/the-web3/eth-wallet/database"
+	"github.com/the-web3/eth-wallet/wallet/ethereum"
+	"github.com/the-web3/eth-wallet/wallet/retry"
+)
+
+// bandRebalance 根据热钱包余额和区间计算调拨方向和金额，余额在区间内时返回 nil
+func bandRebalance(band database.HotWalletBands, balance *big.Int) (uint8, *big.Int) {
+	if balance.Cmp(band.HighWatermark) > 0 && balance.Cmp(band.Target) > 0 {
+		return database.RebalanceHotToCold, new(big.Int).Sub(balance, band.Target)
+	}
+	if balance.Cmp(band.LowWatermark) < 0 && band.Target.Cmp(balance) > 0 {
+		return database.RebalanceColdToHot, new(big.Int).Sub(band.Target, balance)
+	}
+	return 0, nil
+}
+
+// Rebalance 按热钱包区间调拨：高于上限的部分自动转入冷钱包，低于下限时发起冷转热申请，审批通过的申请由冷钱包签名发送
+func (cc *CollectionCold) Rebalance() error {
+	bandList, err := cc.db.HotWalletBands.QueryEnableHotWalletBands()
+	if err != nil {
+		log.Error("query hot wallet bands fail", "err", err)
+		return err
+	}
+	coldWallet, err := cc.db.Addresses.QueryColdWalletInfo()
+	if err != nil {
+		log.Error("query cold wallet info fail", "err", err)
+		return err
+	}
+	if coldWallet == nil {
+		if len(bandList) > 0 {
+			log.Warn("no cold wallet, skip rebalance")
+		}
+		return nil
+	}
+	hotWalletList, err := cc.db.Addresses.QueryHotWalletList()
+	if err != nil {
+		log.Error("query hot wallet list fail", "err", err)
+		return err
+	}
+
+	for _, band := range bandList {
+		for _, hotWallet := range hotWalletList {
+			active, err := cc.db.Rebalances.QueryActiveRebalance(hotWallet.Address, band.TokenAddress)
+			if err != nil {
+				return err
+			}
+			if active != nil {
+				continue
+			}
+			balance := big.NewInt(0)
+			hotBalance, err := cc.db.Balances.QueryWalletBalanceByTokenAndAddress(hotWallet.Address, band.TokenAddress)
+			if err != nil {
+				return err
+			}
+			if hotBalance != nil {
+				balance = hotBalance.Balance
+			}
+			direction, amount := bandRebalance(band, balance)
+			if amount == nil {
+				continue
+			}
+			rebalance := database.Rebalances{
+				GUID:         uuid.New(),
+				Direction:    direction,
+				HotWallet:    hotWallet.Address,
+				TokenAddress: band.TokenAddress,
+				Amount:       amount,
+				Status:       0,
+				Fee:          big.NewInt(0),
+				Timestamp:    uint64(time.Now().Unix()),
+			}
+			if direction == database.RebalanceHotToCold {
+				// 热转冷无需审批，直接签名发送
+				rebalance.Status = 1
+				rebalance.FromAddress = hotWallet.Address
+				rebalance.ToAddress = coldWallet.Address
+				if err := cc.sendRebalance(rebalance, true); err != nil {
+					return err
+				}
+				continue
+			}
+			rebalance.FromAddress = coldWallet.Address
+			rebalance.ToAddress = hotWallet.Address
+			if err := cc.db.Rebalances.StoreRebalance(rebalance); err != nil {
+				log.Error("store cold to hot request fail", "err", err)
+				return err
+			}
+			log.Info("hot wallet below low watermark, cold to hot request created", "hotWallet", hotWallet.Address, "tokenAddress", band.TokenAddress, "amount", amount, "guid", rebalance.GUID)
+		}
+	}
+
+	// 冷钱包离线签名时，审批通过的申请通过导出文件签名后再导入
+	if cc.coldSignOffline {
+		return nil
+	}
+	approvedList, err := cc.db.Rebalances.QueryRebalancesByStatus(1)
+	if err != nil {
+		log.Error("query approved rebalances fail", "err", err)
+		return err
+	}
+	for _, rebalance := range approvedList {
+		if err := cc.sendRebalance(rebalance, false); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func buildRebalanceTx(rebalance *database.Rebalances, chainId uint, nonce uint64) *types.DynamicFeeTx {
+	dFeeTx := &types.DynamicFeeTx{
+		ChainID:   big.NewInt(int64(chainId)),
+		Nonce:     nonce,
+		GasTipCap: maxPriorityFeePerGas,
+		GasFeeCap: maxFeePerGas,
+		Gas:       EthGasLimit,
+		To:        &rebalance.ToAddress,
+		Value:     rebalance.Amount,
+	}
+	if rebalance.TokenAddress != (common.Address{}) {
+		dFeeTx.Gas = TokenGasLimit
+		dFeeTx.To = &rebalance.TokenAddress
+		dFeeTx.Value = big.NewInt(0)
+		dFeeTx.Data = ethereum.BuildErc20Data(rebalance.ToAddress, rebalance.Amount)
+	}
+	return dFeeTx
+}
+
+// sendRebalance 签名调拨交易，先持久化调拨记录和交易记录再广播；isNew 为 true 时同时写入新的热转冷记录并锁定热钱包余额
+func (cc *CollectionCold) sendRebalance(rebalance database.Rebalances, isNew bool) error {
+	nonce, err := cc.hotWallets.NextNonce(rebalance.FromAddress)
+	if errors.Is(err, ErrHotWalletStuck) {
+		return nil
+	}
+	if err != nil {
+		log.Error("query nonce by address fail", "address", rebalance.FromAddress, "err", err)
+		return nil
+	}
+	dFeeTx := buildRebalanceTx(&rebalance, cc.chainConf.ChainID, nonce)
+	// 模拟回滚的交易不广播，避免白白消耗手续费
+	if err := simulateTx(cc.client, rebalance.FromAddress, dFeeTx); err != nil {
+		log.Warn("skip rebalance tx that fails simulation", "from", rebalance.FromAddress, "tokenAddress", rebalance.TokenAddress, "err", err)
+		return nil
+	}
+	rawTx, txHash, err := cc.signer.SignTx(rebalance.FromAddress, dFeeTx, big.NewInt(int64(cc.chainConf.ChainID)))
+	if err != nil {
+		log.Error("offline transaction fail", "err", err)
+		return err
+	}
+	log.Info("Offline sign rebalance tx success", "rawTx", rawTx, "direction", rebalance.Direction, "amount", rebalance.Amount)
+	if err := storeRebalanceSent(cc.resourceCtx, cc.db, rebalance, isNew, common.HexToHash(txHash), rawTx); err != nil {
+		return err
+	}
+
+	// 已持久化，广播失败由重新广播任务继续发送，nonce 被占用时重置调拨并释放锁定余额
+	if err := cc.client.SendRawTransaction(rawTx); err != nil {
+		log.Error("send rebalance tx fail", "from", rebalance.FromAddress, "err", err)
+	}
+	return nil
+}
+
+// storeRebalanceSent 记录已签名的调拨交易，热转冷同时锁定热钱包余额
+func storeRebalanceSent(ctx context.Context, db *database.DB, rebalance database.Rebalances, isNew bool, txHash common.Hash, rawTx string) error {
+	txType := uint8(3)
+	if rebalance.Direction == database.RebalanceColdToHot {
+		txType = 4
+	}
+	rebalanceTx := database.Transactions{
+		GUID:             uuid.New(),
+		BlockHash:        common.Hash{},
+		BlockNumber:      big.NewInt(1),
+		Hash:             txHash,
+		FromAddress:      rebalance.FromAddress,
+		ToAddress:        rebalance.ToAddress,
+		TokenAddress:     rebalance.TokenAddress,
+		Fee:              big.NewInt(1),
+		Amount:           rebalance.Amount,
+		Status:           0,
+		TxType:           txType,
+		TransactionIndex: big.NewInt(time.Now().Unix()),
+		Timestamp:        uint64(time.Now().Unix()),
+	}
+	retryStrategy := &retry.ExponentialStrategy{Min: 1000, Max: 20_000, MaxJitter: 250}
+	_, err := retry.Do[interface{}](ctx, 10, retryStrategy, func() (interface{}, error) {
+		if err := db.Transaction(func(tx *database.DB) error {
+			if isNew {
+				if err := tx.Rebalances.StoreRebalance(rebalance); err != nil {
+					return err
+				}
+			}
+			if err := tx.Rebalances.MarkRebalanceSent(rebalance.GUID, txHash, rawTx); err != nil {
+				return err
+			}
+			if rebalance.Direction == database.RebalanceHotToCold {
+				lockJournal := database.NewJournal(database.LedgerReasonRebalanceLock, txHash, rebalance.TokenAddress).
+					Transfer(database.AvailableAccount(rebalance.FromAddress, 1), database.LockedAccount(rebalance.FromAddress, 1), rebalance.Amount)
+				if err := tx.LedgerEntries.PostJournal(lockJournal); err != nil {
+					return err
+				}
+			}
+			return tx.Transactions.StoreTransactions([]database.Transactions{rebalanceTx}, 1)
+		}); err != nil {
+			log.Error("unable to persist rebalance", "err", err)
+			return nil, err
+		}
+		return nil, nil
+	})
+	return err
+}
@@ -0,0 +1,31 @@
+package wallet
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/the-web3/eth-wallet/database"
+)
+
+func TestBandRebalance(t *testing.T) {
+	band := database.HotWalletBands{
+		LowWatermark:  big.NewInt(100),
+		Target:        big.NewInt(500),
+		HighWatermark: big.NewInt(1000),
+	}
+
+	direction, amount := bandRebalance(band, big.NewInt(1500))
+	require.Equal(t, database.RebalanceHotToCold, direction)
+	require.Equal(t, big.NewInt(1000), amount)
+
+	direction, amount = bandRebalance(band, big.NewInt(40))
+	require.Equal(t, database.RebalanceColdToHot, direction)
+	require.Equal(t, big.NewInt(460), amount)
+
+	_, amount = bandRebalance(band, big.NewInt(1000))
+	require.Nil(t, amount)
+	_, amount = bandRebalance(band, big.NewInt(100))
+	require.Nil(t, amount)
+}
@@ -0,0 +1,245 @@
+package wallet
+
+import (
+	"errors"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/the-web3/eth-wallet/database"
+	"github.com/the-web3/eth-wallet/wallet/retry"
+)
+
+const rebroadcastInterval = time.Second * 30
+
+// startRebroadcast 启动时立即执行一次，之后定期重新广播已持久化但未上链的交易
+func (w *Withdraw) startRebroadcast() {
+	tickerRebroadcastWorker := time.NewTicker(rebroadcastInterval)
+	w.tasks.Go(func() error {
+		defer tickerRebroadcastWorker.Stop()
+		for {
+			if err := w.rebroadcast(); err != nil {
+				log.Error("rebroadcast withdraw fail", "err", err)
+				return err
+			}
+			select {
+			case <-w.resourceCtx.Done():
+				return nil
+			case <-tickerRebroadcastWorker.C:
+			}
+		}
+	})
+}
+
+func (w *Withdraw) rebroadcast() error {
+	withdrawList, err := w.db.Withdraws.QueryUnconfirmedWithdraws()
+	if err != nil {
+		log.Error("query unconfirmed withdraws fail", "err", err)
+		return err
+	}
+	for _, withdraw := range withdrawList {
+		_, replaced, err := w.rebroadcastTx(withdraw.TxSignHex, withdraw.Hash)
+		if err != nil {
+			log.Warn("rebroadcast withdraw tx fail", "hash", withdraw.Hash, "err", err)
+			continue
+		}
+		if !replaced {
+			continue
+		}
+		log.Warn("withdraw tx replaced, reset to unsent", "guid", withdraw.GUID, "hash", withdraw.Hash)
+		if err := w.persistReplaced(func(tx *database.DB) error {
+			if err := tx.Withdraws.ResetReplacedWithdraw(withdraw.GUID); err != nil {
+				return err
+			}
+			if err := tx.WithdrawEvents.StoreWithdrawEvents([]database.WithdrawEvents{
+				database.NewWithdrawEvent(withdraw.GUID, database.WithdrawEventReplaced, 1, 0, withdraw.Hash.String()),
+			}); err != nil {
+				return err
+			}
+			return tx.LedgerEntries.SettleLocks(withdraw.Hash, database.LedgerReasonWithdrawLock, database.LedgerReasonWithdrawRelease, nil, nil)
+		}); err != nil {
+			return err
+		}
+	}
+
+	if err := w.cancelWithdraws(); err != nil {
+		return err
+	}
+
+	batchList, err := w.db.WithdrawBatches.QueryUnconfirmedWithdrawBatches()
+	if err != nil {
+		log.Error("query unconfirmed withdraw batches fail", "err", err)
+		return err
+	}
+	for _, batch := range batchList {
+		_, replaced, err := w.rebroadcastTx(batch.TxSignHex, batch.Hash)
+		if err != nil {
+			log.Warn("rebroadcast withdraw batch tx fail", "hash", batch.Hash, "err", err)
+			continue
+		}
+		if !replaced {
+			continue
+		}
+		log.Warn("withdraw batch tx replaced, reset withdraws to unsent", "guid", batch.GUID, "hash", batch.Hash)
+		if err := w.persistReplaced(func(tx *database.DB) error {
+			if err := tx.WithdrawBatches.MarkWithdrawBatchReplaced(batch.GUID); err != nil {
+				return err
+			}
+			if err := tx.Withdraws.ResetReplacedBatchWithdraws(batch.GUID); err != nil {
+				return err
+			}
+			return tx.LedgerEntries.SettleLocks(batch.Hash, database.LedgerReasonWithdrawLock, database.LedgerReasonWithdrawRelease, nil, nil)
+		}); err != nil {
+			return err
+		}
+	}
+
+	rebalanceList, err := w.db.Rebalances.QueryUnconfirmedRebalances()
+	if err != nil {
+		log.Error("query unconfirmed rebalances fail", "err", err)
+		return err
+	}
+	for _, rebalance := range rebalanceList {
+		_, replaced, err := w.rebroadcastTx(rebalance.TxSignHex, rebalance.Hash)
+		if err != nil {
+			log.Warn("rebroadcast rebalance tx fail", "hash", rebalance.Hash, "err", err)
+			continue
+		}
+		if !replaced {
+			continue
+		}
+		log.Warn("rebalance tx replaced, reset rebalance", "guid", rebalance.GUID, "direction", rebalance.Direction, "hash", rebalance.Hash)
+		if err := w.persistReplaced(func(tx *database.DB) error {
+			if err := tx.Rebalances.ResetReplacedRebalance(rebalance); err != nil {
+				return err
+			}
+			if rebalance.Direction != database.RebalanceHotToCold {
+				return nil
+			}
+			return tx.LedgerEntries.SettleLocks(rebalance.Hash, database.LedgerReasonRebalanceLock, database.LedgerReasonRebalanceRelease, nil, nil)
+		}); err != nil {
+			return err
+		}
+	}
+
+	collectionList, err := w.db.Transactions.QueryUnconfirmedCollections()
+	if err != nil {
+		log.Error("query unconfirmed collections fail", "err", err)
+		return err
+	}
+	for _, collection := range collectionList {
+		_, replaced, err := w.rebroadcastTx(collection.TxSignHex, collection.Hash)
+		if err != nil {
+			log.Warn("rebroadcast collection tx fail", "hash", collection.Hash, "err", err)
+			continue
+		}
+		if !replaced {
+			continue
+		}
+		log.Warn("collection tx replaced, release locked balance", "guid", collection.GUID, "hash", collection.Hash)
+		if err := w.persistReplaced(func(tx *database.DB) error {
+			marked, err := tx.Transactions.MarkCollectionReplaced(collection.Hash)
+			if err != nil || !marked {
+				return err
+			}
+			return settleCollectionLocks(tx, collection.Hash, false, collection.ToAddress, nil)
+		}); err != nil {
+			return err
+		}
+	}
+
+	sweepList, err := w.db.ForwarderSweeps.QueryUnconfirmedForwarderSweeps()
+	if err != nil {
+		log.Error("query unconfirmed forwarder sweeps fail", "err", err)
+		return err
+	}
+	for _, sweep := range sweepList {
+		_, replaced, err := w.rebroadcastTx(sweep.TxSignHex, sweep.Hash)
+		if err != nil {
+			log.Warn("rebroadcast forwarder sweep tx fail", "hash", sweep.Hash, "err", err)
+			continue
+		}
+		if !replaced {
+			continue
+		}
+		log.Warn("forwarder sweep tx replaced, release locked balances", "hash", sweep.Hash)
+		if err := w.persistReplaced(func(tx *database.DB) error {
+			if err := tx.ForwarderSweeps.MarkForwarderSweepsReplaced(sweep.Hash); err != nil {
+				return err
+			}
+			return settleCollectionLocks(tx, sweep.Hash, false, sweep.HotWallet, nil)
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// rebroadcastTx 未上链的交易重新广播；返回 replaced 为 true 表示该交易的 nonce 已被其它交易占用，永远不会上链
+func (w *Withdraw) rebroadcastTx(rawTx string, hash common.Hash) (common.Address, bool, error) {
+	signedTx, from, err := decodeSignedTx(rawTx)
+	if err != nil {
+		return common.Address{}, false, err
+	}
+
+	mined, err := w.txMined(hash)
+	if err != nil || mined {
+		return from, false, err
+	}
+	latestNonce, err := w.client.TxCountByAddress(from)
+	if err != nil {
+		return from, false, err
+	}
+	if uint64(latestNonce) > signedTx.Nonce() {
+		// nonce 已被使用，再确认一次本交易没有在两次查询之间上链
+		mined, err := w.txMined(hash)
+		if err != nil || mined {
+			return from, false, err
+		}
+		return from, true, nil
+	}
+	return from, false, w.client.SendRawTransaction(rawTx)
+}
+
+func decodeSignedTx(rawTx string) (*types.Transaction, common.Address, error) {
+	signedTx := new(types.Transaction)
+	rawTxBytes, err := hexutil.Decode(rawTx)
+	if err != nil {
+		return nil, common.Address{}, err
+	}
+	if err := signedTx.UnmarshalBinary(rawTxBytes); err != nil {
+		return nil, common.Address{}, err
+	}
+	from, err := types.Sender(types.LatestSignerForChainID(signedTx.ChainId()), signedTx)
+	if err != nil {
+		return nil, common.Address{}, err
+	}
+	return signedTx, from, nil
+}
+
+func (w *Withdraw) txMined(hash common.Hash) (bool, error) {
+	_, err := w.client.TxReceiptByHash(hash)
+	if err == nil {
+		return true, nil
+	}
+	if errors.Is(err, ethereum.NotFound) {
+		return false, nil
+	}
+	return false, err
+}
+
+func (w *Withdraw) persistReplaced(fn func(tx *database.DB) error) error {
+	retryStrategy := &retry.ExponentialStrategy{Min: 1000, Max: 20_000, MaxJitter: 250}
+	_, err := retry.Do[interface{}](w.resourceCtx, 10, retryStrategy, func() (interface{}, error) {
+		if err := w.db.Transaction(fn); err != nil {
+			log.Error("unable to persist replaced withdraw", "err", err)
+			return nil, err
+		}
+		return nil, nil
+	})
+	return err
+}
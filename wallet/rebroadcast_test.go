@@ -0,0 +1,103 @@
+package wallet
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	ethwallet "github.com/the-web3/eth-wallet/wallet/ethereum"
+	"github.com/the-web3/eth-wallet/wallet/node"
+)
+
+type fakeRebroadcastClient struct {
+	node.EthClient
+	mined       bool
+	latestNonce uint64
+	sent        []string
+}
+
+func (c *fakeRebroadcastClient) TxReceiptByHash(common.Hash) (*types.Receipt, error) {
+	if c.mined {
+		return &types.Receipt{}, nil
+	}
+	return nil, ethereum.NotFound
+}
+
+func (c *fakeRebroadcastClient) TxCountByAddress(common.Address) (hexutil.Uint64, error) {
+	return hexutil.Uint64(c.latestNonce), nil
+}
+
+func (c *fakeRebroadcastClient) SendRawTransaction(rawTx string) error {
+	c.sent = append(c.sent, rawTx)
+	return nil
+}
+
+func TestRebroadcastTx(t *testing.T) {
+	privateKey := "0cbb2ff952da876c4779200c83f6b90d73ea85a8da82e06c2276a11499922720"
+	toAddress := common.HexToAddress("0x35096AD62E57e86032a3Bb35aDaCF2240d55421D")
+	rawTx, txHash, err := ethwallet.OfflineSignTx(&types.DynamicFeeTx{
+		ChainID:   big.NewInt(1),
+		Nonce:     5,
+		GasTipCap: maxPriorityFeePerGas,
+		GasFeeCap: maxFeePerGas,
+		Gas:       EthGasLimit,
+		To:        &toAddress,
+		Value:     big.NewInt(1000),
+	}, privateKey, big.NewInt(1))
+	require.NoError(t, err)
+	key, err := crypto.HexToECDSA(privateKey)
+	require.NoError(t, err)
+	sender := crypto.PubkeyToAddress(key.PublicKey)
+
+	// nonce 未被使用：重新广播
+	client := &fakeRebroadcastClient{latestNonce: 5}
+	w := &Withdraw{client: client}
+	from, replaced, err := w.rebroadcastTx(rawTx, common.HexToHash(txHash))
+	require.NoError(t, err)
+	require.Equal(t, sender, from)
+	require.False(t, replaced)
+	require.Equal(t, []string{rawTx}, client.sent)
+
+	// 已上链：不广播也不重置
+	client = &fakeRebroadcastClient{mined: true, latestNonce: 6}
+	w.client = client
+	_, replaced, err = w.rebroadcastTx(rawTx, common.HexToHash(txHash))
+	require.NoError(t, err)
+	require.False(t, replaced)
+	require.Empty(t, client.sent)
+
+	// nonce 已被其它交易占用：交易作废
+	client = &fakeRebroadcastClient{latestNonce: 6}
+	w.client = client
+	_, replaced, err = w.rebroadcastTx(rawTx, common.HexToHash(txHash))
+	require.NoError(t, err)
+	require.True(t, replaced)
+	require.Empty(t, client.sent)
+}
+
+func TestBuildCancelTx(t *testing.T) {
+	toAddress := common.HexToAddress("0x35096AD62E57e86032a3Bb35aDaCF2240d55421D")
+	from := common.HexToAddress("0x01")
+	originTx := types.NewTx(&types.DynamicFeeTx{
+		ChainID:   big.NewInt(1),
+		Nonce:     7,
+		GasTipCap: big.NewInt(100),
+		GasFeeCap: big.NewInt(1000),
+		Gas:       TokenGasLimit,
+		To:        &toAddress,
+		Value:     big.NewInt(1000),
+	})
+	cancelTx := buildCancelTx(originTx, from)
+	require.Equal(t, uint64(7), cancelTx.Nonce)
+	require.Equal(t, &from, cancelTx.To)
+	require.Equal(t, int64(0), cancelTx.Value.Int64())
+	require.Equal(t, big.NewInt(111), cancelTx.GasTipCap)
+	require.Equal(t, big.NewInt(1101), cancelTx.GasFeeCap)
+}
@@ -0,0 +1,235 @@
+package wallet
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/the-web3/eth-wallet/common/tasks"
+	"github.com/the-web3/eth-wallet/config"
+	"github.com/the-web3/eth-wallet/database"
+	"github.com/the-web3/eth-wallet/wallet/node"
+)
+
+var ErrReconcileAlert = errors.New("balance reconciliation found discrepancies above tolerance")
+
+// Reconciler 按固定区块高度比对链上余额与数据库余额，差异写入对账报告
+type Reconciler struct {
+	db             *database.DB
+	client         node.EthClient
+	conf           *config.ReconcileConfig
+	resourceCtx    context.Context
+	resourceCancel context.CancelFunc
+	tasks          tasks.Group
+}
+
+func NewReconciler(cfg *config.Config, db *database.DB, client node.EthClient, shutdown context.CancelCauseFunc) (*Reconciler, error) {
+	resCtx, resCancel := context.WithCancel(context.Background())
+	return &Reconciler{
+		db:             db,
+		client:         client,
+		conf:           &cfg.Reconcile,
+		resourceCtx:    resCtx,
+		resourceCancel: resCancel,
+		tasks: tasks.Group{HandleCrit: func(err error) {
+			shutdown(fmt.Errorf("critical error in reconcile: %w", err))
+		}},
+	}, nil
+}
+
+func (r *Reconciler) Close() error {
+	r.resourceCancel()
+	if err := r.tasks.Wait(); err != nil {
+		return fmt.Errorf("failed to await reconcile: %w", err)
+	}
+	return nil
+}
+
+func (r *Reconciler) Start() error {
+	if r.conf.Interval == 0 {
+		log.Info("reconcile worker disabled")
+		return nil
+	}
+	log.Info("start reconcile......", "interval", r.conf.Interval)
+	tickerReconcileWorker := time.NewTicker(r.conf.Interval)
+	r.tasks.Go(func() error {
+		for {
+			select {
+			case <-r.resourceCtx.Done():
+				tickerReconcileWorker.Stop()
+				return nil
+			case <-tickerReconcileWorker.C:
+				// 对账失败只影响本轮，下一轮重新对账
+				if _, err := r.Reconcile(); err != nil {
+					log.Error("reconcile fail", "err", err)
+				}
+			}
+		}
+	})
+	return nil
+}
+
+// Reconcile 以扫块已处理的最新区块为基准，批量查询全部地址和币种在该区块的链上余额，与同一区块的余额快照比对
+func (r *Reconciler) Reconcile() (*database.Reconciliations, error) {
+	blockNumber, err := r.pinBlock()
+	if err != nil {
+		log.Error("pin reconcile block fail", "err", err)
+		return nil, err
+	}
+	if blockNumber == nil {
+		log.Warn("no synced block, skip reconcile")
+		return nil, nil
+	}
+	reconciliation := database.Reconciliations{
+		GUID:        uuid.New(),
+		BlockNumber: blockNumber,
+		Timestamp:   uint64(time.Now().Unix()),
+	}
+
+	var discrepancyList []database.ReconciliationDiscrepancies
+	afterGuid := ""
+	for {
+		balanceList, err := r.db.Balances.QueryBalancesAfterGuid(afterGuid, int(r.conf.BatchSize))
+		if err != nil {
+			log.Error("query balances fail", "err", err)
+			return nil, err
+		}
+		if len(balanceList) == 0 {
+			break
+		}
+		afterGuid = balanceList[len(balanceList)-1].GUID.String()
+		snapshotList, err := r.balancesAtBlock(balanceList, blockNumber)
+		if err != nil {
+			return nil, err
+		}
+		mismatchList, err := r.compareBalances(snapshotList, blockNumber)
+		if err != nil {
+			return nil, err
+		}
+		reconciliation.Checked += uint64(len(snapshotList))
+		for _, discrepancy := range mismatchList {
+			discrepancy.ReconciliationGuid = reconciliation.GUID
+			reconciliation.Mismatched++
+			if discrepancy.Alert {
+				reconciliation.Alerted++
+				log.Error("balance reconciliation alert", "address", discrepancy.Address, "tokenAddress", discrepancy.TokenAddress, "blockNumber", discrepancy.BlockNumber,
+					"chainBalance", discrepancy.ChainBalance, "balance", discrepancy.Balance, "lockBalance", discrepancy.LockBalance, "difference", discrepancy.Difference(), "tolerance", discrepancy.Tolerance)
+			} else {
+				log.Warn("balance differs from chain within tolerance", "address", discrepancy.Address, "tokenAddress", discrepancy.TokenAddress, "difference", discrepancy.Difference())
+			}
+			discrepancyList = append(discrepancyList, discrepancy)
+		}
+	}
+
+	if err := r.db.Reconciliations.StoreReconciliation(reconciliation, discrepancyList); err != nil {
+		log.Error("store reconciliation fail", "err", err)
+		return nil, err
+	}
+	log.Info("reconcile finished", "blockNumber", reconciliation.BlockNumber, "checked", reconciliation.Checked, "mismatched", reconciliation.Mismatched, "alerted", reconciliation.Alerted)
+	return &reconciliation, nil
+}
+
+// compareBalances 批量查询链上余额，返回不一致的记录
+func (r *Reconciler) compareBalances(balanceList []database.Balances, blockNumber *big.Int) ([]database.ReconciliationDiscrepancies, error) {
+	queries := make([]node.BalanceQuery, len(balanceList))
+	for i, balance := range balanceList {
+		queries[i] = node.BalanceQuery{Address: balance.Address, TokenAddress: balance.TokenAddress}
+	}
+	chainBalances, err := r.client.BalancesAtBlock(queries, blockNumber)
+	if err != nil {
+		log.Error("query chain balances fail", "blockNumber", blockNumber, "err", err)
+		return nil, err
+	}
+	var mismatchList []database.ReconciliationDiscrepancies
+	for i, balance := range balanceList {
+		discrepancy := reconcileBalance(balance, chainBalances[i], blockNumber, r.tolerance(balance.TokenAddress))
+		if discrepancy != nil {
+			mismatchList = append(mismatchList, *discrepancy)
+		}
+	}
+	return mismatchList, nil
+}
+
+// pinBlock 在同一事务快照中读取扫块最新区块并按该区块全量补录余额快照，已有的批次快照不覆盖，扫块随后处理的新区块不影响本轮对账
+func (r *Reconciler) pinBlock() (*big.Int, error) {
+	var blockNumber *big.Int
+	err := r.db.SnapshotTransaction(func(tx *database.DB) error {
+		latestBlock, err := tx.Blocks.LatestBlocks()
+		if err != nil || latestBlock == nil {
+			return err
+		}
+		if _, err := tx.BalanceSnapshots.SnapshotLatestBalances(); err != nil {
+			return err
+		}
+		blockNumber = latestBlock.Number
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return blockNumber, nil
+}
+
+// balancesAtBlock 取每个账户不晚于 blockNumber 的最后一条快照，没有快照的账户在该区块之后才创建，留到下一轮对账
+func (r *Reconciler) balancesAtBlock(balanceList []database.Balances, blockNumber *big.Int) ([]database.Balances, error) {
+	addressList := make([]common.Address, len(balanceList))
+	for i, balance := range balanceList {
+		addressList[i] = balance.Address
+	}
+	snapshotList, err := r.db.BalanceSnapshots.QueryBalancesAtBlock(addressList, blockNumber)
+	if err != nil {
+		log.Error("query balance snapshots fail", "blockNumber", blockNumber, "err", err)
+		return nil, err
+	}
+	snapshots := make(map[[2]common.Address]database.BalanceSnapshots, len(snapshotList))
+	for _, snapshot := range snapshotList {
+		snapshots[[2]common.Address{snapshot.Address, snapshot.TokenAddress}] = snapshot
+	}
+	var pinnedList []database.Balances
+	for _, balance := range balanceList {
+		snapshot, ok := snapshots[[2]common.Address{balance.Address, balance.TokenAddress}]
+		if !ok {
+			continue
+		}
+		balance.Balance = snapshot.Balance
+		balance.LockBalance = snapshot.LockBalance
+		pinnedList = append(pinnedList, balance)
+	}
+	return pinnedList, nil
+}
+
+func (r *Reconciler) tolerance(tokenAddress common.Address) *big.Int {
+	if tolerance, ok := r.conf.TokenTolerances[tokenAddress]; ok {
+		return tolerance
+	}
+	return r.conf.Tolerance
+}
+
+// reconcileBalance 链上余额应等于可用余额加在途锁定金额，一致时返回 nil，差额绝对值超过容差时标记告警
+func reconcileBalance(balance database.Balances, chainBalance, blockNumber, tolerance *big.Int) *database.ReconciliationDiscrepancies {
+	discrepancy := database.ReconciliationDiscrepancies{
+		GUID:         uuid.New(),
+		Address:      balance.Address,
+		AddressType:  balance.AddressType,
+		TokenAddress: balance.TokenAddress,
+		BlockNumber:  blockNumber,
+		ChainBalance: chainBalance,
+		Balance:      balance.Balance,
+		LockBalance:  balance.LockBalance,
+		Tolerance:    tolerance,
+		Timestamp:    uint64(time.Now().Unix()),
+	}
+	difference := discrepancy.Difference()
+	if difference.Sign() == 0 {
+		return nil
+	}
+	discrepancy.Alert = difference.CmpAbs(tolerance) > 0
+	return &discrepancy
+}
@@ -0,0 +1,80 @@
+package wallet
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/the-web3/eth-wallet/database"
+)
+
+func TestReconcileBalance(t *testing.T) {
+	balance := database.Balances{
+		Address:      common.HexToAddress("0x35096AD62E57e86032a3Bb35aDaCF2240d55421D"),
+		TokenAddress: common.Address{},
+		Balance:      big.NewInt(900),
+		LockBalance:  big.NewInt(100),
+	}
+	blockNumber := big.NewInt(10)
+
+	// 锁定金额属于尚未上链的交易，仍在链上余额中
+	require.Nil(t, reconcileBalance(balance, big.NewInt(1000), blockNumber, big.NewInt(0)))
+
+	discrepancy := reconcileBalance(balance, big.NewInt(995), blockNumber, big.NewInt(5))
+	require.NotNil(t, discrepancy)
+	require.Equal(t, big.NewInt(-5), discrepancy.Difference())
+	require.False(t, discrepancy.Alert)
+
+	discrepancy = reconcileBalance(balance, big.NewInt(1006), blockNumber, big.NewInt(5))
+	require.NotNil(t, discrepancy)
+	require.Equal(t, big.NewInt(6), discrepancy.Difference())
+	require.True(t, discrepancy.Alert)
+}
+
+// 对账和定期快照按最新区块补录，不覆盖扫块批次在该区块写入的快照
+func TestLatestSnapshotKeepsBatchSnapshot(t *testing.T) {
+	db := newStressDB(t)
+	_, address := newStressKey(t)
+	credit := func(amount int64) {
+		journal := database.NewJournal(database.LedgerReasonDeposit, common.BigToHash(big.NewInt(time.Now().UnixNano())), common.Address{})
+		journal.Transfer(database.LedgerExternalAccount, database.AvailableAccount(address, 0), big.NewInt(amount))
+		require.NoError(t, db.Transaction(func(tx *database.DB) error {
+			return tx.LedgerEntries.PostJournal(journal)
+		}))
+	}
+	require.NoError(t, db.Balances.StoreBalances([]database.Balances{{
+		GUID: uuid.New(), Address: address, Balance: big.NewInt(0), LockBalance: big.NewInt(0), Timestamp: uint64(time.Now().Unix()),
+	}}, 1))
+
+	// 区块号取当前纳秒时间，保证是共享测试库中的最新区块
+	header := &types.Header{Number: big.NewInt(time.Now().UnixNano()), Time: 1700000000, ParentHash: common.BytesToHash(address.Bytes())}
+	require.NoError(t, db.Blocks.StoreBlockss([]database.Blocks{database.BlockHeaderFromHeader(header)}, 1))
+
+	credit(100)
+	_, err := db.BalanceSnapshots.SnapshotBalances(0, header.Number, header.Time)
+	require.NoError(t, err)
+	credit(50)
+	_, err = db.BalanceSnapshots.SnapshotLatestBalances()
+	require.NoError(t, err)
+
+	snapshotList, err := db.BalanceSnapshots.QueryBalancesAtBlock([]common.Address{address}, header.Number)
+	require.NoError(t, err)
+	require.Len(t, snapshotList, 1)
+	require.Equal(t, big.NewInt(100), snapshotList[0].Balance)
+	require.Equal(t, header.Time, snapshotList[0].BlockTimestamp)
+
+	// 下一批次按自己的最后区块记录
+	next := new(big.Int).Add(header.Number, big.NewInt(1))
+	_, err = db.BalanceSnapshots.SnapshotBalances(0, next, header.Time+12)
+	require.NoError(t, err)
+	snapshotList, err = db.BalanceSnapshots.QueryBalancesAtBlock([]common.Address{address}, next)
+	require.NoError(t, err)
+	require.Len(t, snapshotList, 1)
+	require.Equal(t, big.NewInt(150), snapshotList[0].Balance)
+}
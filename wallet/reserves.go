@@ -0,0 +1,217 @@
+package wallet
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/the-web3/eth-wallet/database"
+	"github.com/the-web3/eth-wallet/wallet/merklesum"
+	"github.com/the-web3/eth-wallet/wallet/node"
+)
+
+var ErrNoSyncedBlock = errors.New("no synced block")
+
+// ReserveReporter 生成储备证明：按币种对用户负债构建默克尔求和树，并与同一区块的链上持仓比较
+type ReserveReporter struct {
+	db        *database.DB
+	client    node.EthClient
+	batchSize int
+}
+
+func NewReserveReporter(db *database.DB, client node.EthClient, batchSize uint) *ReserveReporter {
+	return &ReserveReporter{
+		db:        db,
+		client:    client,
+		batchSize: int(batchSize),
+	}
+}
+
+// reserveLeaf 一个用户在某一币种上的负债叶子
+type reserveLeaf struct {
+	userUid string
+	salt    common.Hash
+	balance *big.Int
+}
+
+// Generate 以扫块已处理的最新区块为基准生成全部币种的储备证明并保存用户证明
+func (r *ReserveReporter) Generate() ([]database.ReserveReports, error) {
+	latestBlock, err := r.db.Blocks.LatestBlocks()
+	if err != nil {
+		log.Error("query latest block fail", "err", err)
+		return nil, err
+	}
+	if latestBlock == nil {
+		return nil, ErrNoSyncedBlock
+	}
+	liabilityList, err := r.db.Reserves.QueryUserLiabilities(latestBlock.Number)
+	if err != nil {
+		log.Error("query user liabilities fail", "err", err)
+		return nil, err
+	}
+	var tokenList []common.Address
+	leaves := make(map[common.Address][]reserveLeaf)
+	for _, liability := range liabilityList {
+		amount, ok := new(big.Int).SetString(liability.Amount, 10)
+		if !ok {
+			return nil, errors.New("invalid liability amount " + liability.Amount)
+		}
+		// 提现多于充值（如业务方内部划转）时不能以负数抵扣其他用户的负债
+		if amount.Sign() < 0 {
+			log.Warn("negative user liability counted as zero", "userUid", liability.UserUid, "tokenAddress", liability.TokenAddress, "amount", amount)
+			amount = big.NewInt(0)
+		}
+		salt, err := randomSalt()
+		if err != nil {
+			return nil, err
+		}
+		tokenAddress := common.HexToAddress(liability.TokenAddress)
+		if _, ok := leaves[tokenAddress]; !ok {
+			tokenList = append(tokenList, tokenAddress)
+		}
+		leaves[tokenAddress] = append(leaves[tokenAddress], reserveLeaf{userUid: liability.UserUid, salt: salt, balance: amount})
+	}
+
+	holdings, err := r.onchainHoldings(tokenList, latestBlock.Number)
+	if err != nil {
+		return nil, err
+	}
+
+	var reportList []database.ReserveReports
+	for _, tokenAddress := range tokenList {
+		report, proofList, err := buildReserveReport(tokenAddress, leaves[tokenAddress], latestBlock.Number, holdings[tokenAddress])
+		if err != nil {
+			return nil, err
+		}
+		if err := r.db.Reserves.StoreReserveReport(*report, proofList); err != nil {
+			log.Error("store reserve report fail", "tokenAddress", tokenAddress, "err", err)
+			return nil, err
+		}
+		if report.OnchainHoldings.Cmp(report.TotalLiabilities) < 0 {
+			log.Error("on-chain holdings below user liabilities", "tokenAddress", tokenAddress, "liabilities", report.TotalLiabilities, "holdings", report.OnchainHoldings)
+		}
+		log.Info("reserve report generated", "tokenAddress", tokenAddress, "root", report.Root, "liabilities", report.TotalLiabilities, "holdings", report.OnchainHoldings, "users", report.UserCount)
+		reportList = append(reportList, *report)
+	}
+	return reportList, nil
+}
+
+// onchainHoldings 批量查询热钱包、冷钱包和用户地址在 blockNumber 的链上余额并按币种汇总
+func (r *ReserveReporter) onchainHoldings(tokenList []common.Address, blockNumber *big.Int) (map[common.Address]*big.Int, error) {
+	holdings := make(map[common.Address]*big.Int)
+	for _, tokenAddress := range tokenList {
+		holdings[tokenAddress] = big.NewInt(0)
+	}
+	seen := make(map[node.BalanceQuery]bool)
+	var queries []node.BalanceQuery
+	addQuery := func(query node.BalanceQuery) {
+		if _, ok := holdings[query.TokenAddress]; ok && !seen[query] {
+			seen[query] = true
+			queries = append(queries, query)
+		}
+	}
+
+	// 热钱包和冷钱包即使没有余额记录也计入
+	walletList, err := r.db.Addresses.QueryHotWalletList()
+	if err != nil {
+		return nil, err
+	}
+	coldWallet, err := r.db.Addresses.QueryColdWalletInfo()
+	if err != nil {
+		return nil, err
+	}
+	if coldWallet != nil {
+		walletList = append(walletList, *coldWallet)
+	}
+	for _, walletAddress := range walletList {
+		for _, tokenAddress := range tokenList {
+			addQuery(node.BalanceQuery{Address: walletAddress.Address, TokenAddress: tokenAddress})
+		}
+	}
+	afterGuid := ""
+	for {
+		balanceList, err := r.db.Balances.QueryBalancesAfterGuid(afterGuid, r.batchSize)
+		if err != nil {
+			return nil, err
+		}
+		if len(balanceList) == 0 {
+			break
+		}
+		afterGuid = balanceList[len(balanceList)-1].GUID.String()
+		for _, balance := range balanceList {
+			addQuery(node.BalanceQuery{Address: balance.Address, TokenAddress: balance.TokenAddress})
+		}
+	}
+
+	for start := 0; start < len(queries); start += r.batchSize {
+		end := min(start+r.batchSize, len(queries))
+		balanceList, err := r.client.BalancesAtBlock(queries[start:end], blockNumber)
+		if err != nil {
+			log.Error("query chain balances fail", "blockNumber", blockNumber, "err", err)
+			return nil, err
+		}
+		for i, balance := range balanceList {
+			holding := holdings[queries[start+i].TokenAddress]
+			holding.Add(holding, balance)
+		}
+	}
+	return holdings, nil
+}
+
+// buildReserveReport 构建一个币种的默克尔求和树，生成报告和每个用户的证明
+func buildReserveReport(tokenAddress common.Address, leafList []reserveLeaf, blockNumber, holdings *big.Int) (*database.ReserveReports, []database.ReserveProofs, error) {
+	nodes := make([]merklesum.Node, len(leafList))
+	for i, leaf := range leafList {
+		nodes[i] = merklesum.Node{Hash: merklesum.LeafHash(leaf.salt, leaf.userUid, leaf.balance), Sum: leaf.balance}
+	}
+	tree := merklesum.NewTree(nodes)
+	root := tree.Root()
+	report := &database.ReserveReports{
+		GUID:             uuid.New(),
+		TokenAddress:     tokenAddress,
+		BlockNumber:      blockNumber,
+		Root:             root.Hash,
+		TotalLiabilities: root.Sum,
+		OnchainHoldings:  holdings,
+		UserCount:        uint64(len(leafList)),
+		Timestamp:        uint64(time.Now().Unix()),
+	}
+	proofList := make([]database.ReserveProofs, len(leafList))
+	for i, leaf := range leafList {
+		path, err := tree.Proof(i)
+		if err != nil {
+			return nil, nil, err
+		}
+		content, err := json.Marshal(path)
+		if err != nil {
+			return nil, nil, err
+		}
+		proofList[i] = database.ReserveProofs{
+			GUID:         uuid.New(),
+			ReportGuid:   report.GUID,
+			UserUid:      leaf.userUid,
+			TokenAddress: tokenAddress,
+			LeafIndex:    uint64(i),
+			Salt:         leaf.salt,
+			Balance:      leaf.balance,
+			Proof:        string(content),
+			Timestamp:    report.Timestamp,
+		}
+	}
+	return report, proofList, nil
+}
+
+func randomSalt() (common.Hash, error) {
+	var salt common.Hash
+	if _, err := rand.Read(salt[:]); err != nil {
+		return common.Hash{}, err
+	}
+	return salt, nil
+}
@@ -0,0 +1,35 @@
+package wallet
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/the-web3/eth-wallet/wallet/merklesum"
+)
+
+func TestBuildReserveReport(t *testing.T) {
+	var leafList []reserveLeaf
+	for i, userUid := range []string{"alice", "bob", "carol"} {
+		salt, err := randomSalt()
+		require.NoError(t, err)
+		leafList = append(leafList, reserveLeaf{userUid: userUid, salt: salt, balance: big.NewInt(int64(10 * (i + 1)))})
+	}
+	report, proofList, err := buildReserveReport(common.Address{}, leafList, big.NewInt(100), big.NewInt(55))
+	require.NoError(t, err)
+	require.Equal(t, big.NewInt(60), report.TotalLiabilities)
+	require.Equal(t, uint64(3), report.UserCount)
+	require.Len(t, proofList, 3)
+
+	root := merklesum.Node{Hash: report.Root, Sum: report.TotalLiabilities}
+	for _, proof := range proofList {
+		var path []merklesum.ProofNode
+		require.NoError(t, json.Unmarshal([]byte(proof.Proof), &path))
+		leaf := merklesum.Node{Hash: merklesum.LeafHash(proof.Salt, proof.UserUid, proof.Balance), Sum: proof.Balance}
+		require.True(t, merklesum.VerifyProof(leaf, path, root))
+	}
+}
@@ -0,0 +1,49 @@
+package signer
+
+import (
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/the-web3/eth-wallet/wallet/ethereum"
+)
+
+// KeystoreSigner 使用 go-ethereum 加密 keystore 文件签名，启动时用同一个密码解锁目录下所有账户
+type KeystoreSigner struct {
+	ks *keystore.KeyStore
+}
+
+func NewKeystoreSigner(keystoreDir, passwordFile string) (*KeystoreSigner, error) {
+	if keystoreDir == "" {
+		return nil, fmt.Errorf("keystore dir is required for keystore signer")
+	}
+	password, err := os.ReadFile(passwordFile)
+	if err != nil {
+		return nil, fmt.Errorf("read keystore password file fail: %w", err)
+	}
+	ks := keystore.NewKeyStore(keystoreDir, keystore.StandardScryptN, keystore.StandardScryptP)
+	return newKeystoreSigner(ks, strings.TrimRight(string(password), "\r\n"))
+}
+
+func newKeystoreSigner(ks *keystore.KeyStore, password string) (*KeystoreSigner, error) {
+	for _, account := range ks.Accounts() {
+		if err := ks.Unlock(account, password); err != nil {
+			return nil, fmt.Errorf("unlock keystore account %s fail: %w", account.Address, err)
+		}
+	}
+	return &KeystoreSigner{ks: ks}, nil
+}
+
+func (s *KeystoreSigner) SignTx(from common.Address, txData *types.DynamicFeeTx, chainId *big.Int) (string, string, error) {
+	signedTx, err := s.ks.SignTx(accounts.Account{Address: from}, types.NewTx(txData), chainId)
+	if err != nil {
+		return "", "", err
+	}
+	return ethereum.EncodeSignedTx(signedTx)
+}
@@ -0,0 +1,68 @@
+package signer
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/the-web3/eth-wallet/database"
+	"github.com/the-web3/eth-wallet/wallet/ethereum"
+	"github.com/the-web3/eth-wallet/wallet/hdwallet"
+	"github.com/the-web3/eth-wallet/wallet/kms"
+)
+
+// LocalSigner 使用 addresses 表中保存的私钥或 HD 种子派生的私钥签名，私钥只在签名时解密或派生
+type LocalSigner struct {
+	db         *database.DB
+	keyManager kms.KMS
+	hd         *hdwallet.HDWallet
+}
+
+func NewLocalSigner(db *database.DB, keyManager kms.KMS, hd *hdwallet.HDWallet) *LocalSigner {
+	return &LocalSigner{db: db, keyManager: keyManager, hd: hd}
+}
+
+func (s *LocalSigner) SignTx(from common.Address, txData *types.DynamicFeeTx, chainId *big.Int) (string, string, error) {
+	account, err := s.db.Addresses.QueryAddressesByToAddress(&from)
+	if err != nil {
+		return "", "", err
+	}
+	if account == nil {
+		return "", "", fmt.Errorf("no private key for address %s", from)
+	}
+	privateKey := account.PrivateKey
+	if account.DerivationIndex != nil {
+		if s.hd == nil {
+			return "", "", fmt.Errorf("address %s is hd derived but no mnemonic is configured", from)
+		}
+		derivedKey, err := s.hd.DerivePrivateKey(*account.DerivationIndex)
+		if err != nil {
+			return "", "", err
+		}
+		if crypto.PubkeyToAddress(derivedKey.PublicKey) != from {
+			return "", "", fmt.Errorf("derived address mismatch for %s at index %d", from, *account.DerivationIndex)
+		}
+		privateKey = hex.EncodeToString(crypto.FromECDSA(derivedKey))
+	} else if account.DataKey != "" {
+		if s.keyManager == nil {
+			return "", "", fmt.Errorf("private key of %s is encrypted but no kms master key is configured", from)
+		}
+		privateKey, err = kms.OpenPrivateKey(s.keyManager, account.PrivateKey, account.DataKey, account.KeyId, from)
+		if err != nil {
+			return "", "", err
+		}
+		// 未绑定地址的历史密文可能被挪到其它地址下，解密后核对私钥属于 from
+		openedKey, err := crypto.HexToECDSA(privateKey)
+		if err != nil {
+			return "", "", err
+		}
+		if crypto.PubkeyToAddress(openedKey.PublicKey) != from {
+			return "", "", fmt.Errorf("decrypted private key does not belong to %s", from)
+		}
+	}
+	return ethereum.OfflineSignTx(txData, privateKey, chainId)
+}
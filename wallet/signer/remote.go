@@ -0,0 +1,121 @@
+package signer
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+
+	"github.com/the-web3/eth-wallet/wallet/ethereum"
+)
+
+const (
+	defaultRemoteMethod  = "eth_signTransaction"
+	remoteRequestTimeout = 10 * time.Second
+)
+
+// RemoteSigner 通过 JSON-RPC 调用独立的签名服务（Web3Signer、clef 等）
+type RemoteSigner struct {
+	client *rpc.Client
+	method string
+}
+
+type remoteTxArgs struct {
+	From                 common.Address  `json:"from"`
+	To                   *common.Address `json:"to,omitempty"`
+	Gas                  hexutil.Uint64  `json:"gas"`
+	MaxFeePerGas         *hexutil.Big    `json:"maxFeePerGas"`
+	MaxPriorityFeePerGas *hexutil.Big    `json:"maxPriorityFeePerGas"`
+	Value                *hexutil.Big    `json:"value"`
+	Nonce                hexutil.Uint64  `json:"nonce"`
+	Data                 hexutil.Bytes   `json:"data"`
+	ChainID              *hexutil.Big    `json:"chainId"`
+}
+
+func NewRemoteSigner(ctx context.Context, url, method string) (*RemoteSigner, error) {
+	if url == "" {
+		return nil, fmt.Errorf("remote url is required for remote signer")
+	}
+	client, err := rpc.DialContext(ctx, url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to dial remote signer (%s): %w", url, err)
+	}
+	if method == "" {
+		method = defaultRemoteMethod
+	}
+	return &RemoteSigner{client: client, method: method}, nil
+}
+
+func (s *RemoteSigner) SignTx(from common.Address, txData *types.DynamicFeeTx, chainId *big.Int) (string, string, error) {
+	args := remoteTxArgs{
+		From:                 from,
+		To:                   txData.To,
+		Gas:                  hexutil.Uint64(txData.Gas),
+		MaxFeePerGas:         (*hexutil.Big)(txData.GasFeeCap),
+		MaxPriorityFeePerGas: (*hexutil.Big)(txData.GasTipCap),
+		Value:                (*hexutil.Big)(txData.Value),
+		Nonce:                hexutil.Uint64(txData.Nonce),
+		Data:                 txData.Data,
+		ChainID:              (*hexutil.Big)(chainId),
+	}
+	ctxwt, cancel := context.WithTimeout(context.Background(), remoteRequestTimeout)
+	defer cancel()
+	var result json.RawMessage
+	if err := s.client.CallContext(ctxwt, &result, s.method, args); err != nil {
+		return "", "", err
+	}
+	rawTx, err := decodeRemoteResult(result)
+	if err != nil {
+		return "", "", err
+	}
+	signedTx := new(types.Transaction)
+	if err := signedTx.UnmarshalBinary(rawTx); err != nil {
+		return "", "", fmt.Errorf("decode remote signed tx fail: %w", err)
+	}
+	if err := checkSignedTx(signedTx, from, txData, chainId); err != nil {
+		return "", "", err
+	}
+	return ethereum.EncodeSignedTx(signedTx)
+}
+
+// decodeRemoteResult 兼容直接返回 rawTx 字符串（Web3Signer）和返回 {raw, tx} 对象（geth、clef）两种格式
+func decodeRemoteResult(result json.RawMessage) ([]byte, error) {
+	var raw hexutil.Bytes
+	if err := json.Unmarshal(result, &raw); err == nil {
+		return raw, nil
+	}
+	var signResult struct {
+		Raw hexutil.Bytes `json:"raw"`
+	}
+	if err := json.Unmarshal(result, &signResult); err != nil {
+		return nil, fmt.Errorf("unexpected remote signer result: %s", string(result))
+	}
+	return signResult.Raw, nil
+}
+
+// checkSignedTx 校验签名服务返回的交易与请求一致，避免签名服务篡改交易内容
+func checkSignedTx(signedTx *types.Transaction, from common.Address, txData *types.DynamicFeeTx, chainId *big.Int) error {
+	sender, err := types.Sender(types.LatestSignerForChainID(chainId), signedTx)
+	if err != nil {
+		return err
+	}
+	if sender != from {
+		return fmt.Errorf("remote signed tx sender %s mismatch, expect %s", sender, from)
+	}
+	if signedTx.Nonce() != txData.Nonce || signedTx.Gas() != txData.Gas ||
+		signedTx.Value().Cmp(txData.Value) != 0 || !bytes.Equal(signedTx.Data(), txData.Data) ||
+		signedTx.GasFeeCap().Cmp(txData.GasFeeCap) != 0 || signedTx.GasTipCap().Cmp(txData.GasTipCap) != 0 {
+		return fmt.Errorf("remote signed tx does not match request")
+	}
+	if (signedTx.To() == nil) != (txData.To == nil) || (txData.To != nil && *signedTx.To() != *txData.To) {
+		return fmt.Errorf("remote signed tx to address mismatch")
+	}
+	return nil
+}
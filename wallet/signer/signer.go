@@ -0,0 +1,39 @@
+package signer
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/the-web3/eth-wallet/config"
+	"github.com/the-web3/eth-wallet/database"
+	"github.com/the-web3/eth-wallet/wallet/hdwallet"
+	"github.com/the-web3/eth-wallet/wallet/kms"
+)
+
+const (
+	TypeLocal    = "local"
+	TypeKeystore = "keystore"
+	TypeRemote   = "remote"
+)
+
+// Signer 对 from 地址发出的交易签名，返回可直接广播的 rawTx 和交易哈希
+type Signer interface {
+	SignTx(from common.Address, txData *types.DynamicFeeTx, chainId *big.Int) (string, string, error)
+}
+
+func NewSigner(ctx context.Context, cfg *config.SignerConfig, db *database.DB, keyManager kms.KMS, hd *hdwallet.HDWallet) (Signer, error) {
+	switch cfg.Type {
+	case "", TypeLocal:
+		return NewLocalSigner(db, keyManager, hd), nil
+	case TypeKeystore:
+		return NewKeystoreSigner(cfg.KeystoreDir, cfg.KeystorePasswordFile)
+	case TypeRemote:
+		return NewRemoteSigner(ctx, cfg.RemoteUrl, cfg.RemoteMethod)
+	default:
+		return nil, fmt.Errorf("unknown signer type: %s", cfg.Type)
+	}
+}
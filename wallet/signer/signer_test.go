@@ -0,0 +1,113 @@
+package signer
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/the-web3/eth-wallet/wallet/ethereum"
+)
+
+const testPrivateKey = "0cbb2ff952da876c4779200c83f6b90d73ea85a8da82e06c2276a11499922720"
+
+func testTxData() *types.DynamicFeeTx {
+	toAddress := common.HexToAddress("0x35096AD62E57e86032a3Bb35aDaCF2240d55421D")
+	return &types.DynamicFeeTx{
+		ChainID:   big.NewInt(1),
+		Nonce:     58,
+		GasTipCap: big.NewInt(2600000000),
+		GasFeeCap: big.NewInt(2900000000),
+		Gas:       21000,
+		To:        &toAddress,
+		Value:     big.NewInt(1000000000000),
+	}
+}
+
+func TestKeystoreSigner(t *testing.T) {
+	privateKey, err := crypto.HexToECDSA(testPrivateKey)
+	require.NoError(t, err)
+	ks := keystore.NewKeyStore(t.TempDir(), keystore.LightScryptN, keystore.LightScryptP)
+	account, err := ks.ImportECDSA(privateKey, "password")
+	require.NoError(t, err)
+
+	s, err := newKeystoreSigner(ks, "password")
+	require.NoError(t, err)
+	rawTx, txHash, err := s.SignTx(account.Address, testTxData(), big.NewInt(1))
+	require.NoError(t, err)
+
+	expectRawTx, expectTxHash, err := ethereum.OfflineSignTx(testTxData(), testPrivateKey, big.NewInt(1))
+	require.NoError(t, err)
+	require.Equal(t, expectRawTx, rawTx)
+	require.Equal(t, expectTxHash, txHash)
+
+	_, err = newKeystoreSigner(ks, "wrong")
+	require.Error(t, err)
+}
+
+func TestRemoteSigner(t *testing.T) {
+	privateKey, err := crypto.HexToECDSA(testPrivateKey)
+	require.NoError(t, err)
+	from := crypto.PubkeyToAddress(privateKey.PublicKey)
+
+	var tamper bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		var req struct {
+			ID     json.RawMessage `json:"id"`
+			Method string          `json:"method"`
+			Params []remoteTxArgs  `json:"params"`
+		}
+		require.NoError(t, json.Unmarshal(body, &req))
+		require.Equal(t, "account_signTransaction", req.Method)
+		args := req.Params[0]
+		require.Equal(t, from, args.From)
+		txData := &types.DynamicFeeTx{
+			ChainID:   args.ChainID.ToInt(),
+			Nonce:     uint64(args.Nonce),
+			GasTipCap: args.MaxPriorityFeePerGas.ToInt(),
+			GasFeeCap: args.MaxFeePerGas.ToInt(),
+			Gas:       uint64(args.Gas),
+			To:        args.To,
+			Value:     args.Value.ToInt(),
+			Data:      args.Data,
+		}
+		if tamper {
+			txData.Value = big.NewInt(1)
+		}
+		signedTx, err := types.SignTx(types.NewTx(txData), types.LatestSignerForChainID(txData.ChainID), privateKey)
+		require.NoError(t, err)
+		raw, err := signedTx.MarshalBinary()
+		require.NoError(t, err)
+		result, _ := json.Marshal(map[string]interface{}{"raw": hexutil.Bytes(raw)})
+		resp, _ := json.Marshal(map[string]interface{}{"jsonrpc": "2.0", "id": req.ID, "result": json.RawMessage(result)})
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(resp)
+	}))
+	defer server.Close()
+
+	s, err := NewRemoteSigner(context.Background(), server.URL, "account_signTransaction")
+	require.NoError(t, err)
+	rawTx, txHash, err := s.SignTx(from, testTxData(), big.NewInt(1))
+	require.NoError(t, err)
+
+	expectRawTx, expectTxHash, err := ethereum.OfflineSignTx(testTxData(), testPrivateKey, big.NewInt(1))
+	require.NoError(t, err)
+	require.Equal(t, expectRawTx, rawTx)
+	require.Equal(t, expectTxHash, txHash)
+
+	tamper = true
+	_, _, err = s.SignTx(from, testTxData(), big.NewInt(1))
+	require.Error(t, err)
+}
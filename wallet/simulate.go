@@ -0,0 +1,107 @@
+package wallet
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+
+	"github.com/the-web3/eth-wallet/wallet/node"
+)
+
+const (
+	RevertKindPaused                = "paused"
+	RevertKindBlacklisted           = "blacklisted"
+	RevertKindInsufficientBalance   = "insufficient_balance"
+	RevertKindInsufficientAllowance = "insufficient_allowance"
+	RevertKindInsufficientFunds     = "insufficient_funds"
+	RevertKindOutOfGas              = "out_of_gas"
+	RevertKindPanic                 = "panic"
+	RevertKindReverted              = "reverted"
+)
+
+// panicSelector Panic(uint256) 的函数选择器，由 solidity 内置检查（溢出、除零、越界等）触发
+var panicSelector = []byte{0x4e, 0x48, 0x7b, 0x71}
+
+// SimulationError 模拟执行确定会失败的交易，广播只会白白消耗手续费
+type SimulationError struct {
+	Kind   string
+	Reason string
+}
+
+func (e *SimulationError) Error() string {
+	return fmt.Sprintf("simulation %s: %s", e.Kind, e.Reason)
+}
+
+// simulateTx 在 pending 状态上 eth_call 待发送交易，交易会回滚时返回 *SimulationError，节点异常等临时错误原样返回
+func simulateTx(client node.EthClient, from common.Address, txData *types.DynamicFeeTx) error {
+	_, err := client.CallContract(ethereum.CallMsg{
+		From:      from,
+		To:        txData.To,
+		Gas:       txData.Gas,
+		GasFeeCap: txData.GasFeeCap,
+		GasTipCap: txData.GasTipCap,
+		Value:     txData.Value,
+		Data:      txData.Data,
+	})
+	if err == nil {
+		return nil
+	}
+	if simulationErr := toSimulationError(err); simulationErr != nil {
+		return simulationErr
+	}
+	return err
+}
+
+func toSimulationError(err error) *SimulationError {
+	message := err.Error()
+	var dataErr rpc.DataError
+	if errors.As(err, &dataErr) {
+		if data, ok := dataErr.ErrorData().(string); ok {
+			if revertData, decodeErr := hexutil.Decode(data); decodeErr == nil && len(revertData) > 0 {
+				if reason, unpackErr := abi.UnpackRevert(revertData); unpackErr == nil {
+					if bytes.HasPrefix(revertData, panicSelector) {
+						return &SimulationError{Kind: RevertKindPanic, Reason: reason}
+					}
+					return &SimulationError{Kind: classifyRevert(reason), Reason: reason}
+				}
+				return &SimulationError{Kind: RevertKindReverted, Reason: data}
+			}
+		}
+	}
+	lower := strings.ToLower(message)
+	if strings.Contains(lower, "execution reverted") || strings.Contains(lower, "insufficient funds") ||
+		strings.Contains(lower, "gas required exceeds") || strings.Contains(lower, "out of gas") {
+		return &SimulationError{Kind: classifyRevert(message), Reason: message}
+	}
+	return nil
+}
+
+// classifyRevert 按回滚原因归类，便于运营按类型处理被拦截的交易
+func classifyRevert(reason string) string {
+	lower := strings.ToLower(reason)
+	switch {
+	case strings.Contains(lower, "paused"):
+		return RevertKindPaused
+	case strings.Contains(lower, "blacklist"), strings.Contains(lower, "blocklist"),
+		strings.Contains(lower, "blocked"), strings.Contains(lower, "frozen"):
+		return RevertKindBlacklisted
+	case strings.Contains(lower, "allowance"):
+		return RevertKindInsufficientAllowance
+	case strings.Contains(lower, "insufficient funds"):
+		return RevertKindInsufficientFunds
+	case strings.Contains(lower, "exceeds balance"), strings.Contains(lower, "insufficient balance"):
+		return RevertKindInsufficientBalance
+	case strings.Contains(lower, "out of gas"), strings.Contains(lower, "gas required exceeds"):
+		return RevertKindOutOfGas
+	default:
+		return RevertKindReverted
+	}
+}
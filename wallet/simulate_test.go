@@ -0,0 +1,50 @@
+package wallet
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type testDataError struct {
+	message string
+	data    string
+}
+
+func (e *testDataError) Error() string          { return e.message }
+func (e *testDataError) ErrorData() interface{} { return e.data }
+
+func TestToSimulationError(t *testing.T) {
+	// Error("Pausable: paused")
+	pausedErr := &testDataError{
+		message: "execution reverted: Pausable: paused",
+		data:    "0x08c379a0000000000000000000000000000000000000000000000000000000000000002000000000000000000000000000000000000000000000000000000000000000105061757361626c653a2070617573656400000000000000000000000000000000",
+	}
+	simulationErr := toSimulationError(pausedErr)
+	require.NotNil(t, simulationErr)
+	require.Equal(t, RevertKindPaused, simulationErr.Kind)
+	require.Equal(t, "Pausable: paused", simulationErr.Reason)
+
+	// Panic(0x11)
+	panicErr := &testDataError{
+		message: "execution reverted",
+		data:    "0x4e487b710000000000000000000000000000000000000000000000000000000000000011",
+	}
+	simulationErr = toSimulationError(panicErr)
+	require.NotNil(t, simulationErr)
+	require.Equal(t, RevertKindPanic, simulationErr.Kind)
+
+	simulationErr = toSimulationError(errors.New("insufficient funds for gas * price + value"))
+	require.NotNil(t, simulationErr)
+	require.Equal(t, RevertKindInsufficientFunds, simulationErr.Kind)
+
+	require.Nil(t, toSimulationError(errors.New("context deadline exceeded")))
+}
+
+func TestClassifyRevert(t *testing.T) {
+	require.Equal(t, RevertKindBlacklisted, classifyRevert("Blacklistable: account is blacklisted"))
+	require.Equal(t, RevertKindInsufficientBalance, classifyRevert("ERC20: transfer amount exceeds balance"))
+	require.Equal(t, RevertKindInsufficientAllowance, classifyRevert("ERC20: insufficient allowance"))
+	require.Equal(t, RevertKindReverted, classifyRevert("custom failure"))
+}
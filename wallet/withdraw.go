@@ -16,7 +16,9 @@ import (
 	"github.com/the-web3/eth-wallet/common/tasks"
 	"github.com/the-web3/eth-wallet/config"
 	"github.com/the-web3/eth-wallet/database"
+	"github.com/the-web3/eth-wallet/risk"
 	"github.com/the-web3/eth-wallet/wallet/node"
+	"github.com/the-web3/eth-wallet/wallet/signer"
 )
 
 var (
@@ -30,17 +32,23 @@ type Withdraw struct {
 	db             *database.DB
 	chainConf      *config.ChainConfig
 	client         node.EthClient
+	signer         signer.Signer
+	riskEngine     *risk.Engine
+	hotWallets     *HotWalletSelector
 	resourceCtx    context.Context
 	resourceCancel context.CancelFunc
 	tasks          tasks.Group
 }
 
-func NewWithdraw(cfg *config.Config, db *database.DB, client node.EthClient, shutdown context.CancelCauseFunc) (*Withdraw, error) {
+func NewWithdraw(cfg *config.Config, db *database.DB, client node.EthClient, txSigner signer.Signer, shutdown context.CancelCauseFunc) (*Withdraw, error) {
 	resCtx, resCancel := context.WithCancel(context.Background())
 	return &Withdraw{
 		db:             db,
 		chainConf:      &cfg.Chain,
 		client:         client,
+		signer:         txSigner,
+		riskEngine:     risk.NewEngine(db),
+		hotWallets:     NewHotWalletSelector(cfg, db, client),
 		resourceCtx:    resCtx,
 		resourceCancel: resCancel,
 		tasks: tasks.Group{HandleCrit: func(err error) {
@@ -53,118 +61,205 @@ func (w *Withdraw) Close() error {
 	var result error
 	w.resourceCancel()
 	if err := w.tasks.Wait(); err != nil {
-		result = errors.Join(result, fmt.Errorf("failed to await deposit %w"), err)
+		result = errors.Join(result, fmt.Errorf("failed to await deposit: %w", err))
 	}
 	return nil
 }
 
 func (w *Withdraw) Start() error {
 	log.Info("start withdraw......")
+	w.startRebroadcast()
 	tickerWithdrawsWorker := time.NewTicker(time.Second * 5)
 	w.tasks.Go(func() error {
 		for range tickerWithdrawsWorker.C {
-			withdrawList, err := w.db.Withdraws.UnSendWithdrawsList()
-			if err != nil {
-				log.Error("get unsend withdraw list fail", "err", err)
+			if err := w.processWithdraws(); err != nil {
 				return err
 			}
+		}
+		return nil
+	})
+	return nil
+}
 
-			returnWithdrawsList := make([]database.Withdraws, len(withdrawList))
-			index := 0
-			var balanceList []database.Balances
-			for _, withdraw := range withdrawList {
-				hotWallet, err := w.db.Addresses.QueryHotWalletInfo()
-				if err != nil {
-					log.Error("query hot wallet info err", "err", err)
-					return err
-				}
+// processWithdraws 对待发送的提现做风控和限额复核，签名并持久化后广播
+func (w *Withdraw) processWithdraws() error {
+	withdrawList, err := w.db.Withdraws.UnSendWithdrawsList()
+	if err != nil {
+		log.Error("get unsend withdraw list fail", "err", err)
+		return err
+	}
 
-				hotWalletTokenBalance, err := w.db.Balances.QueryWalletBalanceByTokenAndAddress(hotWallet.Address, withdraw.TokenAddress)
-				if hotWalletTokenBalance.Balance.Cmp(withdraw.Amount) < 0 {
-					log.Info("hot wallet balance is not enough", "tokenAddress", withdraw.TokenAddress)
-					continue
-				}
+	if w.batchEnable() {
+		if err := w.batchWithdraw(withdrawList); err != nil {
+			log.Error("batch withdraw fail", "err", err)
+			return err
+		}
+		return nil
+	}
 
-				nonce, err := w.client.TxCountByAddress(hotWallet.Address)
-				if err != nil {
-					log.Error("query nonce by address fail", "err", err)
-					return err
-				}
+	// 本轮已使用热钱包的下一个 nonce，以及发送失败或 nonce 卡住的热钱包
+	nonceMap := make(map[common.Address]uint64)
+	excludeWallets := make(map[common.Address]bool)
+	for _, withdraw := range withdrawList {
+		// 风控未通过的提现不签名，停留在审核或拒绝状态
+		riskResult, err := w.riskEngine.CheckWithdraw(&withdraw)
+		if err != nil {
+			log.Error("check withdraw risk fail", "err", err)
+			return err
+		}
+		if !riskResult.Pass() {
+			continue
+		}
+		// 签名前复核限额，超限的提现保持未发送，待窗口滚动后再出款
+		if err := w.riskEngine.CheckSignLimits(&withdraw, nil); err != nil {
+			if errors.Is(err, risk.ErrWithdrawLimitExceeded) {
+				log.Warn("withdraw exceeds limit, wait for next window", "guid", withdraw.GUID, "err", err)
+				continue
+			}
+			return err
+		}
 
-				var buildData []byte
-				var gasLimit uint64
-				var toAddress *common.Address
-				var amount *big.Int
-				if withdraw.TokenAddress.Hex() != "0x0000000000000000000000000000000000000000" {
-					buildData = ethereum.BuildErc20Data(withdraw.ToAddress, withdraw.Amount)
-					toAddress = &withdraw.TokenAddress
-					gasLimit = TokenGasLimit
-					amount = big.NewInt(0)
-				} else {
-					toAddress = toAddress
-					gasLimit = EthGasLimit
-					amount = withdraw.Amount
-				}
-				dFeeTx := &types.DynamicFeeTx{
-					ChainID:   big.NewInt(int64(w.chainConf.ChainID)),
-					Nonce:     uint64(nonce),
-					GasTipCap: maxPriorityFeePerGas,
-					GasFeeCap: maxFeePerGas,
-					Gas:       gasLimit,
-					To:        toAddress,
-					Value:     amount,
-					Data:      buildData,
-				}
-				rawTx, txHash, err := ethereum.OfflineSignTx(dFeeTx, hotWallet.PrivateKey, big.NewInt(int64(w.chainConf.ChainID)))
-				if err != nil {
-					log.Error("offline transaction fail", "err", err)
-					return err
-				}
-				log.Info("Offline sign tx success", "rawTx", rawTx)
+		hotWallet, nonce, err := w.nextHotWallet(withdraw.TokenAddress, withdraw.Amount, excludeWallets, nonceMap)
+		if err != nil {
+			return err
+		}
+		if hotWallet == nil {
+			log.Info("hot wallet balance is not enough", "tokenAddress", withdraw.TokenAddress)
+			continue
+		}
 
-				// sendRawTx
-				err = w.client.SendRawTransaction(rawTx)
-				if err != nil {
-					log.Error("send raw transaction fail", "err", err)
+		dFeeTx := w.buildWithdrawTx(&withdraw, nonce)
+		// 广播前模拟执行，必然回滚的提现转入审核状态，不浪费手续费
+		if parked, err := w.simulateWithdraw(hotWallet.Address, dFeeTx, []database.Withdraws{withdraw}); err != nil {
+			return err
+		} else if parked {
+			continue
+		}
+		rawTx, txHash, err := w.signer.SignTx(hotWallet.Address, dFeeTx, big.NewInt(int64(w.chainConf.ChainID)))
+		if err != nil {
+			log.Error("offline transaction fail", "err", err)
+			return err
+		}
+		log.Info("Offline sign tx success", "rawTx", rawTx)
+
+		// 先持久化签名交易并锁定热钱包余额再广播，广播失败由重新广播任务继续发送
+		lockJournal := database.NewJournal(database.LedgerReasonWithdrawLock, common.HexToHash(txHash), withdraw.TokenAddress).
+			Transfer(database.AvailableAccount(hotWallet.Address, 1), database.LockedAccount(hotWallet.Address, 1), withdraw.Amount)
+		statusChanged := false
+		retryStrategy := &retry.ExponentialStrategy{Min: 1000, Max: 20_000, MaxJitter: 250}
+		if _, err := retry.Do[interface{}](w.resourceCtx, 10, retryStrategy, func() (interface{}, error) {
+			if err := w.db.Transaction(func(tx *database.DB) error {
+				if err := tx.LedgerEntries.PostJournal(lockJournal); err != nil {
 					return err
 				}
-				returnWithdrawsList[index].Hash = common.HexToHash(txHash)
-				returnWithdrawsList[index].GUID = withdraw.GUID
-				balanceItem := database.Balances{
-					Address:      hotWallet.Address,
-					TokenAddress: withdraw.TokenAddress,
-					LockBalance:  withdraw.Amount,
+				return tx.Withdraws.MarkWithdrawSigned(withdraw, common.HexToHash(txHash), rawTx)
+			}); err != nil {
+				if errors.Is(err, database.ErrWithdrawStatusChanged) {
+					statusChanged = true
+					return nil, nil
 				}
-				balanceList = append(balanceList, balanceItem)
-				index++
+				log.Error("unable to persist signed withdraw", "err", err)
+				return nil, err
 			}
+			return nil, nil
+		}); err != nil {
+			return err
+		}
+		if statusChanged {
+			// 签名期间提现被取消、转入审核或修改了目标地址和金额，丢弃已签名交易，nonce 留给下一笔
+			log.Warn("withdraw status changed before broadcast, drop signed tx", "guid", withdraw.GUID)
+			continue
+		}
 
-			retryStrategy := &retry.ExponentialStrategy{Min: 1000, Max: 20_000, MaxJitter: 250}
-			if _, err := retry.Do[interface{}](w.resourceCtx, 10, retryStrategy, func() (interface{}, error) {
-				if err := w.db.Transaction(func(tx *database.DB) error {
-					// 将转出去的热钱包余额锁定
-					err = w.db.Balances.UpdateBalances(balanceList, false)
-					if err != nil {
-						log.Error("mark withdraw send fail", "err", err)
-						return err
-					}
-
-					err = w.db.Withdraws.MarkWithdrawsToSend(returnWithdrawsList)
-					if err != nil {
-						log.Error("mark withdraw send fail", "err", err)
-						return err
-					}
-					return nil
-				}); err != nil {
-					log.Error("unable to persist batch", "err", err)
-					return nil, err
-				}
-				return nil, nil
-			}); err != nil {
-				return err
-			}
+		// 已持久化的交易占用了该 nonce，广播失败由重新广播任务继续发送，nonce 不能再分配给下一笔
+		nonceMap[hotWallet.Address] = nonce + 1
+		err = w.client.SendRawTransaction(rawTx)
+		if err != nil {
+			// 单个热钱包发送失败不阻塞其它热钱包出款
+			log.Error("send raw transaction fail", "address", hotWallet.Address, "err", err)
+			excludeWallets[hotWallet.Address] = true
 		}
-		return nil
-	})
+	}
+	return nil
+}
+
+// nextHotWallet 选出可以支付 amount 的热钱包及其下一个 nonce，nonce 卡住的热钱包会被排除后重新选择
+func (w *Withdraw) nextHotWallet(tokenAddress common.Address, amount *big.Int, excludeWallets map[common.Address]bool, nonceMap map[common.Address]uint64) (*database.Addresses, uint64, error) {
+	for {
+		hotWallet, err := w.hotWallets.SelectForWithdraw(tokenAddress, amount, excludeWallets)
+		if err != nil {
+			log.Error("select hot wallet fail", "err", err)
+			return nil, 0, err
+		}
+		if hotWallet == nil {
+			return nil, 0, nil
+		}
+		if nonce, ok := nonceMap[hotWallet.Address]; ok {
+			return hotWallet, nonce, nil
+		}
+		nonce, err := w.hotWallets.NextNonce(hotWallet.Address)
+		if errors.Is(err, ErrHotWalletStuck) {
+			excludeWallets[hotWallet.Address] = true
+			continue
+		}
+		if err != nil {
+			log.Error("query nonce by address fail", "address", hotWallet.Address, "err", err)
+			return nil, 0, err
+		}
+		nonceMap[hotWallet.Address] = nonce
+		return hotWallet, nonce, nil
+	}
+}
+
+func (w *Withdraw) buildWithdrawTx(withdraw *database.Withdraws, nonce uint64) *types.DynamicFeeTx {
+	var buildData []byte
+	var gasLimit uint64
+	var toAddress *common.Address
+	var amount *big.Int
+	if withdraw.TokenAddress.Hex() != "0x0000000000000000000000000000000000000000" {
+		buildData = ethereum.BuildErc20Data(withdraw.ToAddress, withdraw.Amount)
+		toAddress = &withdraw.TokenAddress
+		gasLimit = TokenGasLimit
+		amount = big.NewInt(0)
+	} else {
+		toAddress = &withdraw.ToAddress
+		gasLimit = EthGasLimit
+		amount = withdraw.Amount
+	}
+	return &types.DynamicFeeTx{
+		ChainID:   big.NewInt(int64(w.chainConf.ChainID)),
+		Nonce:     nonce,
+		GasTipCap: maxPriorityFeePerGas,
+		GasFeeCap: maxFeePerGas,
+		Gas:       gasLimit,
+		To:        toAddress,
+		Value:     amount,
+		Data:      buildData,
+	}
+}
+
+// simulateWithdraw 模拟执行提现交易，返回 true 表示交易不能广播：模拟回滚时提现已转入审核状态，节点异常时留待下一轮重试
+func (w *Withdraw) simulateWithdraw(from common.Address, dFeeTx *types.DynamicFeeTx, withdrawList []database.Withdraws) (bool, error) {
+	err := simulateTx(w.client, from, dFeeTx)
+	if err == nil {
+		return false, nil
+	}
+	var simulationErr *SimulationError
+	if !errors.As(err, &simulationErr) {
+		log.Error("simulate withdraw tx fail", "from", from, "err", err)
+		return true, nil
+	}
+	log.Warn("withdraw tx would revert, park for review", "from", from, "kind", simulationErr.Kind, "reason", simulationErr.Reason, "count", len(withdrawList))
+	return true, w.parkWithdraws(withdrawList, simulationErr)
+}
+
+// parkWithdraws 将模拟回滚的提现转入审核状态并记录回滚原因
+func (w *Withdraw) parkWithdraws(withdrawList []database.Withdraws, simulationErr *SimulationError) error {
+	for _, withdraw := range withdrawList {
+		if err := w.db.Withdraws.UpdateWithdrawFailReason(withdraw.GUID, 6, simulationErr.Error()); err != nil {
+			log.Error("park withdraw fail", "guid", withdraw.GUID, "err", err)
+			return err
+		}
+	}
 	return nil
 }
@@ -0,0 +1,243 @@
+package wallet
+
+import (
+	"errors"
+	"math/big"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/the-web3/eth-wallet/database"
+	"github.com/the-web3/eth-wallet/risk"
+	"github.com/the-web3/eth-wallet/wallet/ethereum"
+	"github.com/the-web3/eth-wallet/wallet/retry"
+)
+
+var (
+	DisperseBaseGasLimit        uint64 = 60000
+	DisperseEthGasPerTransfer   uint64 = 40000
+	DisperseTokenGasPerTransfer uint64 = 70000
+)
+
+func (w *Withdraw) batchEnable() bool {
+	return w.chainConf.WithdrawBatchEnable && common.IsHexAddress(w.chainConf.DisperseContract)
+}
+
+// batchWithdraw 将同一币种的待发送提现按批次合并为一笔 disperse 合约调用
+func (w *Withdraw) batchWithdraw(withdrawList []database.Withdraws) error {
+	tokenWithdraws := make(map[common.Address][]database.Withdraws)
+	var tokenList []common.Address
+	// 同一轮的提现在批次发送前都还未签名，复核限额时需要计入前面已通过复核的提现
+	tally := &risk.SignLimitTally{}
+	for _, withdraw := range withdrawList {
+		riskResult, err := w.riskEngine.CheckWithdraw(&withdraw)
+		if err != nil {
+			log.Error("check withdraw risk fail", "err", err)
+			return err
+		}
+		if !riskResult.Pass() {
+			continue
+		}
+		// 签名前复核限额，超限的提现保持未发送，待窗口滚动后再出款
+		if err := w.riskEngine.CheckSignLimits(&withdraw, tally); err != nil {
+			if errors.Is(err, risk.ErrWithdrawLimitExceeded) {
+				log.Warn("withdraw exceeds limit, wait for next window", "guid", withdraw.GUID, "err", err)
+				continue
+			}
+			return err
+		}
+		tally.Add(withdraw)
+		if _, ok := tokenWithdraws[withdraw.TokenAddress]; !ok {
+			tokenList = append(tokenList, withdraw.TokenAddress)
+		}
+		tokenWithdraws[withdraw.TokenAddress] = append(tokenWithdraws[withdraw.TokenAddress], withdraw)
+	}
+	if len(tokenList) == 0 {
+		return nil
+	}
+
+	nonceMap := make(map[common.Address]uint64)
+	excludeWallets := make(map[common.Address]bool)
+	batchSize := int(w.chainConf.WithdrawBatchSize)
+	for _, tokenAddress := range tokenList {
+		batchWithdraws := tokenWithdraws[tokenAddress]
+		for start := 0; start < len(batchWithdraws); start += batchSize {
+			end := min(start+batchSize, len(batchWithdraws))
+			chunk := batchWithdraws[start:end]
+			totalAmount := big.NewInt(0)
+			for _, withdraw := range chunk {
+				totalAmount.Add(totalAmount, withdraw.Amount)
+			}
+			// 每个批次单独选择热钱包，发送失败的热钱包本轮不再使用
+			hotWallet, nonce, err := w.nextHotWallet(tokenAddress, totalAmount, excludeWallets, nonceMap)
+			if err != nil {
+				return err
+			}
+			if hotWallet == nil {
+				log.Info("hot wallet balance is not enough for batch", "tokenAddress", tokenAddress, "totalAmount", totalAmount)
+				continue
+			}
+			dFeeTx, err := w.buildWithdrawBatchTx(tokenAddress, chunk, totalAmount, nonce)
+			if err != nil {
+				return err
+			}
+			if parked, err := w.simulateWithdrawBatch(hotWallet.Address, dFeeTx, chunk, nonce); err != nil {
+				return err
+			} else if parked {
+				continue
+			}
+			persisted, sent, err := w.sendWithdrawBatch(hotWallet, tokenAddress, chunk, totalAmount, dFeeTx)
+			if err != nil {
+				return err
+			}
+			if persisted {
+				// 已持久化的交易占用了该 nonce，广播失败也不能再分配给下一批
+				nonceMap[hotWallet.Address] = nonce + 1
+			}
+			if !sent {
+				excludeWallets[hotWallet.Address] = true
+			}
+		}
+	}
+	return nil
+}
+
+func (w *Withdraw) buildWithdrawBatchTx(tokenAddress common.Address, withdrawList []database.Withdraws, totalAmount *big.Int, nonce uint64) (*types.DynamicFeeTx, error) {
+	recipients := make([]common.Address, len(withdrawList))
+	values := make([]*big.Int, len(withdrawList))
+	for i, withdraw := range withdrawList {
+		recipients[i] = withdraw.ToAddress
+		values[i] = withdraw.Amount
+	}
+
+	disperseContract := common.HexToAddress(w.chainConf.DisperseContract)
+	var err error
+	var buildData []byte
+	var gasLimit uint64
+	var amount *big.Int
+	if tokenAddress.Hex() != "0x0000000000000000000000000000000000000000" {
+		buildData, err = ethereum.BuildDisperseTokenData(tokenAddress, recipients, values)
+		gasLimit = DisperseBaseGasLimit + DisperseTokenGasPerTransfer*uint64(len(recipients))
+		amount = big.NewInt(0)
+	} else {
+		buildData, err = ethereum.BuildDisperseEtherData(recipients, values)
+		gasLimit = DisperseBaseGasLimit + DisperseEthGasPerTransfer*uint64(len(recipients))
+		amount = totalAmount
+	}
+	if err != nil {
+		log.Error("build disperse data fail", "err", err)
+		return nil, err
+	}
+	return &types.DynamicFeeTx{
+		ChainID:   big.NewInt(int64(w.chainConf.ChainID)),
+		Nonce:     nonce,
+		GasTipCap: maxPriorityFeePerGas,
+		GasFeeCap: maxFeePerGas,
+		Gas:       gasLimit,
+		To:        &disperseContract,
+		Value:     amount,
+		Data:      buildData,
+	}, nil
+}
+
+// simulateWithdrawBatch 模拟执行批量提现，整批回滚时逐笔模拟找出导致回滚的提现转入审核，其余提现下一轮重新组批
+func (w *Withdraw) simulateWithdrawBatch(from common.Address, dFeeTx *types.DynamicFeeTx, withdrawList []database.Withdraws, nonce uint64) (bool, error) {
+	err := simulateTx(w.client, from, dFeeTx)
+	if err == nil {
+		return false, nil
+	}
+	var batchErr *SimulationError
+	if !errors.As(err, &batchErr) {
+		log.Error("simulate withdraw batch tx fail", "from", from, "err", err)
+		return true, nil
+	}
+	log.Warn("withdraw batch tx would revert", "from", from, "kind", batchErr.Kind, "reason", batchErr.Reason, "count", len(withdrawList))
+	parkedCount, transientErr := 0, false
+	for _, withdraw := range withdrawList {
+		err := simulateTx(w.client, from, w.buildWithdrawTx(&withdraw, nonce))
+		if err == nil {
+			continue
+		}
+		var simulationErr *SimulationError
+		if !errors.As(err, &simulationErr) {
+			transientErr = true
+			continue
+		}
+		if err := w.parkWithdraws([]database.Withdraws{withdraw}, simulationErr); err != nil {
+			return true, err
+		}
+		parkedCount++
+	}
+	// 逐笔都能成功说明是批量调用本身的问题（如授权不足），整批转入审核
+	if parkedCount == 0 && !transientErr {
+		return true, w.parkWithdraws(withdrawList, batchErr)
+	}
+	return true, nil
+}
+
+// sendWithdrawBatch 签名并持久化批次后广播，persisted 表示交易已落库占用了 nonce，sent 表示广播成功
+func (w *Withdraw) sendWithdrawBatch(hotWallet *database.Addresses, tokenAddress common.Address, withdrawList []database.Withdraws, totalAmount *big.Int, dFeeTx *types.DynamicFeeTx) (persisted bool, sent bool, err error) {
+	rawTx, txHash, err := w.signer.SignTx(hotWallet.Address, dFeeTx, big.NewInt(int64(w.chainConf.ChainID)))
+	if err != nil {
+		log.Error("offline transaction fail", "err", err)
+		return false, false, err
+	}
+	log.Info("Offline sign batch tx success", "rawTx", rawTx, "withdrawCount", len(withdrawList))
+
+	batch := database.WithdrawBatches{
+		GUID:          uuid.New(),
+		BlockHash:     common.Hash{},
+		BlockNumber:   big.NewInt(1),
+		Hash:          common.HexToHash(txHash),
+		FromAddress:   hotWallet.Address,
+		TokenAddress:  tokenAddress,
+		Fee:           big.NewInt(0),
+		TotalAmount:   totalAmount,
+		WithdrawCount: uint64(len(withdrawList)),
+		Status:        0,
+		TxSignHex:     rawTx,
+		Timestamp:     uint64(time.Now().Unix()),
+	}
+	lockJournal := database.NewJournal(database.LedgerReasonWithdrawLock, batch.Hash, tokenAddress).
+		Transfer(database.AvailableAccount(hotWallet.Address, 1), database.LockedAccount(hotWallet.Address, 1), totalAmount)
+	statusChanged := false
+	retryStrategy := &retry.ExponentialStrategy{Min: 1000, Max: 20_000, MaxJitter: 250}
+	if _, err := retry.Do[interface{}](w.resourceCtx, 10, retryStrategy, func() (interface{}, error) {
+		if err := w.db.Transaction(func(tx *database.DB) error {
+			if err := tx.WithdrawBatches.StoreWithdrawBatch(batch); err != nil {
+				return err
+			}
+			if err := tx.Withdraws.MarkBatchWithdrawsToSend(batch.GUID, batch.Hash, withdrawList); err != nil {
+				return err
+			}
+			return tx.LedgerEntries.PostJournal(lockJournal)
+		}); err != nil {
+			if errors.Is(err, database.ErrWithdrawStatusChanged) {
+				statusChanged = true
+				return nil, nil
+			}
+			log.Error("unable to persist withdraw batch", "err", err)
+			return nil, err
+		}
+		return nil, nil
+	}); err != nil {
+		return false, false, err
+	}
+	if statusChanged {
+		// 批次内有提现在签名期间被取消或修改，丢弃本批交易，下一轮重新组批
+		log.Warn("withdraw status changed before broadcast, drop signed batch tx", "hash", batch.Hash)
+		return false, false, nil
+	}
+
+	// 批次已持久化，广播失败由重新广播任务继续发送
+	err = w.client.SendRawTransaction(rawTx)
+	if err != nil {
+		log.Error("send raw transaction fail", "address", hotWallet.Address, "err", err)
+		return true, false, nil
+	}
+	return true, true, nil
+}